@@ -27,7 +27,11 @@ func Start() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	services, err := server.GetServices(logger, cfg)
+	// stops the background work of the services once the server is down
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	services, err := server.GetServices(ctx, logger, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to get services: %w", err)
 	}
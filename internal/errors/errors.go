@@ -47,4 +47,16 @@ var (
 	ErrGatewayTimeout      = NewCustomError(http.StatusGatewayTimeout, "gateway timeout")
 
 	ErrNoAccounts = NewCustomError(http.StatusNotFound, "no accounts found")
+
+	// ErrTokenRevoked is returned when EVE SSO rejects a refresh token, the character has to log in again
+	ErrTokenRevoked = NewCustomError(http.StatusUnauthorized, "token revoked")
+
+	// ErrTokenRefreshFailed is returned when a token could not be refreshed, it may work on the next try
+	ErrTokenRefreshFailed = NewCustomError(http.StatusBadGateway, "token refresh failed")
+
+	// ErrInvalidDoctrine is returned for a doctrine that cannot be saved as given
+	ErrInvalidDoctrine = NewCustomError(http.StatusBadRequest, "invalid doctrine")
+
+	// ErrServerNotConfigured is returned for a server without an SSO client, its tokens cannot be used elsewhere
+	ErrServerNotConfigured = NewCustomError(http.StatusServiceUnavailable, "server not configured")
 )
@@ -4,6 +4,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
@@ -130,3 +131,112 @@ func (h *AccountHandler) RemoveAccount() http.HandlerFunc {
 		respondJSON(w, map[string]bool{"success": true})
 	}
 }
+
+// SetAccountExpiry sets the omega expiry of an account, a null expiry clears it.
+func (h *AccountHandler) SetAccountExpiry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			AccountID int64      `json:"accountID"`
+			Expiry    *time.Time `json:"expiry"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil {
+			respondError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if request.AccountID == 0 {
+			respondError(w, "UserId is required", http.StatusBadRequest)
+			return
+		}
+
+		err := h.accountService.SetAccountExpiry(request.AccountID, request.Expiry)
+		if err != nil {
+			if err.Error() == "account not found" {
+				respondError(w, "Account not found", http.StatusNotFound)
+			} else {
+				respondError(w, fmt.Sprintf("Failed to set account expiry: %v", err), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+// SetCharacterMCTExpiry sets the MCT slot expiry of a character, a null expiry clears it.
+func (h *AccountHandler) SetCharacterMCTExpiry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			CharacterID int64      `json:"characterID"`
+			Expiry      *time.Time `json:"expiry"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil {
+			respondError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if request.CharacterID == 0 {
+			respondError(w, "CharacterID is required", http.StatusBadRequest)
+			return
+		}
+
+		err := h.accountService.SetCharacterMCTExpiry(request.CharacterID, request.Expiry)
+		if err != nil {
+			if err.Error() == "character not found" {
+				respondError(w, "Character not found", http.StatusNotFound)
+			} else {
+				respondError(w, fmt.Sprintf("Failed to set MCT expiry: %v", err), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+// MoveCharacter moves a character that ended up in the wrong account
+func (h *AccountHandler) MoveCharacter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			CharacterID int64  `json:"characterID"`
+			AccountName string `json:"accountName"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil {
+			respondError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if request.CharacterID == 0 || request.AccountName == "" {
+			respondError(w, "CharacterID and AccountName are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.accountService.MoveCharacter(request.CharacterID, request.AccountName); err != nil {
+			respondError(w, fmt.Sprintf("Failed to move character: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *AccountHandler) MergeAccounts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			FromAccountName string `json:"fromAccountName"`
+			IntoAccountName string `json:"intoAccountName"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil {
+			respondError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if request.FromAccountName == "" || request.IntoAccountName == "" {
+			respondError(w, "FromAccountName and IntoAccountName are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.accountService.MergeAccounts(request.FromAccountName, request.IntoAccountName); err != nil {
+			respondError(w, fmt.Sprintf("Failed to merge accounts: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
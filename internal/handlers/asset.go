@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type AssetHandler struct {
+	logger       interfaces.Logger
+	assetService interfaces.AssetService
+}
+
+func NewAssetHandler(l interfaces.Logger, a interfaces.AssetService) *AssetHandler {
+	return &AssetHandler{
+		logger:       l,
+		assetService: a,
+	}
+}
+
+func (h *AssetHandler) RefreshAssets() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.assetService.RefreshAssets(); err != nil {
+			h.logger.Errorf("Failed to refresh assets: %v", err)
+			respondError(w, "Failed to refresh assets", http.StatusInternalServerError)
+			return
+		}
+
+		status, err := h.assetService.GetAssetStatus()
+		if err != nil {
+			h.logger.Errorf("Failed to load asset status: %v", err)
+			respondError(w, "Failed to load asset status", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, status)
+	}
+}
+
+// SearchAssets matches ?q= against item type and custom names and ?location= against the
+// station, structure or system the item is in. At least one of them is required.
+func (h *AssetHandler) SearchAssets() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("q") == "" && query.Get("location") == "" {
+			respondError(w, "q or location is required", http.StatusBadRequest)
+			return
+		}
+
+		items, err := h.assetService.SearchAssets(query.Get("q"), query.Get("location"))
+		if err != nil {
+			h.logger.Errorf("Failed to search assets: %v", err)
+			respondError(w, "Failed to search assets", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, items)
+	}
+}
+
+func (h *AssetHandler) GetAssetStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := h.assetService.GetAssetStatus()
+		if err != nil {
+			h.logger.Errorf("Failed to load asset status: %v", err)
+			respondError(w, "Failed to load asset status", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, status)
+	}
+}
@@ -4,21 +4,25 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
 
 type AssociationHandler struct {
 	logger       interfaces.Logger
 	assocService interfaces.AssociationService
+	stateService interfaces.AppStateService
 }
 
 func NewAssociationHandler(
 	l interfaces.Logger,
 	a interfaces.AssociationService,
+	s interfaces.AppStateService,
 ) *AssociationHandler {
 	return &AssociationHandler{
 		logger:       l,
 		assocService: a,
+		stateService: s,
 	}
 }
 
@@ -73,3 +77,120 @@ func (h *AssociationHandler) UnassociateCharacter(w http.ResponseWriter, r *http
 	})
 
 }
+
+// SuggestAssociations infers associations from the profiles last loaded into the app state
+func (h *AssociationHandler) SuggestAssociations(w http.ResponseWriter, r *http.Request) {
+	profiles := h.stateService.GetAppState().EveData.EveProfiles
+	suggestions, err := h.assocService.SuggestAssociations(profiles)
+	if err != nil {
+		h.logger.Errorf("Failed to suggest associations: %v", err)
+		respondError(w, "Failed to suggest associations", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, suggestions)
+}
+
+func (h *AssociationHandler) AcceptSuggestions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Suggestions []model.Association `json:"suggestions"`
+	}
+
+	if err := decodeJSONBody(r, &req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Suggestions) == 0 {
+		respondError(w, "suggestions are required", http.StatusBadRequest)
+		return
+	}
+
+	accepted, rejected, err := h.assocService.AcceptSuggestions(req.Suggestions)
+	if err != nil {
+		h.logger.Errorf("Failed to accept suggestions: %v", err)
+		respondJSON(w, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+
+	respondJSON(w, map[string]interface{}{
+		"success":  len(accepted) > 0,
+		"message":  fmt.Sprintf("%d associations accepted, %d rejected", len(accepted), len(rejected)),
+		"accepted": accepted,
+		"rejected": rejected,
+	})
+}
+
+func (h *AssociationHandler) GetAssociationRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.assocService.GetAssociationRules()
+	if err != nil {
+		h.logger.Errorf("Failed to load association rules: %v", err)
+		respondError(w, "Failed to load association rules", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, rules)
+}
+
+func (h *AssociationHandler) UpdateAssociationRules(w http.ResponseWriter, r *http.Request) {
+	var rules model.AssociationRules
+	if err := decodeJSONBody(r, &rules); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.assocService.UpdateAssociationRules(rules); err != nil {
+		respondJSON(w, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+	respondJSON(w, map[string]interface{}{"success": true, "message": "Association rules updated"})
+}
+
+// CheckAssociations checks the associations against the profiles last loaded into the app state
+func (h *AssociationHandler) CheckAssociations(w http.ResponseWriter, r *http.Request) {
+	issues, err := h.assocService.CheckAssociations(h.stateService.GetAppState().EveData.EveProfiles)
+	if err != nil {
+		h.logger.Errorf("Failed to check associations: %v", err)
+		respondError(w, "Failed to check associations", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, issues)
+}
+
+func (h *AssociationHandler) RepairAssociations(w http.ResponseWriter, r *http.Request) {
+	repaired, err := h.assocService.RepairAssociations()
+	if err != nil {
+		h.logger.Errorf("Failed to repair associations: %v", err)
+		respondJSON(w, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+	respondJSON(w, map[string]interface{}{
+		"success":  true,
+		"message":  fmt.Sprintf("%d association issues repaired", len(repaired)),
+		"repaired": repaired,
+	})
+}
+
+// RemoveOrphanedAssociations removes the orphaned associations the user picked from the check
+func (h *AssociationHandler) RemoveOrphanedAssociations(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Associations []model.Association `json:"associations"`
+	}
+	if err := decodeJSONBody(r, &req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Associations) == 0 {
+		respondError(w, "associations are required", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := h.assocService.RemoveOrphanedAssociations(h.stateService.GetAppState().EveData.EveProfiles, req.Associations)
+	if err != nil {
+		h.logger.Errorf("Failed to remove orphaned associations: %v", err)
+		respondJSON(w, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+	respondJSON(w, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("%d orphaned associations removed", len(removed)),
+		"removed": removed,
+	})
+}
@@ -3,10 +3,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 
 	flyHttp "github.com/guarzo/canifly/internal/http"
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
 
@@ -17,7 +19,7 @@ type AuthHandler struct {
 	accountService interfaces.AccountService
 	stateService   interfaces.AppStateService
 	loginService   interfaces.LoginService
-	authClient     interfaces.AuthClient
+	authClients    map[string]interfaces.AuthClient // by server, Tranquility is always present
 }
 
 func NewAuthHandler(
@@ -27,7 +29,7 @@ func NewAuthHandler(
 	accountSvc interfaces.AccountService,
 	stateSvc interfaces.AppStateService,
 	login interfaces.LoginService,
-	auth interfaces.AuthClient,
+	auth map[string]interfaces.AuthClient,
 ) *AuthHandler {
 	return &AuthHandler{
 		sessionService: s,
@@ -36,10 +38,24 @@ func NewAuthHandler(
 		accountService: accountSvc,
 		stateService:   stateSvc,
 		loginService:   login,
-		authClient:     auth,
+		authClients:    auth,
 	}
 }
 
+// authClientFor returns the server a login is for and its SSO client. An empty server is
+// Tranquility, servers without configured SSO credentials are refused.
+func (h *AuthHandler) authClientFor(server string) (string, interfaces.AuthClient, error) {
+	normalized, ok := model.NormalizeServer(server)
+	if !ok {
+		return "", nil, fmt.Errorf("unknown server %s", server)
+	}
+	auth, ok := h.authClients[normalized]
+	if !ok {
+		return "", nil, fmt.Errorf("server %s is not configured", normalized)
+	}
+	return normalized, auth, nil
+}
+
 func (h *AuthHandler) Login() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, proxy-revalidate")
@@ -50,6 +66,7 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 
 		var request struct {
 			Account string `json:"account"`
+			Server  string `json:"server"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -63,13 +80,19 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 			return
 		}
 
-		state, err := h.loginService.GenerateAndStoreInitialState(request.Account)
+		server, authClient, err := h.authClientFor(request.Server)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		state, err := h.loginService.GenerateAndStoreInitialState(request.Account, server)
 		if err != nil {
 			respondError(w, "Unable to generate state", http.StatusInternalServerError)
 			return
 		}
 
-		url := h.authClient.GetAuthURL(state)
+		url := authClient.GetAuthURL(state)
 		respondJSON(w, map[string]string{"redirectURL": url, "state": state})
 	}
 }
@@ -80,6 +103,7 @@ func (h *AuthHandler) AddCharacterHandler() http.HandlerFunc {
 
 		var request struct {
 			Account string `json:"account"`
+			Server  string `json:"server"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -93,13 +117,63 @@ func (h *AuthHandler) AddCharacterHandler() http.HandlerFunc {
 			return
 		}
 
-		state, err := h.loginService.GenerateAndStoreInitialState(request.Account)
+		// characters added to an existing account log in to its server
+		if request.Server == "" {
+			request.Server = h.accountServer(request.Account)
+		}
+
+		server, authClient, err := h.authClientFor(request.Server)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		state, err := h.loginService.GenerateAndStoreInitialState(request.Account, server)
 		if err != nil {
 			respondError(w, "Unable to generate state", http.StatusInternalServerError)
 			return
 		}
 
-		url := h.authClient.GetAuthURL(state)
+		url := authClient.GetAuthURL(state)
+		respondJSON(w, map[string]string{"redirectURL": url, "state": state})
+	}
+}
+
+// ReauthCharacter starts a login that replaces the token of a character whose token was revoked or
+// lacks scopes.
+func (h *AuthHandler) ReauthCharacter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var request struct {
+			CharacterID int64 `json:"characterID"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			h.logger.Errorf("Invalid request body: %v", err)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		account, ok := h.characterAccount(request.CharacterID)
+		if !ok {
+			respondError(w, "Character not found", http.StatusNotFound)
+			return
+		}
+
+		server, authClient, err := h.authClientFor(account.Server)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		state, err := h.loginService.GenerateReauthState(request.CharacterID, account.Name, server)
+		if err != nil {
+			respondError(w, "Unable to generate state", http.StatusInternalServerError)
+			return
+		}
+
+		url := authClient.GetAuthURL(state)
 		respondJSON(w, map[string]string{"redirectURL": url, "state": state})
 	}
 }
@@ -121,14 +195,28 @@ func (h *AuthHandler) CallBack() http.HandlerFunc {
 
 		h.logger.Infof("Received accountName (account name): %v", accountName)
 
-		token, err := h.authClient.ExchangeCode(code)
+		server, _ := h.loginService.ResolveServerByState(state)
+		server, authClient, err := h.authClientFor(server)
+		if err != nil {
+			h.logger.Errorf("Failed to resolve server for %s: %v", accountName, err)
+			handleErrorWithRedirect(w, r, "/")
+			return
+		}
+
+		token, err := authClient.ExchangeCode(code)
 		if err != nil {
 			h.logger.Errorf("Failed to exchange token for code: %s, %v", code, err)
 			handleErrorWithRedirect(w, r, "/")
 			return
 		}
 
-		user, err := h.esiService.GetUserInfo(token)
+		esi, err := h.esiService.ForServer(server)
+		if err != nil {
+			h.logger.Errorf("Cannot log in to %s: %v", server, err)
+			handleErrorWithRedirect(w, r, "/")
+			return
+		}
+		user, err := esi.GetUserInfo(token)
 		if err != nil {
 			h.logger.Errorf("Failed to get user info: %v", err)
 			handleErrorWithRedirect(w, r, "/")
@@ -142,8 +230,20 @@ func (h *AuthHandler) CallBack() http.HandlerFunc {
 			return
 		}
 
-		// Use AccountService to handle account creation
-		if err = h.accountService.FindOrCreateAccount(accountName, user, token); err != nil {
+		if reauthID := h.loginService.ResolveReauthCharacterByState(state); reauthID != 0 {
+			// the token replaces the one of the character, wherever it is, instead of adding it again
+			if user.CharacterID != reauthID {
+				h.logger.Errorf("Logged in as %s instead of character %d", user.CharacterName, reauthID)
+				http.Error(w, fmt.Sprintf("logged in as %s, not the character to reauthorize", user.CharacterName), http.StatusBadRequest)
+				return
+			}
+			if err = h.accountService.ReauthorizeCharacter(reauthID, server, token); err != nil {
+				h.logger.Errorf("%v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		} else if err = h.accountService.FindOrCreateAccount(accountName, server, user, token); err != nil {
+			// Use AccountService to handle account creation
 			h.logger.Errorf("%v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -168,6 +268,38 @@ func (h *AuthHandler) CallBack() http.HandlerFunc {
 	}
 }
 
+// accountServer returns the server of an existing account, empty when there is no such account.
+func (h *AuthHandler) accountServer(accountName string) string {
+	accounts, err := h.accountService.FetchAccounts()
+	if err != nil {
+		h.logger.Warnf("Failed to fetch accounts: %v", err)
+		return ""
+	}
+	for _, account := range accounts {
+		if account.Name == accountName {
+			return account.Server
+		}
+	}
+	return ""
+}
+
+// characterAccount returns the account holding a character.
+func (h *AuthHandler) characterAccount(characterID int64) (model.Account, bool) {
+	accounts, err := h.accountService.FetchAccounts()
+	if err != nil {
+		h.logger.Warnf("Failed to fetch accounts: %v", err)
+		return model.Account{}, false
+	}
+	for _, account := range accounts {
+		for _, ch := range account.Characters {
+			if ch.Character.CharacterID == characterID {
+				return account, true
+			}
+		}
+	}
+	return model.Account{}, false
+}
+
 func (h *AuthHandler) FinalizeLogin() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		state := r.URL.Query().Get("state")
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type CalendarHandler struct {
+	logger           interfaces.Logger
+	dashboardService interfaces.DashboardService
+	calendarService  interfaces.CalendarService
+	configService    interfaces.ConfigService
+}
+
+func NewCalendarHandler(l interfaces.Logger, d interfaces.DashboardService, cal interfaces.CalendarService, c interfaces.ConfigService) *CalendarHandler {
+	return &CalendarHandler{
+		logger:           l,
+		dashboardService: d,
+		calendarService:  cal,
+		configService:    c,
+	}
+}
+
+// GetFeedURL returns the path calendar apps subscribe to. The feed is public so it carries a
+// token instead of relying on the session cookie.
+func (h *CalendarHandler) GetFeedURL() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := h.configService.GetCalendarToken()
+		if err != nil {
+			h.logger.Errorf("Failed to get calendar token: %v", err)
+			respondError(w, "Failed to get calendar feed", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]string{"url": calendarFeedPath(token)})
+	}
+}
+
+func (h *CalendarHandler) ResetFeedURL() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := h.configService.ResetCalendarToken()
+		if err != nil {
+			h.logger.Errorf("Failed to reset calendar token: %v", err)
+			respondError(w, "Failed to reset calendar feed", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]string{"url": calendarFeedPath(token)})
+	}
+}
+
+// Feed serves the iCalendar feed, ?characters=1,2 limits it to those character IDs.
+func (h *CalendarHandler) Feed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := h.configService.GetCalendarToken()
+		if err != nil {
+			h.logger.Errorf("Failed to get calendar token: %v", err)
+			http.Error(w, "Failed to load calendar", http.StatusInternalServerError)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(mux.Vars(r)["token"]), []byte(token)) != 1 {
+			http.NotFound(w, r)
+			return
+		}
+
+		characterIDs, err := parseCharacterIDs(r.URL.Query().Get("characters"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		accounts := h.dashboardService.GetCurrentAppState().AccountData.Accounts
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="canifly.ics"`)
+		if _, err := w.Write([]byte(h.calendarService.BuildCalendar(accounts, characterIDs))); err != nil {
+			h.logger.Warnf("Failed to write calendar feed: %v", err)
+		}
+	}
+}
+
+func calendarFeedPath(token string) string {
+	return fmt.Sprintf("/calendar/%s.ics", token)
+}
+
+func parseCharacterIDs(value string) ([]int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid character ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
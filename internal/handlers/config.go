@@ -63,6 +63,48 @@ func (h *ConfigHandler) ChooseSettingsDir(w http.ResponseWriter, r *http.Request
 	respondJSON(w, map[string]interface{}{"success": true, "settingsDir": req.Directory})
 }
 
+// DiscoverSettingsDirs lists settings directories found on disk, most recently used first
+func (h *ConfigHandler) DiscoverSettingsDirs(w http.ResponseWriter, r *http.Request) {
+	candidates, err := h.configService.DiscoverSettingsDirs()
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to discover settings directories: %v", err), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, candidates)
+}
+
+func (h *ConfigHandler) AddSettingsRoot(w http.ResponseWriter, r *http.Request) {
+	var req model.SettingsRoot
+	if err := decodeJSONBody(r, &req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.configService.AddSettingsRoot(req); err != nil {
+		respondJSON(w, map[string]interface{}{"success": false, "error": err.Error()})
+		return
+	}
+
+	respondJSON(w, map[string]interface{}{"success": true})
+}
+
+func (h *ConfigHandler) RemoveSettingsRoot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSONBody(r, &req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.configService.RemoveSettingsRoot(req.Name); err != nil {
+		respondJSON(w, map[string]interface{}{"success": false, "error": err.Error()})
+		return
+	}
+
+	respondJSON(w, map[string]interface{}{"success": true})
+}
+
 func (h *ConfigHandler) ResetToDefaultDir(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Infof("in reset to default dir handler")
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -8,23 +9,65 @@ import (
 )
 
 type EveDataHandler struct {
-	logger interfaces.Logger
-	eveSvc interfaces.EveProfilesService
+	logger  interfaces.Logger
+	eveSvc  interfaces.EveProfilesService
+	watcher interfaces.SettingsWatcher
 }
 
 func NewEveDataHandler(
 	l interfaces.Logger,
 	s interfaces.EveProfilesService,
+	watcher interfaces.SettingsWatcher,
 ) *EveDataHandler {
 	return &EveDataHandler{
-		logger: l,
-		eveSvc: s,
+		logger:  l,
+		eveSvc:  s,
+		watcher: watcher,
+	}
+}
+
+// SettingsEvents streams settings changes as server-sent events until the client goes away
+func (h *EveDataHandler) SettingsEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.watcher.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Errorf("Failed to encode settings event: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: settings\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
 	}
 }
 
 // SyncSubDirectory
 func (h *EveDataHandler) SyncSubDirectory(w http.ResponseWriter, r *http.Request) {
 	var req struct {
+		Root   string `json:"root"`
 		SubDir string `json:"subDir"`
 		UserId string `json:"userId"`
 		CharId string `json:"charId"`
@@ -35,7 +78,7 @@ func (h *EveDataHandler) SyncSubDirectory(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	userFilesCopied, charFilesCopied, err := h.eveSvc.SyncDir(req.SubDir, req.CharId, req.UserId)
+	userFilesCopied, charFilesCopied, err := h.eveSvc.SyncDir(req.Root, req.SubDir, req.CharId, req.UserId)
 	if err != nil {
 		respondJSON(w, map[string]interface{}{"success": false, "message": fmt.Sprintf("failed to sync %v", err)})
 		return
@@ -49,6 +92,7 @@ func (h *EveDataHandler) SyncSubDirectory(w http.ResponseWriter, r *http.Request
 // SyncAllSubdirectories
 func (h *EveDataHandler) SyncAllSubdirectories(w http.ResponseWriter, r *http.Request) {
 	var req struct {
+		Root   string `json:"root"`
 		SubDir string `json:"subDir"`
 		UserId string `json:"userId"`
 		CharId string `json:"charId"`
@@ -61,7 +105,7 @@ func (h *EveDataHandler) SyncAllSubdirectories(w http.ResponseWriter, r *http.Re
 	}
 
 	h.logger.Infof("SyncAllSubdirectories request: Profile=%s, UserId=%s, CharId=%s", req.SubDir, req.UserId, req.CharId)
-	userFilesCopied, charFilesCopied, err := h.eveSvc.SyncAllDir(req.SubDir, req.CharId, req.UserId)
+	userFilesCopied, charFilesCopied, err := h.eveSvc.SyncAllDir(req.Root, req.SubDir, req.CharId, req.UserId)
 	if err != nil {
 		h.logger.Errorf("Failed to sync all subdirectories from base %s (UserId=%s, CharId=%s): %v", req.SubDir, req.UserId, req.CharId, err)
 		respondJSON(w, map[string]interface{}{"success": false, "message": fmt.Sprintf("failed to sync all: %v", err)})
@@ -74,6 +118,37 @@ func (h *EveDataHandler) SyncAllSubdirectories(w http.ResponseWriter, r *http.Re
 	respondJSON(w, map[string]interface{}{"success": true, "message": message})
 }
 
+// SyncToRoot copies a profile's files into every profile of another settings root
+func (h *EveDataHandler) SyncToRoot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FromRoot string `json:"fromRoot"`
+		SubDir   string `json:"subDir"`
+		ToRoot   string `json:"toRoot"`
+		UserId   string `json:"userId"`
+		CharId   string `json:"charId"`
+	}
+
+	if err := decodeJSONBody(r, &req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ToRoot == "" {
+		respondError(w, "toRoot is required", http.StatusBadRequest)
+		return
+	}
+
+	userFilesCopied, charFilesCopied, err := h.eveSvc.SyncToRoot(req.FromRoot, req.SubDir, req.ToRoot, req.CharId, req.UserId)
+	if err != nil {
+		h.logger.Errorf("Failed to sync %s into %s: %v", req.SubDir, req.ToRoot, err)
+		respondJSON(w, map[string]interface{}{"success": false, "message": fmt.Sprintf("failed to sync to %s: %v", req.ToRoot, err)})
+		return
+	}
+
+	message := fmt.Sprintf("Sync completed into \"%s\": %d user files and %d character files copied, based on user/char files from \"%s\".",
+		req.ToRoot, userFilesCopied, charFilesCopied, req.SubDir)
+	respondJSON(w, map[string]interface{}{"success": true, "message": message})
+}
+
 func (h *EveDataHandler) BackupDirectory(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		TargetDir string `json:"targetDir"`
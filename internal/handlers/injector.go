@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type InjectorHandler struct {
+	logger           interfaces.Logger
+	dashboardService interfaces.DashboardService
+	skillService     interfaces.SkillService
+}
+
+func NewInjectorHandler(l interfaces.Logger, d interfaces.DashboardService, s interfaces.SkillService) *InjectorHandler {
+	return &InjectorHandler{
+		logger:           l,
+		dashboardService: d,
+		skillService:     s,
+	}
+}
+
+// GetInjectorPlan handles ?planName=&characterID=
+func (h *InjectorHandler) GetInjectorPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		planName := r.URL.Query().Get("planName")
+		if planName == "" {
+			respondError(w, "planName is required", http.StatusBadRequest)
+			return
+		}
+		characterID, err := strconv.ParseInt(r.URL.Query().Get("characterID"), 10, 64)
+		if err != nil {
+			respondError(w, "Invalid characterID", http.StatusBadRequest)
+			return
+		}
+
+		accounts := h.dashboardService.GetCurrentAppState().AccountData.Accounts
+		plan, err := h.skillService.PlanInjectors(accounts, planName, characterID)
+		if err != nil {
+			h.logger.Warnf("Failed to plan injectors: %v", err)
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, plan)
+	}
+}
+
+// GetExtractionPlan handles ?characterID=
+func (h *InjectorHandler) GetExtractionPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		characterID, err := strconv.ParseInt(r.URL.Query().Get("characterID"), 10, 64)
+		if err != nil {
+			respondError(w, "Invalid characterID", http.StatusBadRequest)
+			return
+		}
+
+		accounts := h.dashboardService.GetCurrentAppState().AccountData.Accounts
+		plan, err := h.skillService.PlanExtraction(accounts, characterID)
+		if err != nil {
+			h.logger.Warnf("Failed to plan extraction: %v", err)
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, plan)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type NotificationHandler struct {
+	logger              interfaces.Logger
+	notificationService interfaces.NotificationService
+}
+
+func NewNotificationHandler(l interfaces.Logger, n interfaces.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		logger:              l,
+		notificationService: n,
+	}
+}
+
+func (h *NotificationHandler) GetAlerts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		alerts, err := h.notificationService.GetAlerts()
+		if err != nil {
+			h.logger.Errorf("Failed to load alerts: %v", err)
+			respondError(w, "Failed to load alerts", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, alerts)
+	}
+}
+
+func (h *NotificationHandler) AcknowledgeAlert() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID string `json:"id"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if request.ID == "" {
+			respondError(w, "id is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.notificationService.AcknowledgeAlert(request.ID); err != nil {
+			if err.Error() == "alert not found" {
+				respondError(w, "Alert not found", http.StatusNotFound)
+			} else {
+				respondError(w, fmt.Sprintf("Failed to acknowledge alert: %v", err), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *NotificationHandler) GetSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings, err := h.notificationService.GetSettings()
+		if err != nil {
+			h.logger.Errorf("Failed to load notification settings: %v", err)
+			respondError(w, "Failed to load notification settings", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, settings)
+	}
+}
+
+func (h *NotificationHandler) UpdateSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var settings model.NotificationSettings
+		if err := decodeJSONBody(r, &settings); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.notificationService.UpdateSettings(settings); err != nil {
+			respondError(w, fmt.Sprintf("Failed to update notification settings: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
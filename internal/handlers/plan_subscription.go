@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type PlanSubscriptionHandler struct {
+	logger     interfaces.Logger
+	subService interfaces.PlanSubscriptionService
+}
+
+func NewPlanSubscriptionHandler(l interfaces.Logger, s interfaces.PlanSubscriptionService) *PlanSubscriptionHandler {
+	return &PlanSubscriptionHandler{
+		logger:     l,
+		subService: s,
+	}
+}
+
+func (h *PlanSubscriptionHandler) GetSubscriptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, err := h.subService.GetSubscriptions()
+		if err != nil {
+			h.logger.Errorf("Failed to load plan subscriptions: %v", err)
+			respondError(w, "Failed to load plan subscriptions", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, subs)
+	}
+}
+
+func (h *PlanSubscriptionHandler) AddSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var requestData struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		}
+		if err := decodeJSONBody(r, &requestData); err != nil {
+			respondError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if requestData.Name == "" || requestData.URL == "" {
+			respondError(w, "name and url are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.subService.AddSubscription(r.Context(), requestData.Name, requestData.URL); err != nil {
+			h.logger.Errorf("Failed to add plan subscription %s: %v", requestData.Name, err)
+			respondError(w, fmt.Sprintf("Failed to add subscription: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *PlanSubscriptionHandler) RemoveSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			respondError(w, "Missing name parameter", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.subService.RemoveSubscription(name); err != nil {
+			h.logger.Errorf("Failed to remove plan subscription %s: %v", name, err)
+			respondError(w, "Failed to remove subscription", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+// RefreshSubscriptions refreshes the subscription given by the name parameter, or all of them when it is omitted.
+func (h *PlanSubscriptionHandler) RefreshSubscriptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var err error
+		if name := r.URL.Query().Get("name"); name != "" {
+			err = h.subService.RefreshSubscription(r.Context(), name)
+		} else {
+			err = h.subService.RefreshAll(r.Context())
+		}
+		if err != nil {
+			respondError(w, fmt.Sprintf("Failed to refresh subscriptions: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
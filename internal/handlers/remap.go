@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type RemapHandler struct {
+	logger           interfaces.Logger
+	dashboardService interfaces.DashboardService
+	remapService     interfaces.RemapService
+}
+
+func NewRemapHandler(l interfaces.Logger, d interfaces.DashboardService, r interfaces.RemapService) *RemapHandler {
+	return &RemapHandler{
+		logger:           l,
+		dashboardService: d,
+		remapService:     r,
+	}
+}
+
+// GetRemapPlan handles ?characterID=&planName=&implants=, without planName the skill queue is
+// optimized and implants is the bonus of the attribute implants plugged in, 0 when left out.
+func (h *RemapHandler) GetRemapPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		characterID, err := strconv.ParseInt(query.Get("characterID"), 10, 64)
+		if err != nil {
+			respondError(w, "Invalid characterID", http.StatusBadRequest)
+			return
+		}
+		implants := 0
+		if value := query.Get("implants"); value != "" {
+			if implants, err = strconv.Atoi(value); err != nil {
+				respondError(w, "Invalid implants", http.StatusBadRequest)
+				return
+			}
+		}
+
+		accounts := h.dashboardService.GetCurrentAppState().AccountData.Accounts
+		plan, err := h.remapService.OptimizeRemap(accounts, characterID, query.Get("planName"), implants)
+		if err != nil {
+			h.logger.Warnf("Failed to optimize remap: %v", err)
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, plan)
+	}
+}
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
 
@@ -78,6 +82,171 @@ func (h *SkillPlanHandler) SaveSkillPlan() http.HandlerFunc {
 	}
 }
 
+func (h *SkillPlanHandler) UpdateSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var requestData struct {
+			PlanName string `json:"name"`
+			Contents string `json:"contents"`
+			Note     string `json:"note"`
+		}
+		if err := decodeJSONBody(r, &requestData); err != nil {
+			h.logger.Errorf("Failed to parse JSON body: %v", err)
+			respondError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if requestData.PlanName == "" {
+			respondError(w, "Missing planName", http.StatusBadRequest)
+			return
+		}
+
+		if !h.skillService.CheckIfDuplicatePlan(requestData.PlanName) {
+			respondError(w, fmt.Sprintf("skill plan %s not found", requestData.PlanName), http.StatusNotFound)
+			return
+		}
+
+		if err := h.skillService.ParseAndUpdateSkillPlan(requestData.Contents, requestData.PlanName, requestData.Note); err != nil {
+			h.logger.Errorf("Failed to update eve plan: %v", err)
+			respondError(w, "Failed to update eve plan", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *SkillPlanHandler) RenameSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var requestData struct {
+			OldName string `json:"oldName"`
+			NewName string `json:"newName"`
+		}
+		if err := decodeJSONBody(r, &requestData); err != nil {
+			h.logger.Errorf("Failed to parse JSON body: %v", err)
+			respondError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if requestData.OldName == "" || requestData.NewName == "" {
+			respondError(w, "oldName and newName are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.skillService.RenameSkillPlan(requestData.OldName, requestData.NewName); err != nil {
+			h.logger.Errorf("Failed to rename eve plan: %v", err)
+			respondError(w, fmt.Sprintf("Failed to rename plan: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *SkillPlanHandler) GetSkillPlanHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		planName := r.URL.Query().Get("planName")
+		if planName == "" {
+			respondError(w, "Missing planName parameter", http.StatusBadRequest)
+			return
+		}
+
+		revisions, err := h.skillService.GetSkillPlanRevisions(planName)
+		if err != nil {
+			h.logger.Errorf("Failed to load history for %s: %v", planName, err)
+			respondError(w, "Failed to load skill plan history", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, revisions)
+	}
+}
+
+func (h *SkillPlanHandler) DiffSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		planName := query.Get("planName")
+		from, fromErr := strconv.Atoi(query.Get("from"))
+		to, toErr := strconv.Atoi(query.Get("to"))
+		if planName == "" || fromErr != nil || toErr != nil {
+			respondError(w, "planName, from and to parameters are required", http.StatusBadRequest)
+			return
+		}
+
+		diff, err := h.skillService.DiffSkillPlanRevisions(planName, from, to)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		respondJSON(w, diff)
+	}
+}
+
+func (h *SkillPlanHandler) RollbackSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var requestData struct {
+			PlanName string `json:"name"`
+			Revision int    `json:"revision"`
+		}
+		if err := decodeJSONBody(r, &requestData); err != nil {
+			respondError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if requestData.PlanName == "" || requestData.Revision == 0 {
+			respondError(w, "name and revision are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.skillService.RollbackSkillPlan(requestData.PlanName, requestData.Revision); err != nil {
+			h.logger.Errorf("Failed to roll back %s: %v", requestData.PlanName, err)
+			respondError(w, fmt.Sprintf("Failed to roll back plan: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *SkillPlanHandler) GetEmbeddedPlanConflicts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, h.skillService.GetEmbeddedPlanConflicts())
+	}
+}
+
+func (h *SkillPlanHandler) ResolveEmbeddedPlanConflict() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var requestData struct {
+			PlanName   string `json:"name"`
+			Resolution string `json:"resolution"`
+		}
+		if err := decodeJSONBody(r, &requestData); err != nil {
+			respondError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		switch requestData.Resolution {
+		case model.ResolveKeepLocal, model.ResolveUseUpstream, model.ResolveUseMerged:
+		default:
+			respondError(w, "resolution must be one of local, upstream or merged", http.StatusBadRequest)
+			return
+		}
+
+		if requestData.PlanName == "" {
+			respondError(w, "name is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.skillService.ResolveEmbeddedPlanConflict(requestData.PlanName, requestData.Resolution); err != nil {
+			h.logger.Errorf("Failed to resolve embedded plan conflict for %s: %v", requestData.PlanName, err)
+			respondError(w, fmt.Sprintf("Failed to resolve conflict: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
 func (h *SkillPlanHandler) DeleteSkillPlan() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
@@ -101,3 +270,60 @@ func (h *SkillPlanHandler) DeleteSkillPlan() http.HandlerFunc {
 		respondJSON(w, map[string]bool{"success": true})
 	}
 }
+
+func (h *SkillPlanHandler) GetDoctrines() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, h.skillService.GetDoctrines())
+	}
+}
+
+func (h *SkillPlanHandler) SaveDoctrine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var doctrine model.Doctrine
+		if err := decodeJSONBody(r, &doctrine); err != nil {
+			h.logger.Errorf("Failed to parse JSON body: %v", err)
+			respondError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if doctrine.Name == "" {
+			respondError(w, "Missing doctrine name", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.skillService.SaveDoctrine(doctrine); err != nil {
+			h.logger.Errorf("Failed to save doctrine: %v", err)
+			status := http.StatusInternalServerError
+			if errors.Is(err, flyErrors.ErrInvalidDoctrine) {
+				status = http.StatusBadRequest
+			}
+			respondError(w, fmt.Sprintf("Failed to save doctrine: %v", err), status)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
+
+func (h *SkillPlanHandler) DeleteDoctrine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			respondError(w, "Missing name parameter", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.skillService.DeleteDoctrine(name); err != nil {
+			h.logger.Errorf("Failed to delete doctrine: %v", err)
+			respondError(w, "Failed to delete doctrine", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, map[string]bool{"success": true})
+	}
+}
@@ -0,0 +1,42 @@
+package handlers_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/handlers"
+	"github.com/guarzo/canifly/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSkillPlanHandler_SaveDoctrineStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "saved", status: http.StatusOK},
+		{name: "invalid doctrine", err: fmt.Errorf("%w: cannot save doctrine Kikis without plans", flyErrors.ErrInvalidDoctrine), status: http.StatusBadRequest},
+		{name: "storage failure", err: errors.New("disk full"), status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skillSvc := &testutil.MockSkillService{}
+			skillSvc.On("SaveDoctrine", mock.Anything).Return(tt.err)
+			handler := handlers.NewSkillPlanHandler(&testutil.MockLogger{}, skillSvc)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/save-doctrine", strings.NewReader(`{"Name": "Kikis"}`))
+			rr := httptest.NewRecorder()
+			handler.SaveDoctrine().ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			skillSvc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type TimelineHandler struct {
+	logger           interfaces.Logger
+	dashboardService interfaces.DashboardService
+	timelineService  interfaces.TimelineService
+}
+
+func NewTimelineHandler(l interfaces.Logger, d interfaces.DashboardService, t interfaces.TimelineService) *TimelineHandler {
+	return &TimelineHandler{
+		logger:           l,
+		dashboardService: d,
+		timelineService:  t,
+	}
+}
+
+// GetTimeline returns the training timeline of every character, ?from= and ?to= take a
+// date (2006-01-02, to includes the whole day) or an RFC 3339 timestamp.
+func (h *TimelineHandler) GetTimeline() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, err := parseTimelineBound(r.URL.Query().Get("from"), false)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := parseTimelineBound(r.URL.Query().Get("to"), true)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if from != nil && to != nil && to.Before(*from) {
+			respondError(w, "to must not be before from", http.StatusBadRequest)
+			return
+		}
+
+		accounts := h.dashboardService.GetCurrentAppState().AccountData.Accounts
+		respondJSON(w, h.timelineService.BuildTimeline(accounts, from, to))
+	}
+}
+
+func parseTimelineBound(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %s", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type WalletHandler struct {
+	logger        interfaces.Logger
+	walletService interfaces.WalletService
+}
+
+func NewWalletHandler(l interfaces.Logger, w interfaces.WalletService) *WalletHandler {
+	return &WalletHandler{
+		logger:        l,
+		walletService: w,
+	}
+}
+
+func (h *WalletHandler) GetBalanceHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		characterID, err := strconv.ParseInt(r.URL.Query().Get("characterID"), 10, 64)
+		if err != nil {
+			respondError(w, "Invalid characterID", http.StatusBadRequest)
+			return
+		}
+
+		history, err := h.walletService.GetBalanceHistory(characterID)
+		if err != nil {
+			h.logger.Errorf("Failed to load balance history: %v", err)
+			respondError(w, "Failed to load balance history", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, history)
+	}
+}
+
+func (h *WalletHandler) GetJournal() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		characterID, err := strconv.ParseInt(r.URL.Query().Get("characterID"), 10, 64)
+		if err != nil {
+			respondError(w, "Invalid characterID", http.StatusBadRequest)
+			return
+		}
+
+		journal, err := h.walletService.GetJournal(characterID)
+		if err != nil {
+			h.logger.Errorf("Failed to load wallet journal: %v", err)
+			respondError(w, "Failed to load wallet journal", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, journal)
+	}
+}
@@ -32,10 +32,12 @@ type EsiHttpClient struct {
 	Logger       interfaces.Logger
 	AuthClient   interfaces.AuthClient
 	CacheService interfaces.CacheService
+	tokens       *tokenSources
 }
 
-// NewEsiHttpClient initializes and returns an EsiHttpClient instance
-func NewEsiHttpClient(baseURL string, logger interfaces.Logger, auth interfaces.AuthClient, cache interfaces.CacheService) *EsiHttpClient {
+// NewEsiHttpClient initializes and returns an EsiHttpClient instance. Tokens it refreshes are saved to
+// tokenRepo when it is set.
+func NewEsiHttpClient(baseURL string, logger interfaces.Logger, auth interfaces.AuthClient, cache interfaces.CacheService, tokenRepo interfaces.TokenRepository) *EsiHttpClient {
 	return &EsiHttpClient{
 		BaseURL: baseURL,
 		HTTPClient: &http.Client{
@@ -44,6 +46,7 @@ func NewEsiHttpClient(baseURL string, logger interfaces.Logger, auth interfaces.
 		Logger:       logger,
 		AuthClient:   auth,
 		CacheService: cache,
+		tokens:       newTokenSources(logger, auth, tokenRepo),
 	}
 }
 
@@ -68,7 +71,7 @@ func (c *EsiHttpClient) GetJSONFromURL(url string, token *oauth2.Token, useCache
 
 	// Define the operation for retry
 	operation := func() ([]byte, error) {
-		return c.doRequestWithToken("GET", url, nil, token)
+		return c.doRequestWithToken("GET", url, nil, token, true)
 	}
 
 	bodyBytes, err := c.retryWithExponentialBackoff(operation)
@@ -84,8 +87,29 @@ func (c *EsiHttpClient) GetJSONFromURL(url string, token *oauth2.Token, useCache
 	return json.Unmarshal(bodyBytes, target)
 }
 
-// doRequestWithToken performs a request and handles token refresh if necessary.
-func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{}, token *oauth2.Token) ([]byte, error) {
+// PostJSON posts body to the endpoint and decodes the response into target. POST responses are never cached.
+func (c *EsiHttpClient) PostJSON(endpoint string, token *oauth2.Token, body interface{}, target interface{}) error {
+	url := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	operation := func() ([]byte, error) {
+		return c.doRequestWithToken("POST", url, body, token, true)
+	}
+
+	bodyBytes, err := c.retryWithExponentialBackoff(operation)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bodyBytes, target)
+}
+
+// doRequestWithToken performs a request and handles token refresh if necessary, refresh is only
+// tried when allowRefresh is set so a request rejected with a fresh token is not retried forever.
+func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{}, token *oauth2.Token, allowRefresh bool) ([]byte, error) {
+	if allowRefresh && token != nil && token.RefreshToken != "" {
+		if err := c.tokens.current(token); err != nil {
+			return nil, err
+		}
+	}
+
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -114,15 +138,12 @@ func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{},
 	}
 	defer resp.Body.Close()
 
-	if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && token != nil && token.RefreshToken != "" {
-		// Attempt token refresh
-		newToken, refreshErr := c.AuthClient.RefreshToken(token.RefreshToken)
-		if refreshErr != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w", refreshErr)
+	if allowRefresh && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && token != nil && token.RefreshToken != "" {
+		if err := c.tokens.renew(token); err != nil {
+			return nil, err
 		}
-		token.AccessToken = newToken.AccessToken
 		// Retry once with the new token
-		return c.doRequestWithToken(method, url, body, token)
+		return c.doRequestWithToken(method, url, body, token, false)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
@@ -32,7 +32,7 @@ func TestAPIClient_GetJSON_SuccessNoCache(t *testing.T) {
 	authClient := &testutil.MockAuthClient{}
 	cache := &testutil.MockCacheService{}
 
-	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
+	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache, nil)
 
 	var result map[string]string
 	err := client.GetJSON("/", nil, false, &result)
@@ -51,7 +51,7 @@ func TestAPIClient_GetJSON_UsesCache(t *testing.T) {
 	// Mock the cache "Get" call to return the cached data
 	cache.On("Get", "http://example.com/data").Return(cachedBytes, true)
 
-	client := flyHttp.NewEsiHttpClient("http://example.com", logger, authClient, cache)
+	client := flyHttp.NewEsiHttpClient("http://example.com", logger, authClient, cache, nil)
 	var result map[string]string
 
 	err := client.GetJSON("/data", nil, true, &result)
@@ -87,7 +87,7 @@ func TestAPIClient_GetJSON_TokenRefreshOnUnauthorized(t *testing.T) {
 		Return(&oauth2.Token{AccessToken: "new-access-token"}, nil).
 		Once()
 
-	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
+	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache, nil)
 
 	token := &oauth2.Token{
 		AccessToken:  "old-access-token",
@@ -124,7 +124,7 @@ func TestAPIClient_GetJSON_RetryOnServiceUnavailable(t *testing.T) {
 	authClient := &testutil.MockAuthClient{}
 	cache := &testutil.MockCacheService{}
 
-	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
+	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache, nil)
 
 	var result map[string]string
 	err := client.GetJSON("/", nil, false, &result)
@@ -146,7 +146,7 @@ func TestAPIClient_GetJSON_FailsAfterMaxRetries(t *testing.T) {
 	authClient := &testutil.MockAuthClient{}
 	cache := &testutil.MockCacheService{}
 
-	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
+	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache, nil)
 
 	var result map[string]string
 	err := client.GetJSON("/", nil, false, &result)
@@ -176,10 +176,34 @@ func TestAPIClient_GetJSON_NoToken_NoAuthHeaders(t *testing.T) {
 	authClient := &testutil.MockAuthClient{}
 	cache := &testutil.MockCacheService{}
 
-	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
+	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache, nil)
 
 	var result map[string]bool
 	err := client.GetJSON("/", nil, false, &result)
 	require.NoError(t, err)
 	assert.True(t, result["success"])
 }
+
+func TestAPIClient_PostJSON(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/names", r.URL.Path)
+		var ids []int64
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ids))
+		assert.Equal(t, []int64{1, 2}, ids)
+		w.Write([]byte(`[{"id":1,"name":"one"},{"id":2,"name":"two"}]`))
+	})
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	client := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, &testutil.MockAuthClient{}, &testutil.MockCacheService{}, nil)
+
+	var result []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	require.NoError(t, client.PostJSON("/names", nil, []int64{1, 2}, &result))
+	require.Len(t, result, 2)
+	assert.Equal(t, "two", result[1].Name)
+}
@@ -24,6 +24,7 @@ func AuthMiddleware(s interfaces.SessionService, logger interfaces.Logger) mux.M
 				"/callback":           true,
 				"/api/add-character":  true,
 				"/api/finalize-login": true,
+				"/calendar/":          true, // feed URLs carry their own token
 			}
 
 			// Allow access if the request matches a public route
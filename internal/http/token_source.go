@@ -0,0 +1,108 @@
+package http
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// tokenRefreshMargin is how long before it expires an access token is renewed
+const tokenRefreshMargin = time.Minute
+
+// tokenSources keeps the current token of every character the client called ESI for. Requests only carry
+// a token, so a source is found by refresh token, and it stays reachable under the refresh tokens it
+// rotated out so copies of an old token pick up the current one instead of refreshing again.
+type tokenSources struct {
+	logger  interfaces.Logger
+	auth    interfaces.AuthClient
+	repo    interfaces.TokenRepository
+	mu      sync.Mutex
+	sources map[string]*tokenSource
+}
+
+// tokenSource is the token of one character, its mutex lets a single request at a time refresh it.
+type tokenSource struct {
+	mu    sync.Mutex
+	token oauth2.Token
+}
+
+func newTokenSources(logger interfaces.Logger, auth interfaces.AuthClient, repo interfaces.TokenRepository) *tokenSources {
+	return &tokenSources{
+		logger:  logger,
+		auth:    auth,
+		repo:    repo,
+		sources: make(map[string]*tokenSource),
+	}
+}
+
+func (t *tokenSources) source(token *oauth2.Token) *tokenSource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	src, ok := t.sources[token.RefreshToken]
+	if !ok {
+		src = &tokenSource{token: *token}
+		t.sources[token.RefreshToken] = src
+	}
+	return src
+}
+
+// current brings token up to date with its source, refreshing it first when it is about to expire.
+func (t *tokenSources) current(token *oauth2.Token) error {
+	src := t.source(token)
+	src.mu.Lock()
+	defer src.mu.Unlock()
+
+	if expiresSoon(&src.token) {
+		return t.refreshLocked(src, token)
+	}
+	*token = src.token
+	return nil
+}
+
+// renew refreshes a token ESI rejected, unless another request refreshed it in the meantime.
+func (t *tokenSources) renew(token *oauth2.Token) error {
+	src := t.source(token)
+	src.mu.Lock()
+	defer src.mu.Unlock()
+
+	if src.token.AccessToken != token.AccessToken && !expiresSoon(&src.token) {
+		*token = src.token
+		return nil
+	}
+	return t.refreshLocked(src, token)
+}
+
+// refreshLocked trades the refresh token of src for a new token and saves it, src.mu must be held.
+func (t *tokenSources) refreshLocked(src *tokenSource, token *oauth2.Token) error {
+	previous := src.token.RefreshToken
+	newToken, err := t.auth.RefreshToken(previous)
+	if err != nil {
+		return fmt.Errorf("%w: %w", flyErrors.ErrTokenRefreshFailed, err)
+	}
+	if newToken.RefreshToken == "" {
+		newToken.RefreshToken = previous
+	}
+
+	src.token = *newToken
+	*token = *newToken
+	t.mu.Lock()
+	t.sources[newToken.RefreshToken] = src
+	t.mu.Unlock()
+
+	if t.repo != nil {
+		if err := t.repo.SaveRefreshedToken(previous, *newToken); err != nil {
+			t.logger.Warnf("failed to save refreshed token: %v", err)
+		}
+	}
+	return nil
+}
+
+// expiresSoon is false for tokens without an expiry, they are only refreshed once ESI rejects them.
+func expiresSoon(token *oauth2.Token) bool {
+	return !token.Expiry.IsZero() && time.Until(token.Expiry) < tokenRefreshMargin
+}
@@ -0,0 +1,95 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	flyHttp "github.com/guarzo/canifly/internal/http"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+// savedTokens records the tokens the client saves, by the refresh token they replace.
+type savedTokens struct {
+	mu     sync.Mutex
+	tokens map[string]oauth2.Token
+}
+
+func (s *savedTokens) SaveRefreshedToken(refreshToken string, token oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[refreshToken] = token
+	return nil
+}
+
+// bearerServer answers requests authorized with accessToken and rejects any other.
+func bearerServer(t *testing.T, accessToken string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+accessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAPIClient_RefreshesExpiringTokenBeforeRequest(t *testing.T) {
+	ts := bearerServer(t, "new-access")
+	expiry := time.Now().Add(20 * time.Minute)
+	authClient := &testutil.MockAuthClient{}
+	authClient.On("RefreshToken", "old-refresh").
+		Return(&oauth2.Token{AccessToken: "new-access", RefreshToken: "new-refresh", Expiry: expiry}, nil).
+		Once()
+	saved := &savedTokens{tokens: make(map[string]oauth2.Token)}
+	client := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, authClient, &testutil.MockCacheService{}, saved)
+
+	token := &oauth2.Token{AccessToken: "old-access", RefreshToken: "old-refresh", Expiry: time.Now().Add(30 * time.Second)}
+	var result map[string]interface{}
+	require.NoError(t, client.GetJSON("/", token, false, &result))
+
+	assert.Equal(t, "new-refresh", token.RefreshToken, "the rotated refresh token is kept")
+	assert.True(t, expiry.Equal(token.Expiry))
+	assert.Equal(t, map[string]oauth2.Token{"old-refresh": *token}, saved.tokens)
+
+	// a copy of the old token uses the new one without refreshing again
+	stale := &oauth2.Token{AccessToken: "old-access", RefreshToken: "old-refresh", Expiry: time.Now().Add(30 * time.Second)}
+	require.NoError(t, client.GetJSON("/", stale, false, &result))
+	assert.Equal(t, "new-access", stale.AccessToken)
+	authClient.AssertExpectations(t)
+}
+
+func TestAPIClient_ConcurrentRequestsRefreshOnce(t *testing.T) {
+	ts := bearerServer(t, "new-access")
+	authClient := &testutil.MockAuthClient{}
+	authClient.On("RefreshToken", "refresh").
+		Return(&oauth2.Token{AccessToken: "new-access", RefreshToken: "rotated", Expiry: time.Now().Add(20 * time.Minute)}, nil).
+		Once()
+	client := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, authClient, &testutil.MockCacheService{}, nil)
+
+	// ESI rejects the access token before it expires, as when it was revoked by another login
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token := &oauth2.Token{AccessToken: "old-access", RefreshToken: "refresh", Expiry: time.Now().Add(10 * time.Minute)}
+			var result map[string]interface{}
+			errs[i] = client.GetJSON("/", token, false, &result)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	authClient.AssertExpectations(t)
+}
@@ -14,17 +14,56 @@ const (
 	Omega AccountStatus = "Omega"
 )
 
+// Servers an account can live on, ESI tells them apart by datasource
+const (
+	ServerTranquility = "tranquility"
+	ServerSingularity = "singularity"
+)
+
+// NormalizeServer maps an account's Server to a known server, accounts saved before servers
+// existed are on Tranquility. ok is false for anything else.
+func NormalizeServer(server string) (string, bool) {
+	switch server {
+	case "", ServerTranquility:
+		return ServerTranquility, true
+	case ServerSingularity:
+		return ServerSingularity, true
+	}
+	return ServerTranquility, false
+}
+
 type Account struct {
-	Name       string
-	Status     AccountStatus
-	Characters []CharacterIdentity
-	ID         int64 // userFile ID for this account, defaults to 0 until assigned
-	Visible    bool  // toggle visibility
+	Name         string
+	Status       AccountStatus
+	StatusReason string // why the account has its current status, either the detected evidence or a manual change
+	Characters   []CharacterIdentity
+	ID           int64      // userFile ID for this account, defaults to 0 until assigned
+	Visible      bool       // toggle visibility
+	OmegaExpiry  *time.Time `json:"OmegaExpiry,omitempty"` // entered by the user, nil when unknown
+	Server       string     `json:"Server,omitempty"`      // ServerTranquility when empty
 }
 
 type AccountData struct {
-	Accounts     []Account
-	Associations []Association // in app assigned connections between user and char files (effectively connecting characters to accounts)
+	Accounts         []Account
+	Associations     []Association    // in app assigned connections between user and char files (effectively connecting characters to accounts)
+	AssociationRules AssociationRules // limits applied when associating
+}
+
+// DefaultMaxCharactersPerUser is the number of character slots of an EVE account
+const DefaultMaxCharactersPerUser = 3
+
+// AssociationRules limit the associations, the zero value is the EVE account layout
+type AssociationRules struct {
+	MaxCharactersPerUser  int  `json:"maxCharactersPerUser"`  // DefaultMaxCharactersPerUser when 0
+	AllowSharedCharacters bool `json:"allowSharedCharacters"` // a character may be associated with more than one user file
+}
+
+// CharacterLimit returns the number of characters a user file may be associated with
+func (r AssociationRules) CharacterLimit() int {
+	if r.MaxCharactersPerUser <= 0 {
+		return DefaultMaxCharactersPerUser
+	}
+	return r.MaxCharactersPerUser
 }
 
 // Association are the user connections between char and user files (userID does correspond to accountId)
@@ -34,6 +73,44 @@ type Association struct {
 	CharName string `json:"charName"`
 }
 
+// AssociationSuggestion is an association inferred from user and char files written together
+type AssociationSuggestion struct {
+	UserId     string  `json:"userId"`
+	UserName   string  `json:"userName"`
+	CharId     string  `json:"charId"`
+	CharName   string  `json:"charName"`
+	Confidence float64 `json:"confidence"` // 0 to 1
+	Matches    int     `json:"matches"`    // profiles where both files were written together
+	Profiles   int     `json:"profiles"`   // profiles holding the char file
+}
+
+// AssociationRejection is a suggestion that could not be accepted
+type AssociationRejection struct {
+	UserId string `json:"userId"`
+	CharId string `json:"charId"`
+	Reason string `json:"reason"`
+}
+
+// Kinds of association issues
+const (
+	AssociationOrphaned         = "orphaned"          // the user file is in none of the profiles
+	AssociationRemovedCharacter = "removed_character" // the character no longer exists
+	AssociationAccountMismatch  = "account_mismatch"  // the account ID disagrees with the associations of its characters
+	AssociationDuplicate        = "duplicate"         // the same association is stored twice
+	AssociationDuplicateName    = "duplicate_name"    // accounts sharing a name
+	AssociationRuleViolation    = "rule_violation"    // made before the rules were tightened
+)
+
+// AssociationIssue is an inconsistency found by the association checker
+type AssociationIssue struct {
+	Kind       string `json:"kind"`
+	UserId     string `json:"userId,omitempty"`
+	CharId     string `json:"charId,omitempty"`
+	Account    string `json:"account,omitempty"`
+	Message    string `json:"message"`
+	Repairable bool   `json:"repairable"`
+}
+
 type CharacterIdentity struct {
 	Token           oauth2.Token
 	Character       Character
@@ -41,14 +118,67 @@ type CharacterIdentity struct {
 	AllianceName    string
 	Role            string
 	MCT             bool
+	MCTExpiry       *time.Time `json:"MCTExpiry,omitempty"` // expiry of the MCT slot this character trains in, entered by the user
 	Training        string
+	TokenRevoked    bool     `json:"TokenRevoked,omitempty"`    // EVE SSO rejected the refresh token on the last refresh
+	TokenState      string   `json:"TokenState,omitempty"`      // TokenStateValid when empty
+	RefreshFailures int      `json:"RefreshFailures,omitempty"` // account refreshes in a row that failed to renew the token
+	MissingScopes   []string `json:"MissingScopes,omitempty"`   // scopes requested by the app the token was not granted
+}
+
+// Token states of a character
+const (
+	TokenStateValid         = "valid"
+	TokenStateExpiring      = "expiring"       // the access token could not be renewed on several refreshes in a row
+	TokenStateRevoked       = "revoked"        // EVE SSO no longer accepts the refresh token
+	TokenStateMissingScopes = "missing_scopes" // logged in before the app asked for more scopes
+)
+
+// EsiScopes are the scopes requested when a character logs in
+var EsiScopes = []string{
+	"publicData",
+	"esi-location.read_location.v1",
+	"esi-skills.read_skills.v1",
+	"esi-clones.read_clones.v1",
+	"esi-clones.read_implants.v1",
+	"esi-skills.read_skillqueue.v1",
+	"esi-characters.read_corporation_roles.v1",
+	"esi-wallet.read_character_wallet.v1",
+	"esi-assets.read_assets.v1",
+	"esi-universe.read_structures.v1",
+}
+
+// TokenWarning is a character that has to log in again
+type TokenWarning struct {
+	AccountName   string   `json:"AccountName"`
+	CharacterID   int64    `json:"CharacterID"`
+	CharacterName string   `json:"CharacterName"`
+	State         string   `json:"State"`
+	MissingScopes []string `json:"MissingScopes,omitempty"`
+}
+
+const (
+	ExpiryOmega = "omega"
+	ExpiryMCT   = "mct"
+)
+
+// ExpiryWarning is an omega subscription or MCT slot that has expired or is about to
+type ExpiryWarning struct {
+	Kind          string    `json:"Kind"`
+	AccountID     int64     `json:"AccountID"`
+	AccountName   string    `json:"AccountName"`
+	CharacterID   int64     `json:"CharacterID,omitempty"`
+	CharacterName string    `json:"CharacterName,omitempty"`
+	Expires       time.Time `json:"Expires"`
+	DaysLeft      int       `json:"DaysLeft"` // negative once expired
 }
 
 type Character struct {
 	UserInfoResponse
 	CharacterSkillsResponse `json:"CharacterSkillsResponse"`
-	Location                int64  `json:"Location"`
-	LocationName            string `json:"LocationName"`
+	Location                int64    `json:"Location"`
+	LocationName            string   `json:"LocationName"`
+	WalletBalance           *float64 `json:"WalletBalance,omitempty"` // nil until the wallet scope has been granted
 
 	SkillQueue         []SkillQueue                `json:"SkillQueue"`
 	QualifiedPlans     map[string]bool             `json:"QualifiedPlans"`
@@ -84,8 +214,10 @@ type CharacterSkillsResponse struct {
 }
 
 type AuthStatus struct {
-	AccountName      string `json:"accountName"`
-	CallBackComplete bool   `json:"callBackComplete"`
+	AccountName       string `json:"accountName"`
+	CallBackComplete  bool   `json:"callBackComplete"`
+	Server            string `json:"server"`
+	ReauthCharacterID int64  `json:"reauthCharacterID,omitempty"` // set when an existing character logs in again
 }
 
 // Alliance contains detailed information about an EVE Online alliance
@@ -0,0 +1,57 @@
+// model/asset.go
+package model
+
+import "time"
+
+// EsiAsset is a single asset as returned by /characters/{id}/assets/
+type EsiAsset struct {
+	ItemID          int64  `json:"item_id"`
+	TypeID          int64  `json:"type_id"`
+	Quantity        int32  `json:"quantity"`
+	LocationID      int64  `json:"location_id"`
+	LocationFlag    string `json:"location_flag"`
+	LocationType    string `json:"location_type"` // station, solar_system, item or other
+	IsSingleton     bool   `json:"is_singleton"`
+	IsBlueprintCopy bool   `json:"is_blueprint_copy,omitempty"`
+}
+
+// UniverseName is an entry returned by /universe/names/ and /characters/{id}/assets/names/
+type UniverseName struct {
+	ID       int64  `json:"id"`
+	ItemID   int64  `json:"item_id"` // set by the asset names endpoint instead of id
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+}
+
+// AssetItem is an asset with its names and top level location resolved
+type AssetItem struct {
+	CharacterID   int64  `json:"CharacterID"`
+	CharacterName string `json:"CharacterName"`
+	AccountName   string `json:"AccountName"`
+	ItemID        int64  `json:"ItemID"`
+	TypeID        int64  `json:"TypeID"`
+	TypeName      string `json:"TypeName"`
+	Name          string `json:"Name,omitempty"` // player given name of an assembled ship or container
+	Quantity      int32  `json:"Quantity"`
+	LocationFlag  string `json:"LocationFlag"`
+	ContainerID   int64  `json:"ContainerID,omitempty"` // ship or container the item is in
+	ContainerName string `json:"ContainerName,omitempty"`
+	LocationID    int64  `json:"LocationID"` // station, structure or system the item (or its container) is in
+	LocationName  string `json:"LocationName"`
+	SystemID      int64  `json:"SystemID,omitempty"`
+	SystemName    string `json:"SystemName,omitempty"`
+}
+
+// CharacterAssets is the indexed assets of one character
+type CharacterAssets struct {
+	CharacterID   int64       `json:"CharacterID"`
+	CharacterName string      `json:"CharacterName"`
+	UpdatedAt     time.Time   `json:"UpdatedAt"`
+	LastError     string      `json:"LastError,omitempty"` // set when the last refresh failed and older items were kept
+	Items         []AssetItem `json:"Items"`
+}
+
+// AssetIndex is the locally stored asset index, keyed by character ID
+type AssetIndex struct {
+	Characters map[int64]CharacterAssets `json:"Characters"`
+}
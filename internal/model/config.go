@@ -12,6 +12,10 @@ type AppState struct {
 	AccountData AccountData `json:"AccountData"`
 	ConfigData  ConfigData  `json:"ConfigData"`
 	EveData     EveData     `json:"EveData"`
+
+	ExpiryWarnings []ExpiryWarning `json:"ExpiryWarnings"`
+	TokenWarnings  []TokenWarning  `json:"TokenWarnings"`
+	Wallets        WalletSummary   `json:"Wallets"`
 }
 
 // DropDownSelections  are the dropdown selections on the sync page
@@ -24,10 +28,31 @@ type UserSelection struct {
 
 // ConfigData are user settings and other app specific configuration
 type ConfigData struct {
-	Roles              []string `json:"Roles"`         // in app created roles for organizing data
-	SettingsDir        string   `json:"SettingsDir"`   // directory where the settings are kept
-	LastBackupDir      string   `json:"LastBackupDir"` // directory used for the previous backup
-	DropDownSelections          // dropdown selections within the app
+	Roles              []string       `json:"Roles"`                   // in app created roles for organizing data
+	SettingsDir        string         `json:"SettingsDir"`             // directory where the settings are kept
+	SettingsRoots      []SettingsRoot `json:"SettingsRoots,omitempty"` // settings directories scanned besides SettingsDir
+	LastBackupDir      string         `json:"LastBackupDir"`           // directory used for the previous backup
+	CalendarToken      string         `json:"CalendarToken,omitempty"` // secret part of the calendar feed URL
+	DropDownSelections                // dropdown selections within the app
+}
+
+// PrimarySettingsRoot names SettingsDir among the settings roots
+const PrimarySettingsRoot = "primary"
+
+// SettingsRoot is an EVE settings directory such as a Singularity install, a Wine prefix or a
+// second Windows install. Name tells the roots apart in profiles and sync requests.
+type SettingsRoot struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// SettingsDirCandidate is an EVE settings directory found on disk
+type SettingsDirCandidate struct {
+	Path         string     `json:"path"`
+	Source       string     `json:"source"`       // native, steam (Proton) or wine
+	Server       string     `json:"server"`       // ServerTranquility or ServerSingularity
+	Profiles     int        `json:"profiles"`     // settings_ subdirectories
+	LastModified *time.Time `json:"lastModified"` // newest char or user file, nil when there are none
 }
 
 func init() {
@@ -51,5 +76,7 @@ func init() {
 	gob.Register([]EveProfile{})
 	gob.Register([]Association{})
 	gob.Register(Association{})
+	gob.Register(Doctrine{})
+	gob.Register(map[string]DoctrineWithStatus{})
 
 }
@@ -9,16 +9,27 @@ import (
 type EveData struct {
 	EveProfiles    []EveProfile
 	SkillPlans     map[string]SkillPlanWithStatus
+	Doctrines      map[string]DoctrineWithStatus
 	EveConversions map[string]string // converts skill id to skill name
 }
 
 // EveProfile is the data from the eve settings
 type EveProfile struct {
+	Root               string     `json:"root"`               // name of the settings root the profile is in
 	Profile            string     `json:"profile"`            // eve profile name
 	AvailableCharFiles []CharFile `json:"availableCharFiles"` // character files for a given profile
 	AvailableUserFiles []UserFile `json:"availableUserFiles"` // user files for a given profile
 }
 
+// SettingsChangeEvent reports a profile whose char or user files changed on disk
+type SettingsChangeEvent struct {
+	Root    string    `json:"root"`
+	Profile string    `json:"profile"`
+	Files   []string  `json:"files"`   // core_char_ and core_user_ files written, added or deleted
+	Removed bool      `json:"removed"` // the profile directory is gone
+	Time    time.Time `json:"time"`
+}
+
 // RawFileInfo represents basic information extracted from an EVE settings file.
 type RawFileInfo struct {
 	FileName     string
@@ -30,7 +41,7 @@ type RawFileInfo struct {
 type Station struct {
 	SystemID int64  `json:"system_id"`
 	ID       int64  `json:"station_id"`
-	Name     string `json:"station_name"`
+	Name     string `json:"name"`
 }
 
 type Structure struct {
@@ -63,6 +74,8 @@ type SkillPlanWithStatus struct {
 	Name                string
 	TypeId              int64 // used for image lookup
 	Skills              map[string]Skill
+	Origin              string // subscription the plan was pulled from, empty for local plans
+	ReadOnly            bool
 	QualifiedCharacters []string
 	PendingCharacters   []string
 	MissingSkills       map[string]map[string]int32 // Missing skills by character
@@ -73,10 +86,44 @@ type SkillPlanWithStatus struct {
 type CharacterSkillPlanStatus struct {
 	CharacterName     string
 	Status            string // "qualified", "pending", "missing"
+	Tier              string // highest doctrine tier met, empty for plans outside a doctrine
 	MissingSkills     map[string]int32
 	PendingFinishDate *time.Time
 }
 
+const (
+	TierMinimum     = "Minimum"
+	TierRecommended = "Recommended"
+)
+
+// Doctrine groups several skill plans (for example DPS, logi and tackle) flown together
+type Doctrine struct {
+	Name  string         `json:"Name"`
+	Plans []DoctrinePlan `json:"Plans"`
+}
+
+// DoctrinePlan is a single plan within a doctrine with a minimum and a recommended tier
+type DoctrinePlan struct {
+	Name        string           `json:"Name"`
+	Role        string           `json:"Role"`
+	Minimum     map[string]Skill `json:"Minimum"`
+	Recommended map[string]Skill `json:"Recommended"` // skills on top of (or above) the minimum tier
+}
+
+// DoctrineWithStatus holds a doctrine together with the tier each character meets per plan
+type DoctrineWithStatus struct {
+	Name  string
+	Plans []DoctrinePlanWithStatus
+}
+
+// DoctrinePlanWithStatus holds a doctrine plan and the status of every character for it
+type DoctrinePlanWithStatus struct {
+	DoctrinePlan
+	MinimumCharacters     []string
+	RecommendedCharacters []string
+	Characters            []CharacterSkillPlanStatus
+}
+
 type SkillResponse struct {
 	ActiveSkillLevel   int32 `json:"active_skill_level"`
 	SkillID            int32 `json:"skill_id"`
@@ -105,10 +152,76 @@ type Skill struct {
 type SkillPlan struct {
 	Name                string           `json:"Name"`
 	Skills              map[string]Skill `json:"Skills"`
+	Origin              string           `json:"Origin,omitempty"` // subscription the plan was pulled from, empty for local plans
+	ReadOnly            bool             `json:"ReadOnly,omitempty"`
 	QualifiedCharacters []string         `json:"QualifiedCharacters"`
 	PendingCharacters   []string         `json:"PendingCharacters"`
 }
 
+// SkillPlanRevision is a saved version of a skill plan
+type SkillPlanRevision struct {
+	Revision  int              `json:"Revision"`
+	Timestamp time.Time        `json:"Timestamp"`
+	Note      string           `json:"Note,omitempty"`
+	Skills    map[string]Skill `json:"Skills"`
+}
+
+// SkillPlanDiff lists the differences between two revisions of a skill plan
+type SkillPlanDiff struct {
+	PlanName string             `json:"PlanName"`
+	From     int                `json:"From"`
+	To       int                `json:"To"`
+	Added    []Skill            `json:"Added"`
+	Removed  []Skill            `json:"Removed"`
+	Changed  []SkillLevelChange `json:"Changed"`
+}
+
+// SkillLevelChange is a skill whose required level differs between two revisions
+type SkillLevelChange struct {
+	Name      string `json:"Name"`
+	FromLevel int    `json:"FromLevel"`
+	ToLevel   int    `json:"ToLevel"`
+}
+
+const (
+	ResolveKeepLocal   = "local"
+	ResolveUseUpstream = "upstream"
+	ResolveUseMerged   = "merged"
+)
+
+// EmbeddedPlanConflict describes an updated embedded plan that could not be merged with local edits
+type EmbeddedPlanConflict struct {
+	PlanName  string               `json:"PlanName"`
+	Local     map[string]Skill     `json:"Local"`
+	Upstream  map[string]Skill     `json:"Upstream"`
+	Merged    map[string]Skill     `json:"Merged"` // proposed result, keeping local levels where both sides changed
+	Conflicts []SkillMergeConflict `json:"Conflicts"`
+}
+
+// SkillMergeConflict is a skill changed differently by the user and by the new release, levels are 0 when absent
+type SkillMergeConflict struct {
+	Name          string `json:"Name"`
+	BaseLevel     int    `json:"BaseLevel"`
+	LocalLevel    int    `json:"LocalLevel"`
+	UpstreamLevel int    `json:"UpstreamLevel"`
+}
+
+// PlanSubscription is a remote source of read-only skill plans. URL points at a JSON index
+// listing plan files relative to the index, e.g. {"plans": ["logi.txt", "dps/ferox.txt"]}.
+type PlanSubscription struct {
+	Name        string            `json:"Name"`
+	URL         string            `json:"URL"`
+	ETags       map[string]string `json:"ETags"` // last seen ETag by resource URL
+	Plans       []string          `json:"Plans"` // plan files listed by the last index fetched
+	LastChecked time.Time         `json:"LastChecked"`
+	LastError   string            `json:"LastError,omitempty"`
+}
+
+// RemotePlanIndex is the document served at a subscription URL
+type RemotePlanIndex struct {
+	Plans []string `json:"plans"`
+}
+
 // SkillType represents a eve with typeID, typeName, and description.
 type SkillType struct {
 	TypeID      string
@@ -129,3 +242,64 @@ type UserFile struct {
 	Name   string `json:"name"`
 	Mtime  string `json:"mtime"`
 }
+
+// TimelineEntry is a single queued skill level on the global training timeline
+type TimelineEntry struct {
+	AccountName   string     `json:"AccountName"`
+	CharacterID   int64      `json:"CharacterID"`
+	CharacterName string     `json:"CharacterName"`
+	SkillID       int32      `json:"SkillID"`
+	SkillName     string     `json:"SkillName"`
+	Level         int32      `json:"Level"`
+	StartDate     *time.Time `json:"StartDate,omitempty"`
+	FinishDate    time.Time  `json:"FinishDate"`
+	SkillPoints   int64      `json:"SkillPoints"` // SP gained by the entry, from its training start SP to the end of the level
+}
+
+// QueueEnd is when the skill queue of a character runs out
+type QueueEnd struct {
+	AccountName   string    `json:"AccountName"`
+	CharacterID   int64     `json:"CharacterID"`
+	CharacterName string    `json:"CharacterName"`
+	Ends          time.Time `json:"Ends"`
+}
+
+// DailySP is the skill points gained across every character on a single UTC day
+type DailySP struct {
+	Date        string `json:"Date"` // 2006-01-02
+	SkillPoints int64  `json:"SkillPoints"`
+}
+
+// TrainingTimeline merges every character's skill queue, limited to an optional date range
+type TrainingTimeline struct {
+	From      *time.Time      `json:"From,omitempty"`
+	To        *time.Time      `json:"To,omitempty"`
+	Entries   []TimelineEntry `json:"Entries"`   // ordered by finish date
+	QueueEnds []QueueEnd      `json:"QueueEnds"` // soonest first
+	DailySP   []DailySP       `json:"DailySP"`   // ordered by date
+}
+
+// InjectorPlan compares finishing a skill plan with Large Skill Injectors to training it
+type InjectorPlan struct {
+	PlanName        string   `json:"PlanName"`
+	CharacterID     int64    `json:"CharacterID"`
+	CharacterName   string   `json:"CharacterName"`
+	TotalSP         int64    `json:"TotalSP"`
+	UnallocatedSP   int64    `json:"UnallocatedSP"`
+	RequiredSP      int64    `json:"RequiredSP"` // SP missing from the plan, before unallocated SP is applied
+	Injectors       int      `json:"Injectors"`
+	InjectedSP      int64    `json:"InjectedSP"`      // SP the injectors add, after diminishing returns
+	TrainingHours   float64  `json:"TrainingHours"`   // time to train RequiredSP at SPPerHour
+	SPPerHour       float64  `json:"SPPerHour"`       // measured from the active queue entry, or a default
+	EstimatedSkills []string `json:"EstimatedSkills"` // skills with an unknown rank, counted as rank 1
+}
+
+// ExtractionPlan is how much SP a character can give up to Skill Extractors
+type ExtractionPlan struct {
+	CharacterID   int64  `json:"CharacterID"`
+	CharacterName string `json:"CharacterName"`
+	TotalSP       int64  `json:"TotalSP"`
+	Extractors    int    `json:"Extractors"`
+	ExtractableSP int64  `json:"ExtractableSP"`
+	RemainingSP   int64  `json:"RemainingSP"` // allocated SP left after extracting
+}
@@ -0,0 +1,63 @@
+// model/notification.go
+package model
+
+import "time"
+
+const (
+	AlertQueueEnding    = "queue_ending"    // skill queue runs out within the configured window
+	AlertIdleOmega      = "idle_omega"      // no character on an omega account is training
+	AlertPlanQualified  = "plan_qualified"  // character newly meets every skill in a plan
+	AlertSkillCompleted = "skill_completed" // a queued skill finished training
+	AlertQueueEmpty     = "queue_empty"     // character has nothing left in its skill queue
+	AlertTokenRevoked   = "token_revoked"   // the refresh token of a character was rejected by EVE SSO
+	AlertDoctrineReady  = "doctrine_ready"  // character newly meets the minimum tier of a doctrine plan
+)
+
+// AlertRules lists every rule in the order they are shown in the settings
+var AlertRules = []string{
+	AlertSkillCompleted,
+	AlertPlanQualified,
+	AlertDoctrineReady,
+	AlertQueueEnding,
+	AlertQueueEmpty,
+	AlertIdleOmega,
+	AlertTokenRevoked,
+}
+
+// Alert is raised when a rule starts to hold and resolved once it stops holding. ID identifies the
+// rule and subject so repeated refreshes do not raise the same alert twice.
+type Alert struct {
+	ID              string     `json:"ID"`
+	Rule            string     `json:"Rule"`
+	AccountName     string     `json:"AccountName"`
+	CharacterID     int64      `json:"CharacterID"`
+	CharacterName   string     `json:"CharacterName"`
+	PlanName        string     `json:"PlanName,omitempty"`
+	Message         string     `json:"Message"`
+	RaisedAt        time.Time  `json:"RaisedAt"`
+	ResolvedAt      *time.Time `json:"ResolvedAt,omitempty"`
+	Acknowledged    bool       `json:"Acknowledged"`
+	PendingDelivery bool       `json:"PendingDelivery,omitempty"` // waiting to be posted to the webhook
+}
+
+// NotificationEventSettings configures webhook delivery for a single alert rule
+type NotificationEventSettings struct {
+	Enabled            bool   `json:"Enabled"`
+	MinIntervalMinutes int    `json:"MinIntervalMinutes"` // minimum time between two webhook posts for the rule
+	Mention            string `json:"Mention,omitempty"`  // prefixed to the message, e.g. @here or <@&role> on Discord, <!here> on Slack
+}
+
+// NotificationSettings configures alert evaluation and delivery
+type NotificationSettings struct {
+	WebhookURL       string                               `json:"WebhookURL"`       // alerts are posted here when set
+	QueueEndingHours int                                  `json:"QueueEndingHours"` // window for the queue ending rule, defaults to 24
+	Events           map[string]NotificationEventSettings `json:"Events"`           // keyed by rule, missing rules are enabled
+}
+
+// NotificationState is everything the notification subsystem persists
+type NotificationState struct {
+	Settings            NotificationSettings `json:"Settings"`
+	Alerts              []Alert              `json:"Alerts"`
+	BaselinedCharacters map[int64]bool       `json:"BaselinedCharacters"` // characters whose existing milestones have been recorded without announcing them
+	LastSent            map[string]time.Time `json:"LastSent"`            // last webhook post per rule
+}
@@ -0,0 +1,66 @@
+// model/remap.go
+package model
+
+import "time"
+
+// Dogma attribute IDs of the character attributes and of the skill attributes that refer to them
+const (
+	DogmaCharisma           = 164
+	DogmaIntelligence       = 165
+	DogmaMemory             = 166
+	DogmaPerception         = 167
+	DogmaWillpower          = 168
+	DogmaPrimaryAttribute   = 180
+	DogmaSecondaryAttribute = 181
+	DogmaSkillTimeConstant  = 275 // the skill's rank
+)
+
+// CharacterAttributes is the response of /characters/{id}/attributes/, without implants
+type CharacterAttributes struct {
+	Charisma                 int        `json:"charisma"`
+	Intelligence             int        `json:"intelligence"`
+	Memory                   int        `json:"memory"`
+	Perception               int        `json:"perception"`
+	Willpower                int        `json:"willpower"`
+	BonusRemaps              int        `json:"bonus_remaps,omitempty"`
+	LastRemapDate            *time.Time `json:"last_remap_date,omitempty"`
+	AccruedRemapCooldownDate *time.Time `json:"accrued_remap_cooldown_date,omitempty"`
+}
+
+// EsiType is the response of /universe/types/{id}/, only the fields the app uses
+type EsiType struct {
+	TypeID          int64            `json:"type_id"`
+	Name            string           `json:"name"`
+	DogmaAttributes []DogmaAttribute `json:"dogma_attributes"`
+}
+
+type DogmaAttribute struct {
+	AttributeID int64   `json:"attribute_id"`
+	Value       float64 `json:"value"`
+}
+
+// AttributeSet is a full set of character attributes
+type AttributeSet struct {
+	Charisma     int `json:"Charisma"`
+	Intelligence int `json:"Intelligence"`
+	Memory       int `json:"Memory"`
+	Perception   int `json:"Perception"`
+	Willpower    int `json:"Willpower"`
+}
+
+// RemapPlan compares training a plan or the skill queue with the current attributes to the best remap
+type RemapPlan struct {
+	CharacterID    int64        `json:"CharacterID"`
+	CharacterName  string       `json:"CharacterName"`
+	PlanName       string       `json:"PlanName,omitempty"` // empty when the skill queue was optimized
+	ImplantBonus   int          `json:"ImplantBonus"`       // added to every attribute on both sides
+	Current        AttributeSet `json:"Current"`
+	Optimal        AttributeSet `json:"Optimal"`
+	TrainingSP     int64        `json:"TrainingSP"`
+	CurrentHours   float64      `json:"CurrentHours"`
+	OptimalHours   float64      `json:"OptimalHours"` // including any training before the remap is available
+	SavedHours     float64      `json:"SavedHours"`
+	RemapAvailable bool         `json:"RemapAvailable"`
+	BonusRemaps    int          `json:"BonusRemaps"`
+	NextRemapDate  *time.Time   `json:"NextRemapDate,omitempty"` // set when no remap is available yet
+}
@@ -0,0 +1,43 @@
+// model/wallet.go
+package model
+
+import "time"
+
+// WalletJournalEntry is a single line of a character's wallet journal as returned by ESI
+type WalletJournalEntry struct {
+	ID            int64     `json:"id"`
+	Date          time.Time `json:"date"`
+	RefType       string    `json:"ref_type"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+	Description   string    `json:"description"`
+	Reason        string    `json:"reason,omitempty"`
+	FirstPartyID  int64     `json:"first_party_id,omitempty"`
+	SecondPartyID int64     `json:"second_party_id,omitempty"`
+}
+
+// BalanceSnapshot is a character's wallet balance at a point in time
+type BalanceSnapshot struct {
+	Time    time.Time `json:"Time"`
+	Balance float64   `json:"Balance"`
+}
+
+// WalletData is everything persisted about wallets, keyed by character ID
+type WalletData struct {
+	History  map[int64][]BalanceSnapshot    `json:"History"`
+	Journals map[int64][]WalletJournalEntry `json:"Journals"`
+}
+
+// AccountWallet is the ISK held by the characters of an account
+type AccountWallet struct {
+	AccountID   int64   `json:"AccountID"`
+	AccountName string  `json:"AccountName"`
+	Balance     float64 `json:"Balance"`
+	Characters  int     `json:"Characters"` // characters whose balance is known
+}
+
+// WalletSummary is the ISK across every account
+type WalletSummary struct {
+	Total    float64         `json:"Total"`
+	Accounts []AccountWallet `json:"Accounts"`
+}
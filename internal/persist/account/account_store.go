@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
+	"golang.org/x/oauth2"
+
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/persist"
 	"github.com/guarzo/canifly/internal/services/interfaces"
@@ -17,6 +20,7 @@ const (
 )
 
 var _ interfaces.AccountDataRepository = (*AccountDataStore)(nil)
+var _ interfaces.TokenRepository = (*AccountDataStore)(nil)
 
 type AccountDataStore struct {
 	logger     interfaces.Logger
@@ -91,6 +95,31 @@ func (as *AccountDataStore) SaveAccountData(data model.AccountData) error {
 	return as.saveAccountDataLocked(data)
 }
 
+// SaveRefreshedToken replaces the token of the character logged in with refreshToken. The accounts are
+// copied before the change since fetched account data shares them with the cache.
+func (as *AccountDataStore) SaveRefreshedToken(refreshToken string, token oauth2.Token) error {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	cached, err := as.fetchAccountDataLocked()
+	if err != nil {
+		return err
+	}
+	for i := range cached.Accounts {
+		for j := range cached.Accounts[i].Characters {
+			if cached.Accounts[i].Characters[j].Token.RefreshToken != refreshToken {
+				continue
+			}
+			data := *cached
+			data.Accounts = slices.Clone(cached.Accounts)
+			data.Accounts[i].Characters = slices.Clone(cached.Accounts[i].Characters)
+			data.Accounts[i].Characters[j].Token = token
+			return as.saveAccountDataLocked(data)
+		}
+	}
+	return fmt.Errorf("no character holds the refreshed token")
+}
+
 func (as *AccountDataStore) DeleteAccountData() error {
 	as.mu.Lock()
 	defer as.mu.Unlock()
@@ -4,12 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/persist"
 	"github.com/guarzo/canifly/internal/persist/account"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 // MockLogger that does nothing.
@@ -183,3 +185,66 @@ func TestAccountDataStore_DeleteAccounts(t *testing.T) {
 	assert.Empty(t, ad.Accounts)
 	assert.Len(t, ad.Associations, 1)
 }
+
+func TestAccountDataStore_PersistsExpiryDates(t *testing.T) {
+	logger := &MockLogger{}
+	basePath := t.TempDir()
+	fs := persist.OSFileSystem{}
+
+	omegaExpiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	mctExpiry := time.Date(2030, 2, 3, 4, 5, 6, 0, time.UTC)
+	ad := model.AccountData{
+		Accounts: []model.Account{{
+			Name:        "Acc",
+			OmegaExpiry: &omegaExpiry,
+			Characters: []model.CharacterIdentity{{
+				Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1}},
+				MCTExpiry: &mctExpiry,
+			}},
+		}},
+	}
+	assert.NoError(t, account.NewAccountDataStore(logger, fs, basePath).SaveAccountData(ad))
+
+	// A fresh store reads the dates back from disk
+	loaded, err := account.NewAccountDataStore(logger, fs, basePath).FetchAccountData()
+	assert.NoError(t, err)
+	if assert.Len(t, loaded.Accounts, 1) {
+		assert.True(t, omegaExpiry.Equal(*loaded.Accounts[0].OmegaExpiry))
+		assert.True(t, mctExpiry.Equal(*loaded.Accounts[0].Characters[0].MCTExpiry))
+	}
+}
+
+func TestAccountDataStore_SaveRefreshedToken(t *testing.T) {
+	logger := &MockLogger{}
+	basePath := t.TempDir()
+	fs := persist.OSFileSystem{}
+	store := account.NewAccountDataStore(logger, fs, basePath)
+
+	character := func(id int64, refreshToken string) model.CharacterIdentity {
+		return model.CharacterIdentity{
+			Token:     oauth2.Token{AccessToken: "access", RefreshToken: refreshToken},
+			Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: id}},
+		}
+	}
+	assert.NoError(t, store.SaveAccountData(model.AccountData{Accounts: []model.Account{
+		{Name: "Acc", Characters: []model.CharacterIdentity{character(1, "first"), character(2, "second")}},
+	}}))
+	before, err := store.FetchAccountData()
+	assert.NoError(t, err)
+
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	refreshed := oauth2.Token{AccessToken: "new", TokenType: "Bearer", RefreshToken: "rotated", Expiry: expiry}
+	assert.NoError(t, store.SaveRefreshedToken("second", refreshed))
+	assert.Error(t, store.SaveRefreshedToken("second", refreshed), "the old refresh token is gone")
+	assert.Equal(t, "second", before.Accounts[0].Characters[1].Token.RefreshToken, "fetched data is not changed")
+
+	loaded, err := account.NewAccountDataStore(logger, fs, basePath).FetchAccountData()
+	assert.NoError(t, err)
+	if assert.Len(t, loaded.Accounts[0].Characters, 2) {
+		assert.Equal(t, "first", loaded.Accounts[0].Characters[0].Token.RefreshToken)
+		token := loaded.Accounts[0].Characters[1].Token
+		assert.Equal(t, "rotated", token.RefreshToken)
+		assert.Equal(t, "new", token.AccessToken)
+		assert.True(t, expiry.Equal(token.Expiry))
+	}
+}
@@ -0,0 +1,70 @@
+package account
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+const walletFileName = "wallets.json"
+
+var _ interfaces.WalletRepository = (*WalletStore)(nil)
+
+// WalletStore persists balance history and journals in wallets.json
+type WalletStore struct {
+	logger   interfaces.Logger
+	fs       persist.FileSystem
+	basePath string
+	mut      sync.Mutex
+}
+
+func NewWalletStore(logger interfaces.Logger, fs persist.FileSystem, basePath string) *WalletStore {
+	return &WalletStore{
+		logger:   logger,
+		fs:       fs,
+		basePath: basePath,
+	}
+}
+
+func (s *WalletStore) FetchWalletData() (model.WalletData, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	data := model.WalletData{
+		History:  make(map[int64][]model.BalanceSnapshot),
+		Journals: make(map[int64][]model.WalletJournalEntry),
+	}
+	path := filepath.Join(s.basePath, walletFileName)
+	if _, err := s.fs.Stat(path); os.IsNotExist(err) {
+		return data, nil
+	} else if err != nil {
+		return data, fmt.Errorf("failed to stat wallet file: %w", err)
+	}
+
+	if err := persist.ReadJsonFromFile(s.fs, path, &data); err != nil {
+		return data, fmt.Errorf("failed to load wallet data: %w", err)
+	}
+	if data.History == nil {
+		data.History = make(map[int64][]model.BalanceSnapshot)
+	}
+	if data.Journals == nil {
+		data.Journals = make(map[int64][]model.WalletJournalEntry)
+	}
+	return data, nil
+}
+
+func (s *WalletStore) SaveWalletData(data model.WalletData) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	path := filepath.Join(s.basePath, walletFileName)
+	if err := persist.SaveJsonToFile(s.fs, path, data); err != nil {
+		return fmt.Errorf("failed to save wallet data: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"github.com/guarzo/canifly/internal/model"
@@ -47,10 +48,21 @@ func (s *AppStateStore) SetAppState(appState model.AppState) {
 }
 
 func (s *AppStateStore) SetAppStateLogin(isLoggedIn bool) error {
-	appState := s.GetAppState()
-	appState.LoggedIn = isLoggedIn
-	s.SetAppState(appState)
-	return s.SaveAppStateSnapshot(appState)
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.appState.LoggedIn = isLoggedIn
+	return s.SaveAppStateSnapshot(s.appState)
+}
+
+func (s *AppStateStore) UpdateEveProfiles(update func(profiles []model.EveProfile) []model.EveProfile) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if !s.appState.LoggedIn {
+		return nil
+	}
+	// readers of the state share the backing array of the profiles
+	s.appState.EveData.EveProfiles = update(slices.Clone(s.appState.EveData.EveProfiles))
+	return s.SaveAppStateSnapshot(s.appState)
 }
 
 func (s *AppStateStore) ClearAppState() {
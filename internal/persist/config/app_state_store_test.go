@@ -148,3 +148,28 @@ func TestAppStateStore_LoadError(t *testing.T) {
 	assert.Empty(t, st.EveData.EveProfiles)
 	assert.Empty(t, st.ConfigData.Roles)
 }
+
+func TestAppStateStore_UpdateEveProfiles(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	fs := persist.OSFileSystem{}
+	store := config.NewAppStateStore(logger, fs, basePath)
+
+	addProfile := func(profiles []model.EveProfile) []model.EveProfile {
+		return append(profiles, model.EveProfile{Profile: "settings_New"})
+	}
+	assert.NoError(t, store.UpdateEveProfiles(addProfile))
+	assert.Empty(t, store.GetAppState().EveData.EveProfiles, "nothing is loaded while logged out")
+
+	store.SetAppState(model.AppState{LoggedIn: true, EveData: model.EveData{EveProfiles: []model.EveProfile{{Profile: "settings_Default"}}}})
+	before := store.GetAppState()
+	assert.NoError(t, store.UpdateEveProfiles(func(profiles []model.EveProfile) []model.EveProfile {
+		profiles[0].Profile = "settings_Renamed"
+		return addProfile(profiles)
+	}))
+	assert.Equal(t, "settings_Default", before.EveData.EveProfiles[0].Profile, "earlier readers keep their profiles")
+
+	reloaded := config.NewAppStateStore(logger, fs, basePath).GetAppState()
+	assert.True(t, reloaded.LoggedIn)
+	assert.Equal(t, []model.EveProfile{{Profile: "settings_Renamed"}, {Profile: "settings_New"}}, reloaded.EveData.EveProfiles)
+}
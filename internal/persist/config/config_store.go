@@ -19,6 +19,11 @@ import (
 
 const (
 	configFileName = "config.json"
+
+	// settings directories end in the server they belong to, c_ccp_eve_online_tq_tranquility
+	// and c_ccp_eve_online_sisi_singularity
+	tqSettingsSuffix   = "_tq_tranquility"
+	sisiSettingsSuffix = "_sisi_singularity"
 )
 
 var _ interfaces.ConfigRepository = (*ConfigStore)(nil)
@@ -116,23 +121,15 @@ func (c *ConfigStore) SaveRoles(roles []string) error {
 // GetDefaultSettingsDir returns the default settings directory.
 // It uses os.UserHomeDir() normally, but if running under WSL it retrieves the Windows home directory
 // (converted to WSL format) and then constructs candidate directories.
-// It then checks for the existence of these candidates and returns the first one that exists,
-// or, if none exist, returns the first candidate.
+// It then checks for the existence of these candidates, Tranquility before Singularity, and returns
+// the first one that exists. If none exist it returns the most recently used directory found by
+// DiscoverSettingsDirs, such as one in a Proton prefix, and otherwise the first candidate.
 func (c *ConfigStore) GetDefaultSettingsDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	platform, homeDir, err := settingsHome()
 	if err != nil {
 		return "", err
 	}
 
-	platform := runtime.GOOS
-	if isWSL() {
-		platform = "wsl"
-		homeDir, err = getWindowsHomeInWSL()
-		if err != nil {
-			return "", err
-		}
-	}
-
 	var candidates []string
 	switch platform {
 	case "windows":
@@ -157,6 +154,11 @@ func (c *ConfigStore) GetDefaultSettingsDir() (string, error) {
 	default:
 		return "", fmt.Errorf("unsupported platform: %s", platform)
 	}
+	// Singularity settings sit next to Tranquility's and are only used when there are none for Tranquility
+	tqCandidates := candidates
+	for _, dir := range tqCandidates {
+		candidates = append(candidates, strings.Replace(dir, tqSettingsSuffix, sisiSettingsSuffix, 1))
+	}
 
 	for _, dir := range candidates {
 		if info, err := os.Stat(dir); err == nil && info.IsDir() {
@@ -164,6 +166,10 @@ func (c *ConfigStore) GetDefaultSettingsDir() (string, error) {
 		}
 	}
 
+	if discovered := DiscoverSettingsDirsIn(platform, homeDir); len(discovered) > 0 {
+		return discovered[0].Path, nil
+	}
+
 	// If none of the candidate directories exist, return the first candidate (even if it doesn't exist)
 	return candidates[0], nil
 }
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+const notificationFileName = "notifications.json"
+
+var _ interfaces.NotificationRepository = (*NotificationStore)(nil)
+
+// NotificationStore persists alerts and notification settings in notifications.json
+type NotificationStore struct {
+	logger   interfaces.Logger
+	fs       persist.FileSystem
+	basePath string
+	mut      sync.Mutex
+}
+
+func NewNotificationStore(logger interfaces.Logger, fs persist.FileSystem, basePath string) *NotificationStore {
+	return &NotificationStore{
+		logger:   logger,
+		fs:       fs,
+		basePath: basePath,
+	}
+}
+
+func (s *NotificationStore) FetchNotificationState() (model.NotificationState, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var state model.NotificationState
+	path := filepath.Join(s.basePath, notificationFileName)
+	if _, err := s.fs.Stat(path); os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return state, fmt.Errorf("failed to stat notification file: %w", err)
+	}
+
+	if err := persist.ReadJsonFromFile(s.fs, path, &state); err != nil {
+		return state, fmt.Errorf("failed to load notification state: %w", err)
+	}
+	return state, nil
+}
+
+func (s *NotificationStore) SaveNotificationState(state model.NotificationState) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	path := filepath.Join(s.basePath, notificationFileName)
+	if err := persist.SaveJsonToFile(s.fs, path, state); err != nil {
+		return fmt.Errorf("failed to save notification state: %w", err)
+	}
+	return nil
+}
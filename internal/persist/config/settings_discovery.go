@@ -0,0 +1,234 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// eveSteamAppID is EVE Online on Steam, Proton keeps its Wine prefix under compatdata/<app id>
+const eveSteamAppID = "8500"
+
+// Sources of discovered settings directories
+const (
+	SourceNative = "native"
+	SourceSteam  = "steam"
+	SourceWine   = "wine"
+)
+
+// steamRoots are where Steam installs itself, native and as a Flatpak
+var steamRoots = [][]string{
+	{".steam", "steam"},
+	{".steam", "root"},
+	{".local", "share", "Steam"},
+	{".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"},
+	{".var", "app", "com.valvesoftware.Steam", "data", "Steam"},
+}
+
+// winePrefixGlobs match Wine prefixes made by hand, by Lutris (~/Games/<game>) and by Bottles
+var winePrefixGlobs = [][]string{
+	{".wine"},
+	{"Games", "*"},
+	{".local", "share", "wineprefixes", "*"},
+	{".local", "share", "bottles", "bottles", "*"},
+	{".var", "app", "com.usebottles.bottles", "data", "bottles", "bottles", "*"},
+}
+
+var libraryPathPattern = regexp.MustCompile(`"path"\s+"([^"]+)"`)
+
+// DiscoverSettingsDirs finds every Tranquility and Singularity settings directory of the user,
+// on Linux also inside Steam Proton and Wine prefixes. The most recently used comes first.
+func (c *ConfigStore) DiscoverSettingsDirs() ([]model.SettingsDirCandidate, error) {
+	platform, homeDir, err := settingsHome()
+	if err != nil {
+		return nil, err
+	}
+	return DiscoverSettingsDirsIn(platform, homeDir), nil
+}
+
+// DiscoverSettingsDirsIn is DiscoverSettingsDirs for a platform as reported by runtime.GOOS, or
+// "wsl", and a home directory.
+func DiscoverSettingsDirsIn(platform, homeDir string) []model.SettingsDirCandidate {
+	type eveDir struct {
+		path   string
+		source string
+	}
+	var eveDirs []eveDir
+
+	switch platform {
+	case "windows", "wsl":
+		eveDirs = append(eveDirs, eveDir{filepath.Join(homeDir, "AppData", "Local", "CCP", "EVE"), SourceNative})
+	case "darwin":
+		eveDirs = append(eveDirs, eveDir{filepath.Join(homeDir, "Library", "Application Support", "CCP", "EVE"), SourceNative})
+	case "linux":
+		eveDirs = append(eveDirs, eveDir{filepath.Join(homeDir, ".local", "share", "CCP", "EVE"), SourceNative})
+		for _, library := range steamLibraries(homeDir) {
+			prefix := filepath.Join(library, "steamapps", "compatdata", eveSteamAppID, "pfx")
+			for _, dir := range prefixEveDirs(prefix) {
+				eveDirs = append(eveDirs, eveDir{dir, SourceSteam})
+			}
+		}
+		for _, pattern := range winePrefixGlobs {
+			prefixes, _ := filepath.Glob(filepath.Join(append([]string{homeDir}, pattern...)...))
+			for _, prefix := range prefixes {
+				for _, dir := range prefixEveDirs(prefix) {
+					eveDirs = append(eveDirs, eveDir{dir, SourceWine})
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	candidates := make([]model.SettingsDirCandidate, 0)
+	for _, eve := range eveDirs {
+		entries, err := os.ReadDir(eve.path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			server := settingsDirServer(entry.Name())
+			if !entry.IsDir() || server == "" {
+				continue
+			}
+			path := filepath.Join(eve.path, entry.Name())
+			// ~/.steam/steam usually links to ~/.local/share/Steam
+			key := path
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				key = resolved
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			candidate := model.SettingsDirCandidate{Path: path, Source: eve.source, Server: server}
+			candidate.Profiles, candidate.LastModified = scanSettingsDir(path)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].LastModified, candidates[j].LastModified
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && !a.Equal(*b) {
+			return a.After(*b)
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+	return candidates
+}
+
+// settingsDirServer returns the server a settings directory such as c_ccp_eve_online_tq_tranquility
+// belongs to, empty for other directories.
+func settingsDirServer(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_tranquility"):
+		return model.ServerTranquility
+	case strings.HasSuffix(name, "_singularity"):
+		return model.ServerSingularity
+	}
+	return ""
+}
+
+// steamLibraries returns the Steam install directories and every library listed in their libraryfolders.vdf.
+func steamLibraries(homeDir string) []string {
+	var libraries []string
+	for _, root := range steamRoots {
+		dir := filepath.Join(append([]string{homeDir}, root...)...)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		libraries = append(libraries, dir)
+		for _, vdf := range []string{
+			filepath.Join(dir, "steamapps", "libraryfolders.vdf"),
+			filepath.Join(dir, "config", "libraryfolders.vdf"),
+		} {
+			data, err := os.ReadFile(vdf)
+			if err != nil {
+				continue
+			}
+			for _, match := range libraryPathPattern.FindAllStringSubmatch(string(data), -1) {
+				libraries = append(libraries, match[1])
+			}
+		}
+	}
+	return libraries
+}
+
+// prefixEveDirs returns the CCP/EVE directories of every user in a Wine prefix.
+func prefixEveDirs(prefix string) []string {
+	var dirs []string
+	for _, pattern := range []string{
+		filepath.Join(prefix, "drive_c", "users", "*", "AppData", "Local", "CCP", "EVE"),
+		// prefixes set up as Windows XP
+		filepath.Join(prefix, "drive_c", "users", "*", "Local Settings", "Application Data", "CCP", "EVE"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		dirs = append(dirs, matches...)
+	}
+	return dirs
+}
+
+// scanSettingsDir counts the settings_ profiles of a directory and finds the newest char or user
+// file in them, the client writes both when a character logs out.
+func scanSettingsDir(dir string) (int, *time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, nil
+	}
+
+	profiles := 0
+	var newest *time.Time
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "settings_") {
+			continue
+		}
+		profiles++
+
+		files, err := os.ReadDir(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name := file.Name()
+			if !strings.HasPrefix(name, "core_char_") && !strings.HasPrefix(name, "core_user_") {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			if mtime := info.ModTime(); newest == nil || mtime.After(*newest) {
+				newest = &mtime
+			}
+		}
+	}
+	return profiles, newest
+}
+
+// settingsHome returns the platform and the home directory holding the EVE settings, which is
+// the Windows home under WSL.
+func settingsHome() (string, string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	platform := runtime.GOOS
+	if isWSL() {
+		platform = "wsl"
+		homeDir, err = getWindowsHomeInWSL()
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return platform, homeDir, nil
+}
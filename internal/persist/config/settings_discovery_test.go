@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist/config"
+)
+
+// makeSettingsDir creates a settings directory with one profile holding a char file last written at mtime.
+func makeSettingsDir(t *testing.T, dir string, mtime time.Time) {
+	profile := filepath.Join(dir, "settings_Default")
+	require.NoError(t, os.MkdirAll(profile, 0755))
+	file := filepath.Join(profile, "core_char_90000001.dat")
+	require.NoError(t, os.WriteFile(file, []byte("char"), 0644))
+	require.NoError(t, os.Chtimes(file, mtime, mtime))
+}
+
+func TestDiscoverSettingsDirsIn_Linux(t *testing.T) {
+	home := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+
+	native := filepath.Join(home, ".local", "share", "CCP", "EVE", "c_ccp_eve_online_tq_tranquility")
+	makeSettingsDir(t, native, now.Add(-48*time.Hour))
+
+	// Steam itself, reachable twice through the ~/.steam/steam link, with a second library
+	steam := filepath.Join(home, ".local", "share", "Steam")
+	library := filepath.Join(home, "games", "SteamLibrary")
+	require.NoError(t, os.MkdirAll(filepath.Join(steam, "steamapps"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".steam"), 0755))
+	require.NoError(t, os.Symlink(steam, filepath.Join(home, ".steam", "steam")))
+	vdf := `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"` + steam + `"
+	}
+	"1"
+	{
+		"path"		"` + library + `"
+	}
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(steam, "steamapps", "libraryfolders.vdf"), []byte(vdf), 0644))
+	proton := filepath.Join(library, "steamapps", "compatdata", "8500", "pfx", "drive_c", "users", "steamuser",
+		"AppData", "Local", "CCP", "EVE", "c_ccp_eve_tq_tranquility")
+	makeSettingsDir(t, proton, now.Add(-time.Hour))
+
+	// Lutris prefix with a Singularity install and no settings yet
+	lutris := filepath.Join(home, "Games", "eve-online", "drive_c", "users", "pilot", "AppData", "Local", "CCP", "EVE")
+	require.NoError(t, os.MkdirAll(filepath.Join(lutris, "c_ccp_eve_sisi_singularity"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(lutris, "not_a_server"), 0755))
+
+	candidates := config.DiscoverSettingsDirsIn("linux", home)
+	require.Len(t, candidates, 3)
+
+	assert.Equal(t, proton, candidates[0].Path)
+	assert.Equal(t, config.SourceSteam, candidates[0].Source)
+	assert.Equal(t, model.ServerTranquility, candidates[0].Server)
+	assert.Equal(t, 1, candidates[0].Profiles)
+	require.NotNil(t, candidates[0].LastModified)
+	assert.True(t, now.Add(-time.Hour).Equal(*candidates[0].LastModified))
+
+	assert.Equal(t, native, candidates[1].Path)
+	assert.Equal(t, config.SourceNative, candidates[1].Source)
+
+	assert.Equal(t, filepath.Join(lutris, "c_ccp_eve_sisi_singularity"), candidates[2].Path)
+	assert.Equal(t, config.SourceWine, candidates[2].Source)
+	assert.Equal(t, model.ServerSingularity, candidates[2].Server)
+	assert.Nil(t, candidates[2].LastModified)
+}
+
+func TestDiscoverSettingsDirsIn_NothingFound(t *testing.T) {
+	assert.Empty(t, config.DiscoverSettingsDirsIn("linux", t.TempDir()))
+	assert.Empty(t, config.DiscoverSettingsDirsIn("plan9", t.TempDir()))
+}
@@ -0,0 +1,64 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+const assetFileName = "assets.json"
+
+var _ interfaces.AssetRepository = (*AssetStore)(nil)
+
+// AssetStore persists the asset index of every character in assets.json
+type AssetStore struct {
+	logger   interfaces.Logger
+	fs       persist.FileSystem
+	basePath string
+	mut      sync.Mutex
+}
+
+func NewAssetStore(logger interfaces.Logger, fs persist.FileSystem, basePath string) *AssetStore {
+	return &AssetStore{
+		logger:   logger,
+		fs:       fs,
+		basePath: basePath,
+	}
+}
+
+func (s *AssetStore) FetchAssetIndex() (model.AssetIndex, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	index := model.AssetIndex{Characters: make(map[int64]model.CharacterAssets)}
+	path := filepath.Join(s.basePath, assetFileName)
+	if _, err := s.fs.Stat(path); os.IsNotExist(err) {
+		return index, nil
+	} else if err != nil {
+		return index, fmt.Errorf("failed to stat asset file: %w", err)
+	}
+
+	if err := persist.ReadJsonFromFile(s.fs, path, &index); err != nil {
+		return index, fmt.Errorf("failed to load asset index: %w", err)
+	}
+	if index.Characters == nil {
+		index.Characters = make(map[int64]model.CharacterAssets)
+	}
+	return index, nil
+}
+
+func (s *AssetStore) SaveAssetIndex(index model.AssetIndex) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	path := filepath.Join(s.basePath, assetFileName)
+	if err := persist.SaveJsonToFile(s.fs, path, index); err != nil {
+		return fmt.Errorf("failed to save asset index: %w", err)
+	}
+	return nil
+}
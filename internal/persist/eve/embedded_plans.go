@@ -0,0 +1,271 @@
+package eve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/guarzo/canifly/internal/embed"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+)
+
+// embeddedManifestFileName records, per embedded plan, the release last installed into the plans
+// directory. It is the common base when merging a new release with local edits.
+const embeddedManifestFileName = "embedded_plans.json"
+
+// embeddedPlanReleasesFile lists every release of each embedded plan that has shipped, the current one
+// included. Installs from before the manifest have no base, a local copy matching one of these releases
+// was never edited. Append the new skills of a plan here whenever its file changes.
+const embeddedPlanReleasesFile = "static/embedded_plan_releases.json"
+
+// upgradeEmbeddedPlan installs or upgrades a single embedded plan. Plans the user never edited are
+// replaced, edited plans are merged with the new release and a conflict is returned when that fails.
+// releases are the shipped releases of the plan, used as the base when the manifest has none.
+func (s *SkillStore) upgradeEmbeddedPlan(planName, destPath string, manifest map[string]map[string]model.Skill, releases []map[string]model.Skill) (*model.EmbeddedPlanConflict, error) {
+	srcPath := "static/plans/" + planName + ".txt"
+	upstream, err := readEmbeddedSkills(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.fs.Stat(destPath); os.IsNotExist(err) {
+		if err := s.copyEmbeddedFile(srcPath, destPath); err != nil {
+			return nil, err
+		}
+		manifest[planName] = upstream
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", destPath, err)
+	}
+
+	base, tracked := manifest[planName]
+	if tracked && sameSkills(base, upstream) {
+		// Nothing changed in this release
+		return nil, nil
+	}
+
+	local, err := s.readSkillsFromFile(destPath)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked {
+		for _, release := range releases {
+			if sameSkills(local, release) {
+				base, tracked = release, true
+				break
+			}
+		}
+	}
+	switch {
+	case sameSkills(local, upstream):
+		manifest[planName] = upstream
+		return nil, nil
+	case tracked && sameSkills(local, base):
+		s.logger.Infof("Updating unedited embedded plan %s to the new release", planName)
+		data, err := embed.StaticFiles.ReadFile(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded file %s: %w", srcPath, err)
+		}
+		if err := s.writePlanFile(planName, destPath, data, local, upstream, "Updated to the new embedded release"); err != nil {
+			return nil, err
+		}
+		manifest[planName] = upstream
+		return nil, nil
+	}
+
+	// The user edited the plan (or it predates tracking and matches no release, so the base is unknown)
+	merged, conflicts := mergeSkills(base, local, upstream)
+	if len(conflicts) > 0 {
+		s.logger.Warnf("Embedded plan %s has %d conflicts with local edits", planName, len(conflicts))
+		return &model.EmbeddedPlanConflict{
+			PlanName:  planName,
+			Local:     local,
+			Upstream:  upstream,
+			Merged:    merged,
+			Conflicts: conflicts,
+		}, nil
+	}
+
+	s.logger.Infof("Merged new release of embedded plan %s with local edits", planName)
+	if err := s.writePlanFile(planName, destPath, formatSkillPlan(merged), local, merged, "Merged the new embedded release with local edits"); err != nil {
+		return nil, err
+	}
+	manifest[planName] = upstream
+	return nil, nil
+}
+
+// GetEmbeddedPlanConflicts returns the embedded plans whose new release could not be merged automatically.
+func (s *SkillStore) GetEmbeddedPlanConflicts() []model.EmbeddedPlanConflict {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	conflicts := make([]model.EmbeddedPlanConflict, 0, len(s.planConflicts))
+	for _, c := range s.planConflicts {
+		conflicts = append(conflicts, c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].PlanName < conflicts[j].PlanName })
+	return conflicts
+}
+
+// ResolveEmbeddedPlanConflict applies the chosen resolution and records the new release as the merge base.
+func (s *SkillStore) ResolveEmbeddedPlanConflict(planName, resolution string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	conflict, ok := s.planConflicts[planName]
+	if !ok {
+		return fmt.Errorf("no pending conflict for plan %s", planName)
+	}
+
+	var skills map[string]model.Skill
+	switch resolution {
+	case model.ResolveKeepLocal:
+		skills = conflict.Local
+	case model.ResolveUseUpstream:
+		skills = conflict.Upstream
+	case model.ResolveUseMerged:
+		skills = conflict.Merged
+	default:
+		return fmt.Errorf("unknown resolution %q", resolution)
+	}
+
+	if resolution != model.ResolveKeepLocal {
+		planFilePath := filepath.Join(s.basePath, plansDir, planName+".txt")
+		note := fmt.Sprintf("Applied embedded plan update (%s)", resolution)
+		if err := s.writePlanFile(planName, planFilePath, formatSkillPlan(skills), conflict.Local, skills, note); err != nil {
+			return err
+		}
+		s.skillPlans[planName] = model.SkillPlan{Name: planName, Skills: skills}
+	}
+
+	manifest, err := s.loadEmbeddedPlanManifest()
+	if err != nil {
+		return err
+	}
+	manifest[planName] = conflict.Upstream
+	if err := s.saveEmbeddedPlanManifest(manifest); err != nil {
+		return err
+	}
+
+	delete(s.planConflicts, planName)
+	s.logger.Infof("Resolved embedded plan conflict for %s using %s", planName, resolution)
+	return nil
+}
+
+// mergeSkills performs a three-way merge of skill levels. Where local and upstream both changed a
+// skill differently the local level is kept and the skill is reported as a conflict.
+func mergeSkills(base, local, upstream map[string]model.Skill) (map[string]model.Skill, []model.SkillMergeConflict) {
+	names := make(map[string]struct{})
+	for _, m := range []map[string]model.Skill{base, local, upstream} {
+		for name := range m {
+			names[name] = struct{}{}
+		}
+	}
+
+	merged := make(map[string]model.Skill)
+	var conflicts []model.SkillMergeConflict
+	for name := range names {
+		b, l, u := base[name].Level, local[name].Level, upstream[name].Level
+
+		level := l
+		switch {
+		case l == b:
+			level = u
+		case u == b, u == l:
+			level = l
+		default:
+			conflicts = append(conflicts, model.SkillMergeConflict{Name: name, BaseLevel: b, LocalLevel: l, UpstreamLevel: u})
+		}
+
+		if level > 0 {
+			merged[name] = model.Skill{Name: name, Level: level}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+	return merged, conflicts
+}
+
+// sameSkills reports whether both plans contain the same skills at the same levels.
+func sameSkills(a, b map[string]model.Skill) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, skill := range a {
+		if other, ok := b[name]; !ok || other.Level != skill.Level {
+			return false
+		}
+	}
+	return true
+}
+
+func readEmbeddedSkills(srcPath string) (map[string]model.Skill, error) {
+	srcFile, err := embed.StaticFiles.Open(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded file %s: %w", srcPath, err)
+	}
+	defer srcFile.Close()
+
+	data, err := io.ReadAll(srcFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded file %s: %w", srcPath, err)
+	}
+	return parseSkillLines(data, srcPath)
+}
+
+// readEmbeddedPlanReleases reads the shipped releases of the embedded plans, skill levels by skill name.
+func readEmbeddedPlanReleases() (map[string][]map[string]model.Skill, error) {
+	data, err := embed.StaticFiles.ReadFile(embeddedPlanReleasesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded plan releases: %w", err)
+	}
+	var levels map[string][]map[string]int
+	if err := json.Unmarshal(data, &levels); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded plan releases: %w", err)
+	}
+
+	releases := make(map[string][]map[string]model.Skill, len(levels))
+	for planName, planReleases := range levels {
+		for _, release := range planReleases {
+			skills := make(map[string]model.Skill, len(release))
+			for name, level := range release {
+				skills[name] = model.Skill{Name: name, Level: level}
+			}
+			releases[planName] = append(releases[planName], skills)
+		}
+	}
+	return releases, nil
+}
+
+// SetEmbeddedPlanReleasesForTest replaces the shipped releases the embedded plans are matched against.
+func (s *SkillStore) SetEmbeddedPlanReleasesForTest(releases map[string][]map[string]model.Skill) {
+	s.planReleases = releases
+}
+
+func (s *SkillStore) loadEmbeddedPlanManifest() (map[string]map[string]model.Skill, error) {
+	manifest := make(map[string]map[string]model.Skill)
+	manifestPath := filepath.Join(s.basePath, plansDir, embeddedManifestFileName)
+
+	if _, err := s.fs.Stat(manifestPath); os.IsNotExist(err) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat embedded plan manifest: %w", err)
+	}
+
+	if err := persist.ReadJsonFromFile(s.fs, manifestPath, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to load embedded plan manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func (s *SkillStore) saveEmbeddedPlanManifest(manifest map[string]map[string]model.Skill) error {
+	manifestPath := filepath.Join(s.basePath, plansDir, embeddedManifestFileName)
+	if err := persist.SaveJsonToFile(s.fs, manifestPath, manifest); err != nil {
+		return fmt.Errorf("failed to save embedded plan manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package eve_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/embed"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/persist/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+const testEmbeddedPlan = "Kiki"
+
+// setupOldRelease installs the embedded plans, then rewrites the manifest and local copy of the
+// test plan as if an older release with baseSkills had been installed and edited to localSkills.
+func setupOldRelease(t *testing.T, baseSkills, localSkills func(upstream map[string]model.Skill) map[string]model.Skill) (*eve.SkillStore, map[string]model.Skill) {
+	t.Helper()
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+	store := eve.NewSkillStore(&testutil.MockLogger{}, fs, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	upstream := copySkills(store.GetSkillPlans()[testEmbeddedPlan].Skills)
+	require.NotEmpty(t, upstream)
+
+	manifestPath := filepath.Join(basePath, "plans", "embedded_plans.json")
+	var manifest map[string]map[string]model.Skill
+	require.NoError(t, persist.ReadJsonFromFile(fs, manifestPath, &manifest))
+	manifest[testEmbeddedPlan] = baseSkills(upstream)
+	require.NoError(t, persist.SaveJsonToFile(fs, manifestPath, manifest))
+
+	require.NoError(t, store.UpdateSkillPlan(testEmbeddedPlan, localSkills(upstream), ""))
+	return store, upstream
+}
+
+// lastRevisions returns the last two revisions in the history of the test plan.
+func lastRevisions(t *testing.T, store *eve.SkillStore) (model.SkillPlanRevision, model.SkillPlanRevision) {
+	t.Helper()
+	revisions, err := store.History().GetRevisions(testEmbeddedPlan)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(revisions), 2)
+	return revisions[len(revisions)-2], revisions[len(revisions)-1]
+}
+
+func copySkills(skills map[string]model.Skill) map[string]model.Skill {
+	cpy := make(map[string]model.Skill, len(skills))
+	for k, v := range skills {
+		cpy[k] = v
+	}
+	return cpy
+}
+
+func TestUpgradeEmbeddedPlan_UneditedPlanIsUpdated(t *testing.T) {
+	oldRelease := func(upstream map[string]model.Skill) map[string]model.Skill {
+		old := copySkills(upstream)
+		old["Removed Upstream Skill"] = model.Skill{Name: "Removed Upstream Skill", Level: 1}
+		return old
+	}
+	store, upstream := setupOldRelease(t, oldRelease, oldRelease)
+
+	require.NoError(t, store.LoadSkillPlans())
+	assert.Equal(t, upstream, store.GetSkillPlans()[testEmbeddedPlan].Skills)
+	assert.Empty(t, store.GetEmbeddedPlanConflicts())
+
+	// the replaced copy can be rolled back to
+	before, after := lastRevisions(t, store)
+	assert.Equal(t, oldRelease(upstream), before.Skills)
+	assert.Equal(t, upstream, after.Skills)
+	assert.Equal(t, "Updated to the new embedded release", after.Note)
+}
+
+func TestUpgradeEmbeddedPlan_EditedPlanIsMerged(t *testing.T) {
+	oldRelease := func(upstream map[string]model.Skill) map[string]model.Skill {
+		old := copySkills(upstream)
+		old["Removed Upstream Skill"] = model.Skill{Name: "Removed Upstream Skill", Level: 1}
+		return old
+	}
+	edited := func(upstream map[string]model.Skill) map[string]model.Skill {
+		local := oldRelease(upstream)
+		local["Local Only Skill"] = model.Skill{Name: "Local Only Skill", Level: 3}
+		return local
+	}
+	store, upstream := setupOldRelease(t, oldRelease, edited)
+
+	require.NoError(t, store.LoadSkillPlans())
+	skills := store.GetSkillPlans()[testEmbeddedPlan].Skills
+	assert.NotContains(t, skills, "Removed Upstream Skill", "upstream removal should be applied")
+	assert.Equal(t, 3, skills["Local Only Skill"].Level, "local addition should be kept")
+	assert.Len(t, skills, len(upstream)+1)
+	assert.Empty(t, store.GetEmbeddedPlanConflicts())
+
+	before, after := lastRevisions(t, store)
+	assert.Equal(t, edited(upstream), before.Skills)
+	assert.Equal(t, skills, after.Skills)
+}
+
+func TestUpgradeEmbeddedPlan_ConflictIsReportedAndResolved(t *testing.T) {
+	var conflicted string
+	oldRelease := func(upstream map[string]model.Skill) map[string]model.Skill {
+		old := copySkills(upstream)
+		if conflicted == "" {
+			for name := range old {
+				conflicted = name
+				break
+			}
+		}
+		delete(old, conflicted)
+		return old
+	}
+	edited := func(upstream map[string]model.Skill) map[string]model.Skill {
+		local := oldRelease(upstream)
+		level := upstream[conflicted].Level%5 + 1
+		local[conflicted] = model.Skill{Name: conflicted, Level: level}
+		return local
+	}
+	store, upstream := setupOldRelease(t, oldRelease, edited)
+
+	require.NoError(t, store.LoadSkillPlans())
+	conflicts := store.GetEmbeddedPlanConflicts()
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, testEmbeddedPlan, conflicts[0].PlanName)
+	require.Len(t, conflicts[0].Conflicts, 1)
+	assert.Equal(t, conflicted, conflicts[0].Conflicts[0].Name)
+	assert.Equal(t, upstream[conflicted].Level, conflicts[0].Conflicts[0].UpstreamLevel)
+
+	// The local file is left untouched until the user resolves the conflict
+	assert.NotEqual(t, upstream[conflicted].Level, store.GetSkillPlans()[testEmbeddedPlan].Skills[conflicted].Level)
+
+	require.NoError(t, store.ResolveEmbeddedPlanConflict(testEmbeddedPlan, model.ResolveUseUpstream))
+	assert.Equal(t, upstream, store.GetSkillPlans()[testEmbeddedPlan].Skills)
+	assert.Empty(t, store.GetEmbeddedPlanConflicts())
+	before, after := lastRevisions(t, store)
+	assert.Equal(t, edited(upstream), before.Skills)
+	assert.Equal(t, "Applied embedded plan update (upstream)", after.Note)
+
+	// Reloading should not report the conflict again
+	require.NoError(t, store.LoadSkillPlans())
+	assert.Empty(t, store.GetEmbeddedPlanConflicts())
+}
+
+// setupUntrackedInstall installs the embedded plans, then removes the manifest and writes localSkills as
+// the local copy of the test plan, like an install from before the manifest existed.
+func setupUntrackedInstall(t *testing.T, localSkills func(upstream map[string]model.Skill) map[string]model.Skill) (*eve.SkillStore, map[string]model.Skill, string) {
+	t.Helper()
+	basePath := t.TempDir()
+	store := eve.NewSkillStore(&testutil.MockLogger{}, persist.OSFileSystem{}, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+	upstream := copySkills(store.GetSkillPlans()[testEmbeddedPlan].Skills)
+
+	require.NoError(t, os.Remove(filepath.Join(basePath, "plans", "embedded_plans.json")))
+	var sb strings.Builder
+	for name, skill := range localSkills(upstream) {
+		fmt.Fprintf(&sb, "%s %d\n", name, skill.Level)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "plans", testEmbeddedPlan+".txt"), []byte(sb.String()), 0644))
+	return store, upstream, basePath
+}
+
+func olderRelease(upstream map[string]model.Skill) map[string]model.Skill {
+	old := copySkills(upstream)
+	old["Removed Upstream Skill"] = model.Skill{Name: "Removed Upstream Skill", Level: 1}
+	return old
+}
+
+func TestUpgradeEmbeddedPlan_NoManifestUpgradesShippedRelease(t *testing.T) {
+	store, upstream, basePath := setupUntrackedInstall(t, olderRelease)
+	store.SetEmbeddedPlanReleasesForTest(map[string][]map[string]model.Skill{
+		testEmbeddedPlan: {olderRelease(upstream), upstream},
+	})
+
+	require.NoError(t, store.LoadSkillPlans())
+	assert.Equal(t, upstream, store.GetSkillPlans()[testEmbeddedPlan].Skills)
+	assert.Empty(t, store.GetEmbeddedPlanConflicts())
+
+	var manifest map[string]map[string]model.Skill
+	require.NoError(t, persist.ReadJsonFromFile(persist.OSFileSystem{}, filepath.Join(basePath, "plans", "embedded_plans.json"), &manifest))
+	assert.Equal(t, upstream, manifest[testEmbeddedPlan])
+}
+
+func TestUpgradeEmbeddedPlan_NoManifestKeepsUnknownEdits(t *testing.T) {
+	var changed string
+	edited := func(upstream map[string]model.Skill) map[string]model.Skill {
+		local := olderRelease(upstream)
+		for name, skill := range upstream {
+			changed = name
+			local[name] = model.Skill{Name: name, Level: skill.Level%5 + 1}
+			break
+		}
+		return local
+	}
+	store, upstream, _ := setupUntrackedInstall(t, edited)
+	store.SetEmbeddedPlanReleasesForTest(map[string][]map[string]model.Skill{
+		testEmbeddedPlan: {olderRelease(upstream), upstream},
+	})
+
+	// the copy matches no release, so the level change cannot be told from an edit
+	require.NoError(t, store.LoadSkillPlans())
+	conflicts := store.GetEmbeddedPlanConflicts()
+	require.Len(t, conflicts, 1)
+	require.Len(t, conflicts[0].Conflicts, 1)
+	assert.Equal(t, changed, conflicts[0].Conflicts[0].Name)
+}
+
+func TestEmbeddedPlanReleases_IncludeCurrentPlans(t *testing.T) {
+	data, err := embed.StaticFiles.ReadFile("static/embedded_plan_releases.json")
+	require.NoError(t, err)
+	var releases map[string][]map[string]int
+	require.NoError(t, json.Unmarshal(data, &releases))
+
+	store := eve.NewSkillStore(&testutil.MockLogger{}, persist.OSFileSystem{}, t.TempDir())
+	require.NoError(t, store.LoadSkillPlans())
+	entries, err := embed.StaticFiles.ReadDir("static/plans")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		planName := strings.TrimSuffix(entry.Name(), ".txt")
+		current := make(map[string]int)
+		for name, skill := range store.GetSkillPlans()[planName].Skills {
+			current[name] = skill.Level
+		}
+		assert.Contains(t, releases[planName], current, "add the new release of %s to embedded_plan_releases.json", planName)
+	}
+}
@@ -258,6 +258,50 @@ func (e *EveProfilesStore) SyncAllSubdirectories(baseSubDir, userId, charId, set
 	return totalUserCopied, totalCharCopied, nil
 }
 
+// SyncToSettingsDir copies the user and char files of baseSubDir to every settings_ subdirectory of another
+// settings directory. Unlike syncing within a directory, files with the same names are overwritten too, they
+// belong to another install.
+func (e *EveProfilesStore) SyncToSettingsDir(baseSubDir, userId, charId, settingsDir, targetSettingsDir string) (int, int, error) {
+	e.logger.Infof("Starting SyncToSettingsDir with baseSubDir=%s, userId=%s, charId=%s, from %s to %s", baseSubDir, userId, charId, settingsDir, targetSettingsDir)
+	if filepath.Clean(settingsDir) == filepath.Clean(targetSettingsDir) {
+		return 0, 0, fmt.Errorf("source and target settings directories are the same: %s", settingsDir)
+	}
+
+	baseSubDirPath := filepath.Join(settingsDir, baseSubDir)
+	userFilePath := filepath.Join(baseSubDirPath, "core_user_"+userId+".dat")
+	charFilePath := filepath.Join(baseSubDirPath, "core_char_"+charId+".dat")
+
+	userContent, err := os.ReadFile(userFilePath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read user file %s: %v", userFilePath, err)
+	}
+	charContent, err := os.ReadFile(charFilePath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read char file %s: %v", charFilePath, err)
+	}
+
+	subDirs, err := e.GetSubDirectories(targetSettingsDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get subdirectories: %v", err)
+	}
+
+	totalUserCopied := 0
+	totalCharCopied := 0
+	for _, subDir := range subDirs {
+		// no file names to skip, every user and char file takes the new content
+		uCopied, cCopied, err := e.applyContentToSubDir(filepath.Join(targetSettingsDir, subDir), "", "", userContent, charContent)
+		if err != nil {
+			e.logger.Warnf("Error applying content to %s in %s: %v", subDir, targetSettingsDir, err)
+			continue
+		}
+		totalUserCopied += uCopied
+		totalCharCopied += cCopied
+	}
+
+	e.logger.Infof("SyncToSettingsDir complete: %d total user files, %d total char files copied.", totalUserCopied, totalCharCopied)
+	return totalUserCopied, totalCharCopied, nil
+}
+
 func (e *EveProfilesStore) applyContentToSubDir(
 	dirPath string,
 	userFileName string,
@@ -212,6 +212,41 @@ func TestEveProfilesStore_SyncAllSubdirectories(t *testing.T) {
 	assert.Equal(t, []byte("masterChar"), newCharData)
 }
 
+func TestEveProfilesStore_SyncToSettingsDir(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	store := eve.NewEveProfilesStore(logger)
+
+	tqDir := t.TempDir()
+	sisiDir := t.TempDir()
+
+	basePath := filepath.Join(tqDir, "settings_Default")
+	targetPath := filepath.Join(sisiDir, "settings_Default")
+	require.NoError(t, os.MkdirAll(basePath, 0755))
+	require.NoError(t, os.MkdirAll(targetPath, 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "core_user_999.dat"), []byte("masterUser"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "core_char_888.dat"), []byte("masterChar"), 0644))
+
+	// the same character on the other install takes the layout too
+	require.NoError(t, os.WriteFile(filepath.Join(targetPath, "core_user_999.dat"), []byte("oldUserData"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(targetPath, "core_char_888.dat"), []byte("oldCharData"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(targetPath, "core_char_666.dat"), []byte("oldCharData"), 0644))
+
+	userCopied, charCopied, err := store.SyncToSettingsDir("settings_Default", "999", "888", tqDir, sisiDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, userCopied)
+	assert.Equal(t, 2, charCopied)
+
+	for _, file := range []string{"core_char_888.dat", "core_char_666.dat"} {
+		data, err := os.ReadFile(filepath.Join(targetPath, file))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("masterChar"), data)
+	}
+
+	_, _, err = store.SyncToSettingsDir("settings_Default", "999", "888", tqDir, tqDir)
+	assert.Error(t, err)
+}
+
 func TestEveProfilesStore_SubdirNotExist(t *testing.T) {
 	logger := &testutil.MockLogger{}
 	store := eve.NewEveProfilesStore(logger)
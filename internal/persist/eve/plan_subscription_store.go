@@ -0,0 +1,120 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+const subscriptionFileName = "subscriptions.json"
+
+var _ interfaces.PlanSubscriptionRepository = (*PlanSubscriptionStore)(nil)
+
+// PlanSubscriptionStore keeps the configured remote plan sources in plans/subscriptions.json
+type PlanSubscriptionStore struct {
+	logger   interfaces.Logger
+	fs       persist.FileSystem
+	basePath string
+	mu       sync.Mutex
+}
+
+func NewPlanSubscriptionStore(logger interfaces.Logger, fs persist.FileSystem, basePath string) *PlanSubscriptionStore {
+	return &PlanSubscriptionStore{
+		logger:   logger,
+		fs:       fs,
+		basePath: basePath,
+	}
+}
+
+func (p *PlanSubscriptionStore) GetSubscriptions() ([]model.PlanSubscription, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.readSubscriptionsLocked()
+}
+
+// SaveSubscription creates or replaces the subscription with the same name.
+func (p *PlanSubscriptionStore) SaveSubscription(sub model.PlanSubscription) error {
+	if err := validateSourceName(sub.Name); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	subs, err := p.readSubscriptionsLocked()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range subs {
+		if subs[i].Name == sub.Name {
+			subs[i] = sub
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		subs = append(subs, sub)
+	}
+	return p.writeSubscriptionsLocked(subs)
+}
+
+func (p *PlanSubscriptionStore) DeleteSubscription(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	subs, err := p.readSubscriptionsLocked()
+	if err != nil {
+		return err
+	}
+
+	kept := subs[:0]
+	for _, sub := range subs {
+		if sub.Name != name {
+			kept = append(kept, sub)
+		}
+	}
+	if len(kept) == len(subs) {
+		return fmt.Errorf("subscription %s does not exist", name)
+	}
+	return p.writeSubscriptionsLocked(kept)
+}
+
+func (p *PlanSubscriptionStore) subscriptionPath() string {
+	return filepath.Join(p.basePath, plansDir, subscriptionFileName)
+}
+
+func (p *PlanSubscriptionStore) readSubscriptionsLocked() ([]model.PlanSubscription, error) {
+	path := p.subscriptionPath()
+	if _, err := p.fs.Stat(path); os.IsNotExist(err) {
+		return []model.PlanSubscription{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat subscription file: %w", err)
+	}
+
+	var subs []model.PlanSubscription
+	if err := persist.ReadJsonFromFile(p.fs, path, &subs); err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+func (p *PlanSubscriptionStore) writeSubscriptionsLocked(subs []model.PlanSubscription) error {
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Name < subs[j].Name })
+
+	path := p.subscriptionPath()
+	if err := p.fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create plans directory: %w", err)
+	}
+	if err := persist.SaveJsonToFile(p.fs, path, subs); err != nil {
+		return fmt.Errorf("failed to save subscriptions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// remotePlansDir holds one read-only directory of plan files per subscription
+const remotePlansDir = "remote"
+
+// remotePlanKey is the name a subscribed plan is listed under, keeping it apart from local plans.
+func remotePlanKey(source, planName string) string {
+	return source + "/" + planName
+}
+
+func (s *SkillStore) remoteSourceDir(source string) string {
+	return filepath.Join(s.basePath, plansDir, remotePlansDir, source)
+}
+
+// loadRemotePlans reads every subscribed plan from disk, keyed by remotePlanKey.
+func (s *SkillStore) loadRemotePlans() (map[string]model.SkillPlan, error) {
+	plans := make(map[string]model.SkillPlan)
+	remoteDir := filepath.Join(s.basePath, plansDir, remotePlansDir)
+
+	sources, err := os.ReadDir(remoteDir)
+	if os.IsNotExist(err) {
+		return plans, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read remote plans directory: %w", err)
+	}
+
+	for _, source := range sources {
+		if !source.IsDir() {
+			continue
+		}
+		sourcePlans, err := s.loadSkillPlans(filepath.Join(remoteDir, source.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for planName, plan := range sourcePlans {
+			key := remotePlanKey(source.Name(), planName)
+			plans[key] = model.SkillPlan{Name: key, Skills: plan.Skills, Origin: source.Name(), ReadOnly: true}
+		}
+	}
+	return plans, nil
+}
+
+// SaveRemotePlans replaces the plans of a subscription with the given plan files, keyed by plan name.
+// A nil file keeps the stored copy of that plan. Every file is parsed before anything is written so
+// a bad feed leaves the previous plans in place.
+func (s *SkillStore) SaveRemotePlans(source string, files map[string][]byte) error {
+	if err := validateSourceName(source); err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	sourceDir := s.remoteSourceDir(source)
+	parsed := make(map[string]model.SkillPlan, len(files))
+	contents := make(map[string][]byte, len(files))
+	for planName, data := range files {
+		if planName == "" || strings.ContainsAny(planName, `/\`) {
+			return fmt.Errorf("invalid plan name %q from %s", planName, source)
+		}
+		if data == nil {
+			existing, err := s.fs.ReadFile(filepath.Join(sourceDir, planName+".txt"))
+			if err != nil {
+				return fmt.Errorf("no stored copy of plan %s from %s: %w", planName, source, err)
+			}
+			data = existing
+		}
+		contents[planName] = data
+
+		skills, err := parseSkillLines(data, planName)
+		if err != nil {
+			return err
+		}
+		if len(skills) == 0 {
+			return fmt.Errorf("plan %s from %s has no skills", planName, source)
+		}
+		key := remotePlanKey(source, planName)
+		parsed[key] = model.SkillPlan{Name: key, Skills: skills, Origin: source, ReadOnly: true}
+	}
+
+	if err := os.RemoveAll(sourceDir); err != nil {
+		return fmt.Errorf("failed to clear remote plans for %s: %w", source, err)
+	}
+	if err := s.fs.MkdirAll(sourceDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create remote plans directory for %s: %w", source, err)
+	}
+	note := "Refreshed from subscription " + source
+	for planName, data := range contents {
+		key := remotePlanKey(source, planName)
+		if err := s.writePlanFile(key, filepath.Join(sourceDir, planName+".txt"), data, s.skillPlans[key].Skills, parsed[key].Skills, note); err != nil {
+			return err
+		}
+	}
+
+	s.removeSourceLocked(source)
+	for key, plan := range parsed {
+		s.skillPlans[key] = plan
+	}
+
+	s.logger.Infof("Stored %d plans from %s", len(parsed), source)
+	return nil
+}
+
+// RemoveRemotePlans deletes every plan pulled from a subscription.
+func (s *SkillStore) RemoveRemotePlans(source string) error {
+	if err := validateSourceName(source); err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if err := os.RemoveAll(s.remoteSourceDir(source)); err != nil {
+		return fmt.Errorf("failed to remove remote plans for %s: %w", source, err)
+	}
+	s.removeSourceLocked(source)
+	return nil
+}
+
+func (s *SkillStore) removeSourceLocked(source string) {
+	for key, plan := range s.skillPlans {
+		if plan.Origin == source {
+			delete(s.skillPlans, key)
+		}
+	}
+}
+
+// checkWritableLocked rejects changes to plans that belong to a subscription.
+func (s *SkillStore) checkWritableLocked(planName string) error {
+	if plan, ok := s.skillPlans[planName]; ok && plan.ReadOnly {
+		return fmt.Errorf("eve plan %s is read-only, it is managed by subscription %s", planName, plan.Origin)
+	}
+	return nil
+}
+
+func validateSourceName(source string) error {
+	if source == "" || source == "." || source == ".." || strings.ContainsAny(source, `/\`) {
+		return fmt.Errorf("invalid subscription name %q", source)
+	}
+	return nil
+}
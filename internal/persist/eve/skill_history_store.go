@@ -0,0 +1,126 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+const historyDir = "history"
+
+var _ interfaces.SkillPlanHistoryRepository = (*SkillPlanHistoryStore)(nil)
+
+// SkillPlanHistoryStore keeps every saved revision of a skill plan in plans/history/<plan>.json
+type SkillPlanHistoryStore struct {
+	logger   interfaces.Logger
+	fs       persist.FileSystem
+	basePath string
+	mu       sync.Mutex
+}
+
+func NewSkillPlanHistoryStore(logger interfaces.Logger, fs persist.FileSystem, basePath string) *SkillPlanHistoryStore {
+	return &SkillPlanHistoryStore{
+		logger:   logger,
+		fs:       fs,
+		basePath: basePath,
+	}
+}
+
+func (h *SkillPlanHistoryStore) AddRevision(planName string, skills map[string]model.Skill, note string) (model.SkillPlanRevision, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revisions, err := h.readRevisionsLocked(planName)
+	if err != nil {
+		return model.SkillPlanRevision{}, err
+	}
+
+	next := 1
+	if len(revisions) > 0 {
+		next = revisions[len(revisions)-1].Revision + 1
+	}
+
+	skillsCopy := make(map[string]model.Skill, len(skills))
+	for k, v := range skills {
+		skillsCopy[k] = v
+	}
+
+	revision := model.SkillPlanRevision{
+		Revision:  next,
+		Timestamp: time.Now().UTC(),
+		Note:      note,
+		Skills:    skillsCopy,
+	}
+	revisions = append(revisions, revision)
+
+	if err := persist.SaveJsonToFile(h.fs, h.historyPath(planName), revisions); err != nil {
+		return model.SkillPlanRevision{}, fmt.Errorf("failed to save history for plan %s: %w", planName, err)
+	}
+
+	h.logger.Debugf("Recorded revision %d of plan %s", next, planName)
+	return revision, nil
+}
+
+func (h *SkillPlanHistoryStore) GetRevisions(planName string) ([]model.SkillPlanRevision, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.readRevisionsLocked(planName)
+}
+
+func (h *SkillPlanHistoryStore) RenameHistory(oldName, newName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revisions, err := h.readRevisionsLocked(oldName)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	if err := persist.SaveJsonToFile(h.fs, h.historyPath(newName), revisions); err != nil {
+		return fmt.Errorf("failed to save history for plan %s: %w", newName, err)
+	}
+	if err := h.fs.Remove(h.historyPath(oldName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove history for plan %s: %w", oldName, err)
+	}
+	return nil
+}
+
+func (h *SkillPlanHistoryStore) DeleteHistory(planName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.fs.Remove(h.historyPath(planName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete history for plan %s: %w", planName, err)
+	}
+	return nil
+}
+
+func (h *SkillPlanHistoryStore) historyPath(planName string) string {
+	return filepath.Join(h.basePath, plansDir, historyDir, planName+".json")
+}
+
+// readRevisionsLocked assumes lock is held.
+func (h *SkillPlanHistoryStore) readRevisionsLocked(planName string) ([]model.SkillPlanRevision, error) {
+	path := h.historyPath(planName)
+	if _, err := h.fs.Stat(path); os.IsNotExist(err) {
+		return []model.SkillPlanRevision{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat history for plan %s: %w", planName, err)
+	}
+
+	var revisions []model.SkillPlanRevision
+	if err := persist.ReadJsonFromFile(h.fs, path, &revisions); err != nil {
+		return nil, fmt.Errorf("failed to load history for plan %s: %w", planName, err)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,65 @@
+package eve_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/persist/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func TestSkillPlanHistoryStore_AddAndGetRevisions(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	store := eve.NewSkillPlanHistoryStore(logger, persist.OSFileSystem{}, basePath)
+
+	revisions, err := store.GetRevisions("myplan")
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	first, err := store.AddRevision("myplan", map[string]model.Skill{"Gunnery": {Name: "Gunnery", Level: 3}}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Revision)
+
+	second, err := store.AddRevision("myplan", map[string]model.Skill{"Gunnery": {Name: "Gunnery", Level: 4}}, "bump gunnery")
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Revision)
+
+	revisions, err = store.GetRevisions("myplan")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "bump gunnery", revisions[1].Note)
+	assert.Equal(t, 4, revisions[1].Skills["Gunnery"].Level)
+	assert.False(t, revisions[1].Timestamp.IsZero())
+	assert.FileExists(t, filepath.Join(basePath, "plans", "history", "myplan.json"))
+}
+
+func TestSkillPlanHistoryStore_RenameAndDelete(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	store := eve.NewSkillPlanHistoryStore(logger, persist.OSFileSystem{}, basePath)
+
+	_, err := store.AddRevision("old", map[string]model.Skill{"Drones": {Name: "Drones", Level: 1}}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, store.RenameHistory("old", "new"))
+	oldRevisions, err := store.GetRevisions("old")
+	require.NoError(t, err)
+	assert.Empty(t, oldRevisions)
+	newRevisions, err := store.GetRevisions("new")
+	require.NoError(t, err)
+	assert.Len(t, newRevisions, 1)
+
+	require.NoError(t, store.DeleteHistory("new"))
+	newRevisions, err = store.GetRevisions("new")
+	require.NoError(t, err)
+	assert.Empty(t, newRevisions)
+
+	// Deleting history that does not exist is not an error
+	assert.NoError(t, store.DeleteHistory("never-existed"))
+}
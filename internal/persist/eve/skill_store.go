@@ -7,11 +7,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/guarzo/canifly/internal/embed"
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/persist"
 	"github.com/guarzo/canifly/internal/services/interfaces"
@@ -20,8 +22,9 @@ import (
 var _ interfaces.SkillRepository = (*SkillStore)(nil)
 
 const (
-	plansDir      = "plans"
-	skillTypeFile = "static/invTypes.csv"
+	plansDir         = "plans"
+	skillTypeFile    = "static/invTypes.csv"
+	doctrineFileName = "doctrines.json"
 )
 
 // SkillStore implements interfaces.SkillRepository
@@ -32,20 +35,32 @@ type SkillStore struct {
 	skillPlans    map[string]model.SkillPlan
 	skillTypes    map[string]model.SkillType
 	skillIdToType map[string]model.SkillType
+	doctrines     map[string]model.Doctrine
+	planConflicts map[string]model.EmbeddedPlanConflict
+	planReleases  map[string][]map[string]model.Skill
+	history       *SkillPlanHistoryStore
 	mut           sync.RWMutex
 }
 
 // NewSkillStore now accepts a FileSystem and a basePath for writable directories.
 func NewSkillStore(logger interfaces.Logger, fs persist.FileSystem, basePath string) *SkillStore {
 	return &SkillStore{
-		logger:     logger,
-		fs:         fs,
-		basePath:   basePath,
-		skillPlans: make(map[string]model.SkillPlan),
-		skillTypes: make(map[string]model.SkillType),
+		logger:        logger,
+		fs:            fs,
+		basePath:      basePath,
+		skillPlans:    make(map[string]model.SkillPlan),
+		skillTypes:    make(map[string]model.SkillType),
+		doctrines:     make(map[string]model.Doctrine),
+		planConflicts: make(map[string]model.EmbeddedPlanConflict),
+		history:       NewSkillPlanHistoryStore(logger, fs, basePath),
 	}
 }
 
+// History returns the revision history every plan write of the store is recorded in.
+func (s *SkillStore) History() *SkillPlanHistoryStore {
+	return s.history
+}
+
 func (s *SkillStore) LoadSkillPlans() error {
 	s.logger.Infof("load skill plans")
 
@@ -64,6 +79,14 @@ func (s *SkillStore) LoadSkillPlans() error {
 		return fmt.Errorf("failed to load eve plans: %w", err)
 	}
 
+	remotePlans, err := s.loadRemotePlans()
+	if err != nil {
+		return fmt.Errorf("failed to load subscribed plans: %w", err)
+	}
+	for key, plan := range remotePlans {
+		plans[key] = plan
+	}
+
 	s.mut.Lock()
 	s.skillPlans = plans
 	s.mut.Unlock()
@@ -79,19 +102,18 @@ func (s *SkillStore) SaveSkillPlan(planName string, skills map[string]model.Skil
 
 	planFilePath := filepath.Join(s.basePath, plansDir, planName+".txt")
 
-	var sb strings.Builder
-	for skillName, skill := range skills {
-		sb.WriteString(fmt.Sprintf("%s %d\n", skillName, skill.Level))
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if err := s.checkWritableLocked(planName); err != nil {
+		return err
 	}
 
-	if err := s.fs.WriteFile(planFilePath, []byte(sb.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write plan file: %w", err)
+	if err := s.writePlanFile(planName, planFilePath, formatSkillPlan(skills), s.skillPlans[planName].Skills, skills, ""); err != nil {
+		return err
 	}
 
 	planKey := planName
-	s.mut.Lock()
 	s.skillPlans[planKey] = model.SkillPlan{Name: planKey, Skills: skills}
-	s.mut.Unlock()
 	s.logger.Infof("Saved eve plan %s with %d skills", planKey, len(skills))
 	return nil
 }
@@ -109,11 +131,18 @@ func (s *SkillStore) GetSkillPlans() map[string]model.SkillPlan {
 }
 
 func (s *SkillStore) GetSkillPlanFile(planName string) ([]byte, error) {
+	skillPlanDir := filepath.Join(s.basePath, plansDir)
+
+	s.mut.RLock()
+	if plan, ok := s.skillPlans[planName]; ok && plan.Origin != "" {
+		skillPlanDir = s.remoteSourceDir(plan.Origin)
+		planName = strings.TrimPrefix(planName, plan.Origin+"/")
+	}
+	s.mut.RUnlock()
+
 	planName += ".txt"
 	s.logger.Infof("Attempting to serve eve plan file: %s", planName)
 
-	skillPlanDir := filepath.Join(s.basePath, plansDir)
-
 	filePath := filepath.Join(skillPlanDir, planName)
 	return os.ReadFile(filePath)
 }
@@ -176,6 +205,17 @@ func (s *SkillStore) readSkillsFromFile(filePath string) (map[string]model.Skill
 		return nil, fmt.Errorf("failed to read eve plan file %s: %w", filePath, err)
 	}
 
+	skills, err := parseSkillLines(data, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("Read %d skills from %s", len(skills), filePath)
+	return skills, nil
+}
+
+// parseSkillLines parses "<skill name> <level>" lines, keeping the highest level per skill.
+func parseSkillLines(data []byte, filePath string) (map[string]model.Skill, error) {
 	skills := make(map[string]model.Skill)
 	scanner := bufio.NewScanner(strings.NewReader(string(data)))
 	lineNumber := 0
@@ -205,8 +245,6 @@ func (s *SkillStore) readSkillsFromFile(filePath string) (map[string]model.Skill
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error scanning file %s: %w", filePath, err)
 	}
-
-	s.logger.Debugf("Read %d skills from %s", len(skills), filePath)
 	return skills, nil
 }
 
@@ -358,43 +396,71 @@ func (s *SkillStore) saveDeletedEmbeddedPlans(deletedPlans map[string]bool) erro
 	return nil
 }
 
-// Enhance copyEmbeddedPlansToWritable to skip deleted plans
+// copyEmbeddedPlansToWritable installs embedded plans that are missing and upgrades the ones
+// the user has not edited. Deleted embedded plans are skipped.
 func (s *SkillStore) copyEmbeddedPlansToWritable(writableDir string) error {
 	deletedPlans, err := s.loadDeletedEmbeddedPlans()
 	if err != nil {
 		return err
 	}
 
+	manifest, err := s.loadEmbeddedPlanManifest()
+	if err != nil {
+		return err
+	}
+	if s.planReleases == nil {
+		if s.planReleases, err = readEmbeddedPlanReleases(); err != nil {
+			return err
+		}
+	}
+
 	entries, err := embed.StaticFiles.ReadDir("static/plans")
 	if err != nil {
 		return fmt.Errorf("failed to read embedded plans: %w", err)
 	}
 
+	conflicts := make(map[string]model.EmbeddedPlanConflict)
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			fileName := entry.Name()
-			planName := strings.TrimSuffix(fileName, ".txt")
-
-			// If the user previously deleted this embedded plan, skip copying it
-			if deletedPlans[planName] {
-				s.logger.Debugf("Skipping previously deleted embedded plan: %s", planName)
-				continue
-			}
-
-			destPath := filepath.Join(writableDir, fileName)
-			// Only copy if file does not exist to avoid overwriting custom user changes
-			if _, err := s.fs.Stat(destPath); os.IsNotExist(err) {
-				if err := s.copyEmbeddedFile("static/plans/"+fileName, destPath); err != nil {
-					return fmt.Errorf("failed to copy embedded plan %s: %w", fileName, err)
-				}
-			}
+		if entry.IsDir() {
+			continue
 		}
+		fileName := entry.Name()
+		planName := strings.TrimSuffix(fileName, ".txt")
+
+		// If the user previously deleted this embedded plan, skip copying it
+		if deletedPlans[planName] {
+			s.logger.Debugf("Skipping previously deleted embedded plan: %s", planName)
+			continue
+		}
+
+		conflict, err := s.upgradeEmbeddedPlan(planName, filepath.Join(writableDir, fileName), manifest, s.planReleases[planName])
+		if err != nil {
+			return fmt.Errorf("failed to install embedded plan %s: %w", fileName, err)
+		}
+		if conflict != nil {
+			conflicts[planName] = *conflict
+		}
+	}
+
+	if err := s.saveEmbeddedPlanManifest(manifest); err != nil {
+		return err
 	}
+
+	s.mut.Lock()
+	s.planConflicts = conflicts
+	s.mut.Unlock()
 	return nil
 }
 
 // Modify DeleteSkillPlan to record deletions of embedded plans
 func (s *SkillStore) DeleteSkillPlan(planName string) error {
+	s.mut.RLock()
+	err := s.checkWritableLocked(planName)
+	s.mut.RUnlock()
+	if err != nil {
+		return err
+	}
+
 	planFilePath := filepath.Join(s.basePath, plansDir, planName+".txt")
 
 	if err := s.fs.Remove(planFilePath); err != nil {
@@ -406,28 +472,8 @@ func (s *SkillStore) DeleteSkillPlan(planName string) error {
 	}
 
 	// If this plan was embedded originally, add it to the deleted list
-	// One way is to check if it matches an embedded plan name
-	// For a robust solution, store the original embedded plan list somewhere.
-	// Here, we read from embed and check if planName was one of them.
-	entries, err := embed.StaticFiles.ReadDir("static/plans")
-	if err == nil {
-		embeddedPlan := false
-		for _, entry := range entries {
-			if !entry.IsDir() && strings.TrimSuffix(entry.Name(), ".txt") == planName {
-				embeddedPlan = true
-				break
-			}
-		}
-		if embeddedPlan {
-			deletedPlans, err := s.loadDeletedEmbeddedPlans()
-			if err != nil {
-				return err
-			}
-			deletedPlans[planName] = true
-			if err := s.saveDeletedEmbeddedPlans(deletedPlans); err != nil {
-				return err
-			}
-		}
+	if err := s.markEmbeddedPlanDeleted(planName); err != nil {
+		return err
 	}
 
 	s.mut.Lock()
@@ -437,3 +483,313 @@ func (s *SkillStore) DeleteSkillPlan(planName string) error {
 	s.logger.Infof("Deleted eve plan %s", planName)
 	return nil
 }
+
+// UpdateSkillPlan replaces the skills of an existing plan without touching the embedded plan tracking,
+// note describes the change in the plan history.
+func (s *SkillStore) UpdateSkillPlan(planName string, skills map[string]model.Skill, note string) error {
+	if len(skills) == 0 {
+		return fmt.Errorf("cannot update eve plan %s with no skills", planName)
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.skillPlans[planName]; !ok {
+		return fmt.Errorf("eve plan %s does not exist", planName)
+	}
+	if err := s.checkWritableLocked(planName); err != nil {
+		return err
+	}
+
+	planFilePath := filepath.Join(s.basePath, plansDir, planName+".txt")
+	if err := s.writePlanFile(planName, planFilePath, formatSkillPlan(skills), s.skillPlans[planName].Skills, skills, note); err != nil {
+		return err
+	}
+
+	s.skillPlans[planName] = model.SkillPlan{Name: planName, Skills: skills}
+	s.logger.Infof("Updated eve plan %s with %d skills", planName, len(skills))
+	return nil
+}
+
+// RenameSkillPlan moves a plan to a new name. Renaming an embedded plan records the
+// original name as deleted so it is not copied back on the next load.
+func (s *SkillStore) RenameSkillPlan(oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("new plan name cannot be empty")
+	}
+	if oldName == newName {
+		return nil
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	plan, ok := s.skillPlans[oldName]
+	if !ok {
+		return fmt.Errorf("eve plan %s does not exist", oldName)
+	}
+	if err := s.checkWritableLocked(oldName); err != nil {
+		return err
+	}
+	if _, exists := s.skillPlans[newName]; exists {
+		return fmt.Errorf("eve plan %s already exists", newName)
+	}
+
+	oldPath := filepath.Join(s.basePath, plansDir, oldName+".txt")
+	newPath := filepath.Join(s.basePath, plansDir, newName+".txt")
+
+	data, err := s.fs.ReadFile(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read plan file %s: %w", oldPath, err)
+	}
+	if err := s.fs.WriteFile(newPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan file %s: %w", newPath, err)
+	}
+	if err := s.fs.Remove(oldPath); err != nil {
+		// Roll back so we don't end up with the plan under both names
+		if rmErr := s.fs.Remove(newPath); rmErr != nil {
+			s.logger.Warnf("failed to roll back rename of %s: %v", newPath, rmErr)
+		}
+		return fmt.Errorf("failed to remove plan file %s: %w", oldPath, err)
+	}
+
+	if err := s.markEmbeddedPlanDeleted(oldName); err != nil {
+		return err
+	}
+
+	plan.Name = newName
+	delete(s.skillPlans, oldName)
+	s.skillPlans[newName] = plan
+
+	s.logger.Infof("Renamed eve plan %s to %s", oldName, newName)
+	return nil
+}
+
+// isEmbeddedPlan reports whether planName ships with the application.
+func isEmbeddedPlan(planName string) bool {
+	entries, err := embed.StaticFiles.ReadDir("static/plans")
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.TrimSuffix(entry.Name(), ".txt") == planName {
+			return true
+		}
+	}
+	return false
+}
+
+// markEmbeddedPlanDeleted records planName in the deleted embedded plan list if it is an embedded plan.
+func (s *SkillStore) markEmbeddedPlanDeleted(planName string) error {
+	if !isEmbeddedPlan(planName) {
+		return nil
+	}
+	deletedPlans, err := s.loadDeletedEmbeddedPlans()
+	if err != nil {
+		return err
+	}
+	deletedPlans[planName] = true
+	return s.saveDeletedEmbeddedPlans(deletedPlans)
+}
+
+// writePlanFile writes a plan file and records the change in the plan history. A plan without history
+// first gets its previous skills as a baseline revision so the write can be rolled back, a write that
+// leaves the skills as they were is not recorded.
+func (s *SkillStore) writePlanFile(historyName, path string, data []byte, previous, skills map[string]model.Skill, note string) error {
+	changed := len(previous) == 0 || !sameSkills(previous, skills)
+	if changed {
+		s.recordBaseline(historyName, previous)
+	}
+	if err := s.fs.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan file %s: %w", path, err)
+	}
+	if changed {
+		s.recordRevision(historyName, skills, note)
+	}
+	return nil
+}
+
+// recordBaseline records the skills a plan had before its first recorded change.
+func (s *SkillStore) recordBaseline(historyName string, previous map[string]model.Skill) {
+	if len(previous) == 0 {
+		return
+	}
+	revisions, err := s.history.GetRevisions(historyName)
+	if err != nil {
+		s.logger.Warnf("failed to load history for plan %s: %v", historyName, err)
+		return
+	}
+	if len(revisions) == 0 {
+		s.recordRevision(historyName, previous, "Initial version")
+	}
+}
+
+func (s *SkillStore) recordRevision(historyName string, skills map[string]model.Skill, note string) {
+	if _, err := s.history.AddRevision(historyName, skills, note); err != nil {
+		s.logger.Warnf("failed to record revision for plan %s: %v", historyName, err)
+	}
+}
+
+func formatSkillPlan(skills map[string]model.Skill) []byte {
+	var sb strings.Builder
+	for skillName, skill := range skills {
+		sb.WriteString(fmt.Sprintf("%s %d\n", skillName, skill.Level))
+	}
+	return []byte(sb.String())
+}
+
+// LoadDoctrines reads the persisted doctrines from the plans directory.
+func (s *SkillStore) LoadDoctrines() error {
+	s.logger.Infof("load doctrines")
+
+	doctrinePath := filepath.Join(s.basePath, plansDir, doctrineFileName)
+	doctrines := make(map[string]model.Doctrine)
+
+	if _, err := s.fs.Stat(doctrinePath); os.IsNotExist(err) {
+		s.mut.Lock()
+		s.doctrines = doctrines
+		s.mut.Unlock()
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat doctrine file: %w", err)
+	}
+
+	var stored []model.Doctrine
+	if err := persist.ReadJsonFromFile(s.fs, doctrinePath, &stored); err != nil {
+		return fmt.Errorf("failed to load doctrines: %w", err)
+	}
+	for _, d := range stored {
+		doctrines[d.Name] = d
+	}
+
+	s.mut.Lock()
+	s.doctrines = doctrines
+	s.mut.Unlock()
+
+	s.logger.Debugf("Loaded %d doctrines", len(doctrines))
+	return nil
+}
+
+func (s *SkillStore) GetDoctrines() map[string]model.Doctrine {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	cpy := make(map[string]model.Doctrine, len(s.doctrines))
+	for k, v := range s.doctrines {
+		cpy[k] = v
+	}
+	return cpy
+}
+
+// SaveDoctrine creates or replaces the doctrine with the same name.
+func (s *SkillStore) SaveDoctrine(doctrine model.Doctrine) error {
+	if doctrine.Name == "" {
+		return fmt.Errorf("%w: doctrine name cannot be empty", flyErrors.ErrInvalidDoctrine)
+	}
+	if len(doctrine.Plans) == 0 {
+		return fmt.Errorf("%w: cannot save doctrine %s without plans", flyErrors.ErrInvalidDoctrine, doctrine.Name)
+	}
+	for _, plan := range doctrine.Plans {
+		if plan.Name == "" {
+			return fmt.Errorf("%w: doctrine %s contains a plan without a name", flyErrors.ErrInvalidDoctrine, doctrine.Name)
+		}
+		if len(plan.Minimum) == 0 {
+			return fmt.Errorf("%w: plan %s in doctrine %s has no minimum skills", flyErrors.ErrInvalidDoctrine, plan.Name, doctrine.Name)
+		}
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	updated := make(map[string]model.Doctrine, len(s.doctrines)+1)
+	for k, v := range s.doctrines {
+		updated[k] = v
+	}
+	updated[doctrine.Name] = doctrine
+
+	tiers := changedDoctrineTiers(s.doctrines[doctrine.Name], doctrine)
+	for _, tier := range tiers {
+		s.recordBaseline(tier.historyName, tier.previous)
+	}
+	if err := s.saveDoctrinesLocked(updated); err != nil {
+		return err
+	}
+	s.doctrines = updated
+	for _, tier := range tiers {
+		s.recordRevision(tier.historyName, tier.skills, "Saved doctrine "+doctrine.Name)
+	}
+
+	s.logger.Infof("Saved doctrine %s with %d plans", doctrine.Name, len(doctrine.Plans))
+	return nil
+}
+
+func (s *SkillStore) DeleteDoctrine(name string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.doctrines[name]; !ok {
+		return fmt.Errorf("doctrine %s does not exist", name)
+	}
+
+	updated := make(map[string]model.Doctrine, len(s.doctrines))
+	for k, v := range s.doctrines {
+		if k != name {
+			updated[k] = v
+		}
+	}
+
+	if err := s.saveDoctrinesLocked(updated); err != nil {
+		return err
+	}
+	s.doctrines = updated
+
+	s.logger.Infof("Deleted doctrine %s", name)
+	return nil
+}
+
+// doctrineTier is one tier of a doctrine plan, kept in the plan history as doctrines/<doctrine>/<plan>/<tier>
+type doctrineTier struct {
+	historyName string
+	previous    map[string]model.Skill
+	skills      map[string]model.Skill
+}
+
+// changedDoctrineTiers returns the tiers saving doctrine over previous changes. Names that cannot be
+// used as a history file name are left out of the history.
+func changedDoctrineTiers(previous, doctrine model.Doctrine) []doctrineTier {
+	previousPlans := make(map[string]model.DoctrinePlan, len(previous.Plans))
+	for _, plan := range previous.Plans {
+		previousPlans[plan.Name] = plan
+	}
+
+	var tiers []doctrineTier
+	for _, plan := range doctrine.Plans {
+		if validateSourceName(doctrine.Name) != nil || validateSourceName(plan.Name) != nil {
+			continue
+		}
+		old := previousPlans[plan.Name]
+		historyName := "doctrines/" + doctrine.Name + "/" + plan.Name + "/"
+		if !sameSkills(old.Minimum, plan.Minimum) {
+			tiers = append(tiers, doctrineTier{historyName: historyName + "minimum", previous: old.Minimum, skills: plan.Minimum})
+		}
+		if !sameSkills(old.Recommended, plan.Recommended) {
+			tiers = append(tiers, doctrineTier{historyName: historyName + "recommended", previous: old.Recommended, skills: plan.Recommended})
+		}
+	}
+	return tiers
+}
+
+// saveDoctrinesLocked writes doctrines sorted by name, assumes lock is held.
+func (s *SkillStore) saveDoctrinesLocked(doctrines map[string]model.Doctrine) error {
+	stored := make([]model.Doctrine, 0, len(doctrines))
+	for _, d := range doctrines {
+		stored = append(stored, d)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Name < stored[j].Name })
+
+	doctrinePath := filepath.Join(s.basePath, plansDir, doctrineFileName)
+	if err := persist.SaveJsonToFile(s.fs, doctrinePath, stored); err != nil {
+		return fmt.Errorf("failed to save doctrines: %w", err)
+	}
+	return nil
+}
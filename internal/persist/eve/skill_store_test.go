@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/persist"
 	"github.com/guarzo/canifly/internal/persist/eve"
@@ -153,3 +154,132 @@ func TestSkillStore_GetSkillTypeByID(t *testing.T) {
 	_, found := store.GetSkillTypeByID("999999")
 	assert.False(t, found, "ID 999999 should not be found in skill types")
 }
+
+func TestSkillStore_SaveLoadAndDeleteDoctrine(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, store.LoadDoctrines())
+	assert.Empty(t, store.GetDoctrines())
+
+	doctrine := model.Doctrine{
+		Name: "Shield Kikis",
+		Plans: []model.DoctrinePlan{
+			{
+				Name:        "Kiki DPS",
+				Role:        "DPS",
+				Minimum:     map[string]model.Skill{"Drones": {Name: "Drones", Level: 4}},
+				Recommended: map[string]model.Skill{"Drones": {Name: "Drones", Level: 5}},
+			},
+		},
+	}
+	require.NoError(t, store.SaveDoctrine(doctrine))
+	assert.FileExists(t, filepath.Join(basePath, "plans", "doctrines.json"))
+
+	// A fresh store should read the persisted doctrine back
+	reloaded := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, reloaded.LoadDoctrines())
+	doctrines := reloaded.GetDoctrines()
+	require.Contains(t, doctrines, "Shield Kikis")
+	assert.Equal(t, 5, doctrines["Shield Kikis"].Plans[0].Recommended["Drones"].Level)
+
+	require.NoError(t, reloaded.DeleteDoctrine("Shield Kikis"))
+	assert.Empty(t, reloaded.GetDoctrines())
+	assert.Error(t, reloaded.DeleteDoctrine("Shield Kikis"), "Deleting a missing doctrine should fail")
+}
+
+func TestSkillStore_SaveDoctrineRecordsChangedTiers(t *testing.T) {
+	store := eve.NewSkillStore(&testutil.MockLogger{}, persist.OSFileSystem{}, t.TempDir())
+	require.NoError(t, store.LoadDoctrines())
+
+	plan := model.DoctrinePlan{
+		Name:        "Kiki DPS",
+		Minimum:     map[string]model.Skill{"Drones": {Name: "Drones", Level: 4}},
+		Recommended: map[string]model.Skill{"Drones": {Name: "Drones", Level: 5}},
+	}
+	require.NoError(t, store.SaveDoctrine(model.Doctrine{Name: "Kikis", Plans: []model.DoctrinePlan{plan}}))
+	plan.Minimum = map[string]model.Skill{"Drones": {Name: "Drones", Level: 3}}
+	require.NoError(t, store.SaveDoctrine(model.Doctrine{Name: "Kikis", Plans: []model.DoctrinePlan{plan}}))
+
+	minimum, err := store.History().GetRevisions("doctrines/Kikis/Kiki DPS/minimum")
+	require.NoError(t, err)
+	require.Len(t, minimum, 2)
+	assert.Equal(t, 4, minimum[0].Skills["Drones"].Level)
+	assert.Equal(t, 3, minimum[1].Skills["Drones"].Level)
+	assert.Equal(t, "Saved doctrine Kikis", minimum[1].Note)
+
+	recommended, err := store.History().GetRevisions("doctrines/Kikis/Kiki DPS/recommended")
+	require.NoError(t, err)
+	assert.Len(t, recommended, 1, "the unchanged tier is not recorded again")
+}
+
+func TestSkillStore_SaveDoctrine_Invalid(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	store := eve.NewSkillStore(logger, persist.OSFileSystem{}, t.TempDir())
+
+	assert.ErrorIs(t, store.SaveDoctrine(model.Doctrine{Name: ""}), flyErrors.ErrInvalidDoctrine)
+	assert.ErrorIs(t, store.SaveDoctrine(model.Doctrine{Name: "Empty"}), flyErrors.ErrInvalidDoctrine)
+	assert.ErrorIs(t, store.SaveDoctrine(model.Doctrine{
+		Name:  "NoMinimum",
+		Plans: []model.DoctrinePlan{{Name: "Tackle"}},
+	}), flyErrors.ErrInvalidDoctrine)
+}
+
+func TestSkillStore_UpdateSkillPlan(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	store := eve.NewSkillStore(logger, persist.OSFileSystem{}, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	require.NoError(t, store.SaveSkillPlan("myplan", map[string]model.Skill{"Gunnery": {Name: "Gunnery", Level: 3}}))
+	require.NoError(t, store.UpdateSkillPlan("myplan", map[string]model.Skill{"Gunnery": {Name: "Gunnery", Level: 5}}, ""))
+
+	assert.Equal(t, 5, store.GetSkillPlans()["myplan"].Skills["Gunnery"].Level)
+	data, err := store.GetSkillPlanFile("myplan")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Gunnery 5")
+
+	assert.Error(t, store.UpdateSkillPlan("missing", map[string]model.Skill{"Gunnery": {Name: "Gunnery", Level: 1}}, ""))
+}
+
+func TestSkillStore_RenameSkillPlan(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	store := eve.NewSkillStore(logger, persist.OSFileSystem{}, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	require.NoError(t, store.SaveSkillPlan("typo_plan", map[string]model.Skill{"Drones": {Name: "Drones", Level: 2}}))
+	require.NoError(t, store.RenameSkillPlan("typo_plan", "fixed_plan"))
+
+	plans := store.GetSkillPlans()
+	assert.NotContains(t, plans, "typo_plan")
+	require.Contains(t, plans, "fixed_plan")
+	assert.Equal(t, "fixed_plan", plans["fixed_plan"].Name)
+	assert.NoFileExists(t, filepath.Join(basePath, "plans", "typo_plan.txt"))
+	assert.FileExists(t, filepath.Join(basePath, "plans", "fixed_plan.txt"))
+
+	assert.Error(t, store.RenameSkillPlan("missing", "other"))
+	require.NoError(t, store.SaveSkillPlan("taken", map[string]model.Skill{"Drones": {Name: "Drones", Level: 1}}))
+	assert.Error(t, store.RenameSkillPlan("fixed_plan", "taken"))
+}
+
+func TestSkillStore_RenameEmbeddedPlanDoesNotReappear(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	store := eve.NewSkillStore(logger, persist.OSFileSystem{}, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	if _, exists := store.GetSkillPlans()["Kiki"]; !exists {
+		t.Skip("No embedded Kiki plan found, skipping test")
+	}
+
+	require.NoError(t, store.RenameSkillPlan("Kiki", "Kiki Shield"))
+
+	// Reload to simulate an application restart
+	require.NoError(t, store.LoadSkillPlans())
+	plans := store.GetSkillPlans()
+	assert.NotContains(t, plans, "Kiki", "Renamed embedded plan should not be copied back")
+	assert.Contains(t, plans, "Kiki Shield")
+}
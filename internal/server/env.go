@@ -9,6 +9,7 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Config struct {
@@ -19,8 +20,23 @@ type Config struct {
 	CallbackURL  string
 	PathSuffix   string
 	BasePath     string
+	ESIBaseURL   string // https://esi.evetech.net unless ESI_BASE_URL is set
+	SSOBaseURL   string // https://login.eveonline.com unless SSO_BASE_URL is set
+
+	// Singularity needs an application registered on its own developer site, it is only
+	// available when SISI_CLIENT_ID and SISI_CLIENT_SECRET are set
+	SisiClientID     string
+	SisiClientSecret string
+	SisiCallbackURL  string // EVE_CALLBACK_URL unless SISI_CALLBACK_URL is set
+	SisiSSOBaseURL   string // https://sisilogin.testeveonline.com unless SISI_SSO_BASE_URL is set
 }
 
+const (
+	defaultESIBaseURL     = "https://esi.evetech.net"
+	defaultSSOBaseURL     = "https://login.eveonline.com"
+	defaultSisiSSOBaseURL = "https://sisilogin.testeveonline.com"
+)
+
 func LoadConfig(logger interfaces.Logger) (Config, error) {
 	// Try local .env
 	if err := godotenv.Load(); err != nil {
@@ -54,6 +70,14 @@ func LoadConfig(logger interfaces.Logger) (Config, error) {
 		return cfg, fmt.Errorf("EVE_CLIENT_ID, EVE_CLIENT_SECRET, and EVE_CALLBACK_URL must be set")
 	}
 
+	cfg.ESIBaseURL = getEnvOrDefault("ESI_BASE_URL", defaultESIBaseURL)
+	cfg.SSOBaseURL = getEnvOrDefault("SSO_BASE_URL", defaultSSOBaseURL)
+
+	cfg.SisiClientID = os.Getenv("SISI_CLIENT_ID")
+	cfg.SisiClientSecret = os.Getenv("SISI_CLIENT_SECRET")
+	cfg.SisiCallbackURL = getEnvOrDefault("SISI_CALLBACK_URL", cfg.CallbackURL)
+	cfg.SisiSSOBaseURL = getEnvOrDefault("SISI_SSO_BASE_URL", defaultSisiSSOBaseURL)
+
 	cfg.PathSuffix = os.Getenv("PATH_SUFFIX")
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -86,3 +110,12 @@ func getPort() string {
 	}
 	return port
 }
+
+// getEnvOrDefault returns the variable without a trailing slash, or fallback when it is unset
+func getEnvOrDefault(key, fallback string) string {
+	value := strings.TrimRight(os.Getenv(key), "/")
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -20,13 +20,21 @@ func SetupHandlers(secret string, logger interfaces.Logger, appServices *AppServ
 	// Add authentication middleware
 	r.Use(flyHttp.AuthMiddleware(sessionStore, logger))
 	dashboardHandler := flyHandlers.NewDashboardHandler(sessionStore, logger, appServices.DashBoardService)
-	authHandler := flyHandlers.NewAuthHandler(sessionStore, appServices.EsiService, logger, appServices.AccountService, appServices.StateService, appServices.LoginService, appServices.AuthClient)
+	authHandler := flyHandlers.NewAuthHandler(sessionStore, appServices.EsiService, logger, appServices.AccountService, appServices.StateService, appServices.LoginService, appServices.AuthClients)
 	accountHandler := flyHandlers.NewAccountHandler(sessionStore, logger, appServices.AccountService)
 	characterHandler := flyHandlers.NewCharacterHandler(logger, appServices.CharacterService)
 	skillPlanHandler := flyHandlers.NewSkillPlanHandler(logger, appServices.SkillService)
+	subscriptionHandler := flyHandlers.NewPlanSubscriptionHandler(logger, appServices.SubscriptionSvc)
 	configHandler := flyHandlers.NewConfigHandler(logger, appServices.ConfigService)
-	eveDataHandler := flyHandlers.NewEveDataHandler(logger, appServices.EveProfileService)
-	assocHandler := flyHandlers.NewAssociationHandler(logger, appServices.AssocService)
+	eveDataHandler := flyHandlers.NewEveDataHandler(logger, appServices.EveProfileService, appServices.SettingsWatcher)
+	assocHandler := flyHandlers.NewAssociationHandler(logger, appServices.AssocService, appServices.StateService)
+	notificationHandler := flyHandlers.NewNotificationHandler(logger, appServices.NotificationSvc)
+	walletHandler := flyHandlers.NewWalletHandler(logger, appServices.WalletService)
+	timelineHandler := flyHandlers.NewTimelineHandler(logger, appServices.DashBoardService, appServices.TimelineService)
+	injectorHandler := flyHandlers.NewInjectorHandler(logger, appServices.DashBoardService, appServices.SkillService)
+	remapHandler := flyHandlers.NewRemapHandler(logger, appServices.DashBoardService, appServices.RemapService)
+	assetHandler := flyHandlers.NewAssetHandler(logger, appServices.AssetService)
+	calendarHandler := flyHandlers.NewCalendarHandler(logger, appServices.DashBoardService, appServices.CalendarService, appServices.ConfigService)
 
 	// Public routes
 	r.HandleFunc("/callback/", authHandler.CallBack())
@@ -39,30 +47,79 @@ func SetupHandlers(secret string, logger interfaces.Logger, appServices *AppServ
 
 	r.HandleFunc("/api/logout", authHandler.Logout())
 	r.HandleFunc("/api/login", authHandler.Login())
+	r.HandleFunc("/api/reauth-character", authHandler.ReauthCharacter())
 	r.HandleFunc("/api/reset-identities", authHandler.ResetAccounts())
 
 	r.HandleFunc("/api/get-skill-plan", skillPlanHandler.GetSkillPlanFile())
 	r.HandleFunc("/api/save-skill-plan", skillPlanHandler.SaveSkillPlan())
+	r.HandleFunc("/api/update-skill-plan", skillPlanHandler.UpdateSkillPlan())
+	r.HandleFunc("/api/rename-skill-plan", skillPlanHandler.RenameSkillPlan())
+	r.HandleFunc("/api/skill-plan-history", skillPlanHandler.GetSkillPlanHistory()).Methods("GET")
+	r.HandleFunc("/api/skill-plan-diff", skillPlanHandler.DiffSkillPlan()).Methods("GET")
+	r.HandleFunc("/api/rollback-skill-plan", skillPlanHandler.RollbackSkillPlan())
 	r.HandleFunc("/api/delete-skill-plan", skillPlanHandler.DeleteSkillPlan())
+	r.HandleFunc("/api/embedded-plan-conflicts", skillPlanHandler.GetEmbeddedPlanConflicts()).Methods("GET")
+	r.HandleFunc("/api/resolve-embedded-plan-conflict", skillPlanHandler.ResolveEmbeddedPlanConflict())
+	r.HandleFunc("/api/plan-subscriptions", subscriptionHandler.GetSubscriptions()).Methods("GET")
+	r.HandleFunc("/api/add-plan-subscription", subscriptionHandler.AddSubscription())
+	r.HandleFunc("/api/remove-plan-subscription", subscriptionHandler.RemoveSubscription())
+	r.HandleFunc("/api/refresh-plan-subscriptions", subscriptionHandler.RefreshSubscriptions())
+	r.HandleFunc("/api/injector-plan", injectorHandler.GetInjectorPlan()).Methods("GET")
+	r.HandleFunc("/api/extraction-plan", injectorHandler.GetExtractionPlan()).Methods("GET")
+	r.HandleFunc("/api/remap-plan", remapHandler.GetRemapPlan()).Methods("GET")
+	r.HandleFunc("/api/doctrines", skillPlanHandler.GetDoctrines()).Methods("GET")
+	r.HandleFunc("/api/save-doctrine", skillPlanHandler.SaveDoctrine())
+	r.HandleFunc("/api/delete-doctrine", skillPlanHandler.DeleteDoctrine())
 
 	r.HandleFunc("/api/update-account-name", accountHandler.UpdateAccountName())
 	r.HandleFunc("/api/toggle-account-status", accountHandler.ToggleAccountStatus())
 	r.HandleFunc("/api/toggle-account-visibility", accountHandler.ToggleAccountVisibility())
 	r.HandleFunc("/api/remove-account", accountHandler.RemoveAccount())
+	r.HandleFunc("/api/set-account-expiry", accountHandler.SetAccountExpiry())
+	r.HandleFunc("/api/set-mct-expiry", accountHandler.SetCharacterMCTExpiry())
+	r.HandleFunc("/api/move-character", accountHandler.MoveCharacter())
+	r.HandleFunc("/api/merge-accounts", accountHandler.MergeAccounts())
+
+	r.HandleFunc("/api/alerts", notificationHandler.GetAlerts()).Methods("GET")
+	r.HandleFunc("/api/acknowledge-alert", notificationHandler.AcknowledgeAlert())
+	r.HandleFunc("/api/notification-settings", notificationHandler.GetSettings()).Methods("GET")
+	r.HandleFunc("/api/notification-settings", notificationHandler.UpdateSettings()).Methods("POST")
+
+	r.HandleFunc("/api/wallet-history", walletHandler.GetBalanceHistory()).Methods("GET")
+	r.HandleFunc("/api/wallet-journal", walletHandler.GetJournal()).Methods("GET")
+	r.HandleFunc("/api/refresh-assets", assetHandler.RefreshAssets()).Methods("POST")
+	r.HandleFunc("/api/assets/search", assetHandler.SearchAssets()).Methods("GET")
+	r.HandleFunc("/api/assets/status", assetHandler.GetAssetStatus()).Methods("GET")
+	r.HandleFunc("/api/training-timeline", timelineHandler.GetTimeline()).Methods("GET")
+	r.HandleFunc("/api/calendar-feed", calendarHandler.GetFeedURL()).Methods("GET")
+	r.HandleFunc("/api/reset-calendar-feed", calendarHandler.ResetFeedURL()).Methods("POST")
+	r.HandleFunc("/calendar/{token}.ics", calendarHandler.Feed()).Methods("GET")
 
 	r.HandleFunc("/api/update-character", characterHandler.UpdateCharacter)
 	r.HandleFunc("/api/remove-character", characterHandler.RemoveCharacter)
 
 	r.HandleFunc("/api/choose-settings-dir", configHandler.ChooseSettingsDir)
 	r.HandleFunc("/api/reset-to-default-directory", configHandler.ResetToDefaultDir)
+	r.HandleFunc("/api/discover-settings-dirs", configHandler.DiscoverSettingsDirs).Methods("GET")
+	r.HandleFunc("/api/add-settings-root", configHandler.AddSettingsRoot)
+	r.HandleFunc("/api/remove-settings-root", configHandler.RemoveSettingsRoot)
 	r.HandleFunc("/api/save-user-selections", configHandler.SaveUserSelections)
 
 	r.HandleFunc("/api/sync-subdirectory", eveDataHandler.SyncSubDirectory)
 	r.HandleFunc("/api/sync-all-subdirectories", eveDataHandler.SyncAllSubdirectories)
+	r.HandleFunc("/api/sync-to-root", eveDataHandler.SyncToRoot)
 	r.HandleFunc("/api/backup-directory", eveDataHandler.BackupDirectory)
+	r.HandleFunc("/api/settings-events", eveDataHandler.SettingsEvents).Methods("GET")
 
 	r.HandleFunc("/api/associate-character", assocHandler.AssociateCharacter)
 	r.HandleFunc("/api/unassociate-character", assocHandler.UnassociateCharacter)
+	r.HandleFunc("/api/association-suggestions", assocHandler.SuggestAssociations).Methods("GET")
+	r.HandleFunc("/api/accept-association-suggestions", assocHandler.AcceptSuggestions)
+	r.HandleFunc("/api/association-rules", assocHandler.GetAssociationRules).Methods("GET")
+	r.HandleFunc("/api/association-rules", assocHandler.UpdateAssociationRules).Methods("POST")
+	r.HandleFunc("/api/check-associations", assocHandler.CheckAssociations).Methods("GET")
+	r.HandleFunc("/api/repair-associations", assocHandler.RepairAssociations).Methods("POST")
+	r.HandleFunc("/api/remove-orphaned-associations", assocHandler.RemoveOrphanedAssociations).Methods("POST")
 
 	// Serve static files
 	staticFileServer := http.FileServer(http.FS(embed.StaticFilesSub))
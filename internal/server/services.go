@@ -1,10 +1,12 @@
 package server
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/guarzo/canifly/internal/embed"
 	"github.com/guarzo/canifly/internal/http"
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/persist"
 	"github.com/guarzo/canifly/internal/persist/account"
 	"github.com/guarzo/canifly/internal/persist/config"
@@ -20,26 +22,36 @@ type AppServices struct {
 	EveProfileService interfaces.EveProfilesService
 	AccountService    interfaces.AccountService
 	SkillService      interfaces.SkillService
+	SubscriptionSvc   interfaces.PlanSubscriptionService
+	NotificationSvc   interfaces.NotificationService
+	WalletService     interfaces.WalletService
+	CalendarService   interfaces.CalendarService
+	TimelineService   interfaces.TimelineService
+	AssetService      interfaces.AssetService
+	RemapService      interfaces.RemapService
 	ConfigService     interfaces.ConfigService
 	CharacterService  interfaces.CharacterService
 	DashBoardService  interfaces.DashboardService
 	AssocService      interfaces.AssociationService
 	StateService      interfaces.AppStateService
 	LoginService      interfaces.LoginService
-	AuthClient        interfaces.AuthClient
+	SettingsWatcher   interfaces.SettingsWatcher
+	AuthClients       map[string]interfaces.AuthClient // by server
 }
 
-func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
+// GetServices wires the services, background work they start runs until ctx is canceled.
+func GetServices(ctx context.Context, logger interfaces.Logger, cfg Config) (*AppServices, error) {
 
-	skillService, err := initSkillService(logger, cfg.BasePath)
+	skillService, subscriptionService, err := initSkillService(ctx, logger, cfg.BasePath)
 	if err != nil {
 		return nil, err
 	}
 
 	loginService := initLoginService(logger)
-	authClient := initAuthClient(logger, cfg)
-	esiService := initESIService(logger, cfg, authClient)
-	accountService, assocService := initAccountAndAssoc(logger, esiService, cfg.BasePath)
+	authClients := initAuthClients(logger, cfg)
+	accountStr := account.NewAccountDataStore(logger, persist.OSFileSystem{}, cfg.BasePath)
+	esiService := initESIService(logger, cfg, authClients, accountStr)
+	accountService, assocService := initAccountAndAssoc(logger, esiService, accountStr)
 	configService, err := initConfigService(logger, cfg.BasePath)
 	if err != nil {
 		return nil, err
@@ -49,28 +61,64 @@ func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
 
 	eveProfileService := initEveProfileService(logger, esiService, configService, accountService)
 
-	characterService, dashboardService, err := initCharacterAndDashboard(logger, esiService, skillService, accountService, configService, stateService, eveProfileService)
-	if err != nil {
-		return nil, err
+	notificationStr := config.NewNotificationStore(logger, persist.OSFileSystem{}, cfg.BasePath)
+	notificationService := configSvc.NewNotificationService(ctx, logger, notificationStr, skillService, configSvc.NewWebhookNotifier(logger))
+
+	walletStr := account.NewWalletStore(logger, persist.OSFileSystem{}, cfg.BasePath)
+	walletService := accountSvc.NewWalletService(logger, esiService, walletStr)
+
+	sysStore := eve.NewSystemStore(logger)
+	if err := sysStore.LoadSystems(); err != nil {
+		return nil, fmt.Errorf("failed to load systems %v", err)
 	}
 
+	characterService, dashboardService := initCharacterAndDashboard(logger, esiService, sysStore, skillService, accountService, configService, stateService, eveProfileService, notificationService, walletService)
+
+	settingsWatcher := eveSvc.NewSettingsWatcher(logger, configService, eveProfileService, stateService, eveSvc.DefaultSettingsPollInterval, eveSvc.DefaultSettingsDebounce)
+	go settingsWatcher.Start(ctx)
+
+	assetStr := eve.NewAssetStore(logger, persist.OSFileSystem{}, cfg.BasePath)
+	assetService := eveSvc.NewAssetService(logger, esiService, accountService, skillService, sysStore, assetStr)
+
 	return &AppServices{
 		EsiService:        esiService,
 		EveProfileService: eveProfileService,
 		AccountService:    accountService,
 		SkillService:      skillService,
+		SubscriptionSvc:   subscriptionService,
+		NotificationSvc:   notificationService,
+		WalletService:     walletService,
+		CalendarService:   eveSvc.NewCalendarService(logger, skillService),
+		TimelineService:   eveSvc.NewTimelineService(logger, skillService),
+		AssetService:      assetService,
+		RemapService:      eveSvc.NewRemapService(logger, esiService, skillService),
 		ConfigService:     configService,
 		CharacterService:  characterService,
 		DashBoardService:  dashboardService,
 		AssocService:      assocService,
 		StateService:      stateService,
 		LoginService:      loginService,
-		AuthClient:        authClient,
+		SettingsWatcher:   settingsWatcher,
+		AuthClients:       authClients,
 	}, nil
 }
 
-func initAuthClient(logger interfaces.Logger, cfg Config) interfaces.AuthClient {
-	return accountSvc.NewAuthClient(logger, cfg.ClientID, cfg.ClientSecret, cfg.CallbackURL)
+// initAuthClients returns an SSO client for Tranquility and for Singularity when it is configured.
+func initAuthClients(logger interfaces.Logger, cfg Config) map[string]interfaces.AuthClient {
+	clients := map[string]interfaces.AuthClient{
+		model.ServerTranquility: accountSvc.NewAuthClient(logger, cfg.ClientID, cfg.ClientSecret, cfg.CallbackURL, cfg.SSOBaseURL),
+	}
+	if cfg.SisiClientID != "" && cfg.SisiClientSecret != "" {
+		clients[model.ServerSingularity] = accountSvc.NewAuthClient(logger, cfg.SisiClientID, cfg.SisiClientSecret, cfg.SisiCallbackURL, cfg.SisiSSOBaseURL)
+	}
+	return clients
+}
+
+func ssoBaseURL(cfg Config, server string) string {
+	if server == model.ServerSingularity {
+		return cfg.SisiSSOBaseURL
+	}
+	return cfg.SSOBaseURL
 }
 
 func initEveProfileService(logger interfaces.Logger, esi interfaces.ESIService, con interfaces.ConfigService, ac interfaces.AccountService) interfaces.EveProfilesService {
@@ -78,35 +126,39 @@ func initEveProfileService(logger interfaces.Logger, esi interfaces.ESIService,
 	return eveSvc.NewEveProfileservice(logger, eveRepo, ac, esi, con)
 }
 
-func initCharacterAndDashboard(l interfaces.Logger, e interfaces.ESIService, sk interfaces.SkillService, as interfaces.AccountService, s interfaces.ConfigService, st interfaces.AppStateService, ev interfaces.EveProfilesService) (interfaces.CharacterService, interfaces.DashboardService, error) {
-	sysStore := eve.NewSystemStore(l)
-	if err := sysStore.LoadSystems(); err != nil {
-		return nil, nil, fmt.Errorf("failed to load systems %v", err)
-	}
-
-	characterService := eveSvc.NewCharacterService(e, l, sysStore, sk, as, s)
-	dashboardService := configSvc.NewDashboardService(l, sk, characterService, as, s, st, ev)
-	return characterService, dashboardService, nil
-
+func initCharacterAndDashboard(l interfaces.Logger, e interfaces.ESIService, sys interfaces.SystemRepository, sk interfaces.SkillService, as interfaces.AccountService, s interfaces.ConfigService, st interfaces.AppStateService, ev interfaces.EveProfilesService, n interfaces.NotificationService, w interfaces.WalletService) (interfaces.CharacterService, interfaces.DashboardService) {
+	characterService := eveSvc.NewCharacterService(e, l, sys, sk, as, s)
+	dashboardService := configSvc.NewDashboardService(l, sk, characterService, as, s, st, ev, n, w)
+	return characterService, dashboardService
 }
 
-func initAccountAndAssoc(l interfaces.Logger, e interfaces.ESIService, basePath string) (interfaces.AccountService, interfaces.AssociationService) {
-	accountStr := account.NewAccountDataStore(l, persist.OSFileSystem{}, basePath)
-
+func initAccountAndAssoc(l interfaces.Logger, e interfaces.ESIService, accountStr interfaces.AccountDataRepository) (interfaces.AccountService, interfaces.AssociationService) {
 	assocService := accountSvc.NewAssociationService(l, accountStr, e)
 	accountService := accountSvc.NewAccountService(l, accountStr, e, assocService)
 	return accountService, assocService
 }
 
-func initSkillService(logger interfaces.Logger, basePath string) (interfaces.SkillService, error) {
+func initSkillService(ctx context.Context, logger interfaces.Logger, basePath string) (interfaces.SkillService, interfaces.PlanSubscriptionService, error) {
 	skillStore := eve.NewSkillStore(logger, persist.OSFileSystem{}, basePath)
 	if err := skillStore.LoadSkillPlans(); err != nil {
-		return nil, fmt.Errorf("failed to load eve plans %v", err)
+		return nil, nil, fmt.Errorf("failed to load eve plans %v", err)
 	}
 	if err := skillStore.LoadSkillTypes(); err != nil {
-		return nil, fmt.Errorf("failed to load eve types %v", err)
+		return nil, nil, fmt.Errorf("failed to load eve types %v", err)
 	}
-	return eveSvc.NewSkillService(logger, skillStore), nil
+	if err := skillStore.LoadDoctrines(); err != nil {
+		return nil, nil, fmt.Errorf("failed to load doctrines %v", err)
+	}
+	subscriptionStore := eve.NewPlanSubscriptionStore(logger, persist.OSFileSystem{}, basePath)
+	subscriptionService := eveSvc.NewPlanSubscriptionService(logger, subscriptionStore, skillStore)
+	// Subscribed plans already on disk are usable straight away, so refresh them without delaying startup
+	go func() {
+		if err := subscriptionService.RefreshAll(ctx); err != nil {
+			logger.Warnf("failed to refresh plan subscriptions: %v", err)
+		}
+	}()
+
+	return eveSvc.NewSkillService(logger, skillStore, skillStore.History()), subscriptionService, nil
 }
 
 func initLoginService(logger interfaces.Logger) interfaces.LoginService {
@@ -114,12 +166,21 @@ func initLoginService(logger interfaces.Logger) interfaces.LoginService {
 	return accountSvc.NewLoginService(logger, loginStateStore)
 }
 
-func initESIService(logger interfaces.Logger, cfg Config, authClient interfaces.AuthClient) interfaces.ESIService {
+// initESIService reaches every server with an SSO client, each refreshing tokens with its own SSO and
+// saving them to the account store.
+func initESIService(logger interfaces.Logger, cfg Config, authClients map[string]interfaces.AuthClient, tokenRepo interfaces.TokenRepository) interfaces.ESIService {
 	cacheStr := eve.NewCacheStore(logger, persist.OSFileSystem{}, cfg.BasePath)
 	deletedStr := eve.NewDeletedStore(logger, persist.OSFileSystem{}, cfg.BasePath)
 	cacheService := eveSvc.NewCacheService(logger, cacheStr)
-	httpClient := http.NewEsiHttpClient("https://esi.evetech.net", logger, authClient, cacheService)
-	return eveSvc.NewESIService(httpClient, authClient, logger, cacheService, deletedStr)
+
+	servers := make(map[string]eveSvc.EsiServer, len(authClients))
+	for server, authClient := range authClients {
+		servers[server] = eveSvc.EsiServer{
+			APIClient:  http.NewEsiHttpClient(cfg.ESIBaseURL, logger, authClient, cacheService, tokenRepo),
+			SSOBaseURL: ssoBaseURL(cfg, server),
+		}
+	}
+	return eveSvc.NewESIService(logger, cacheService, deletedStr, servers)
 }
 
 // Modified initConfigService: if EnsureSettingsDir fails, log a warning and reset SettingsDir to empty.
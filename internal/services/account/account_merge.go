@@ -0,0 +1,161 @@
+package account
+
+import (
+	"fmt"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// MoveCharacter moves a character with its token to another account on the same server. An account left
+// without characters is removed, it was most likely created by a mistyped account name at login.
+func (a *accountService) MoveCharacter(characterID int64, toAccountName string) error {
+	accountData, err := a.accountRepo.FetchAccountData()
+	if err != nil {
+		return fmt.Errorf("error fetching account data: %w", err)
+	}
+
+	fromIndex, charIndex := -1, -1
+	for i := range accountData.Accounts {
+		for j := range accountData.Accounts[i].Characters {
+			if accountData.Accounts[i].Characters[j].Character.CharacterID == characterID {
+				fromIndex, charIndex = i, j
+			}
+		}
+	}
+	if fromIndex == -1 {
+		return fmt.Errorf("character not found")
+	}
+
+	from := &accountData.Accounts[fromIndex]
+	to := a.FindAccountByName(toAccountName, accountData.Accounts)
+	if to == nil {
+		return fmt.Errorf("account %s not found", toAccountName)
+	}
+	if to == from {
+		return fmt.Errorf("character is already in account %s", toAccountName)
+	}
+	if err := sameServer(from, to); err != nil {
+		return err
+	}
+
+	character := from.Characters[charIndex]
+	to.Characters = append(to.Characters, character)
+	from.Characters = append(from.Characters[:charIndex:charIndex], from.Characters[charIndex+1:]...)
+	a.logger.Infof("moved %s from account %s to %s", character.Character.CharacterName, from.Name, to.Name)
+
+	if len(from.Characters) == 0 {
+		a.logger.Infof("removing account %s left without characters", from.Name)
+		accountData.Accounts = append(accountData.Accounts[:fromIndex:fromIndex], accountData.Accounts[fromIndex+1:]...)
+	}
+
+	if err := a.accountRepo.SaveAccountData(accountData); err != nil {
+		return fmt.Errorf("failed to save account data: %w", err)
+	}
+	return a.syncMovedCharacters(toAccountName, []int64{characterID})
+}
+
+// MergeAccounts moves every character of one account into another on the same server and removes the
+// emptied account. The account merged into keeps its own settings, except for an omega expiry it lacks.
+func (a *accountService) MergeAccounts(fromAccountName, intoAccountName string) error {
+	if fromAccountName == intoAccountName {
+		return fmt.Errorf("cannot merge account %s into itself", fromAccountName)
+	}
+
+	accountData, err := a.accountRepo.FetchAccountData()
+	if err != nil {
+		return fmt.Errorf("error fetching account data: %w", err)
+	}
+
+	fromIndex := -1
+	for i := range accountData.Accounts {
+		if accountData.Accounts[i].Name == fromAccountName {
+			fromIndex = i
+			break
+		}
+	}
+	if fromIndex == -1 {
+		return fmt.Errorf("account %s not found", fromAccountName)
+	}
+	from := &accountData.Accounts[fromIndex]
+	into := a.FindAccountByName(intoAccountName, accountData.Accounts)
+	if into == nil {
+		return fmt.Errorf("account %s not found", intoAccountName)
+	}
+	if err := sameServer(from, into); err != nil {
+		return err
+	}
+
+	var moved []int64
+	for _, character := range from.Characters {
+		if i := characterIndex(into, character.Character.CharacterID); i >= 0 {
+			// logged in to both, keep the token that lasts longer
+			if character.Token.Expiry.After(into.Characters[i].Token.Expiry) {
+				into.Characters[i].Token = character.Token
+			}
+			continue
+		}
+		into.Characters = append(into.Characters, character)
+		moved = append(moved, character.Character.CharacterID)
+	}
+	if into.OmegaExpiry == nil {
+		into.OmegaExpiry = from.OmegaExpiry
+	}
+	a.logger.Infof("merged %d characters of account %s into %s", len(moved), from.Name, into.Name)
+
+	accountData.Accounts = append(accountData.Accounts[:fromIndex:fromIndex], accountData.Accounts[fromIndex+1:]...)
+	if err := a.accountRepo.SaveAccountData(accountData); err != nil {
+		return fmt.Errorf("failed to save account data: %w", err)
+	}
+	return a.syncMovedCharacters(intoAccountName, moved)
+}
+
+// syncMovedCharacters lets the associations of the characters moved into an account set its ID, as when
+// they log in to it.
+func (a *accountService) syncMovedCharacters(accountName string, characterIDs []int64) error {
+	for _, characterID := range characterIDs {
+		accountData, err := a.accountRepo.FetchAccountData()
+		if err != nil {
+			return fmt.Errorf("error fetching account data: %w", err)
+		}
+		account := a.FindAccountByName(accountName, accountData.Accounts)
+		if account == nil {
+			return fmt.Errorf("account %s not found", accountName)
+		}
+		// the association service saves its own copy of the account data, so carry the ID over after it
+		synced := *account
+		if err := a.assocService.UpdateAssociationsAfterNewCharacter(&synced, characterID); err != nil {
+			a.logger.Debugf("no associations synced for character %d: %v", characterID, err)
+			continue
+		}
+
+		accountData, err = a.accountRepo.FetchAccountData()
+		if err != nil {
+			return fmt.Errorf("error fetching account data: %w", err)
+		}
+		if account = a.FindAccountByName(accountName, accountData.Accounts); account != nil && account.ID != synced.ID {
+			account.ID = synced.ID
+			if err := a.accountRepo.SaveAccountData(accountData); err != nil {
+				return fmt.Errorf("failed to save account data: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func sameServer(from, to *model.Account) error {
+	fromServer, _ := model.NormalizeServer(from.Server)
+	toServer, _ := model.NormalizeServer(to.Server)
+	if fromServer != toServer {
+		return fmt.Errorf("account %s is on %s and %s on %s", from.Name, fromServer, to.Name, toServer)
+	}
+	return nil
+}
+
+func characterIndex(account *model.Account, characterID int64) int {
+	for i := range account.Characters {
+		if account.Characters[i].Character.CharacterID == characterID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,108 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	accountStore "github.com/guarzo/canifly/internal/persist/account"
+	"github.com/guarzo/canifly/internal/services/account"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func tokenCharacter(id int64, name, refreshToken string, expiry time.Time) model.CharacterIdentity {
+	return model.CharacterIdentity{
+		Token:     oauth2.Token{AccessToken: "access-" + refreshToken, RefreshToken: refreshToken, Expiry: expiry},
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: id, CharacterName: name}},
+	}
+}
+
+// newStoredAccountService runs the account and association services on a real account store.
+func newStoredAccountService(t *testing.T, data model.AccountData) (interfaces.AccountService, *accountStore.AccountDataStore, *testutil.MockESIService) {
+	logger := &testutil.MockLogger{}
+	store := accountStore.NewAccountDataStore(logger, persist.OSFileSystem{}, t.TempDir())
+	require.NoError(t, store.SaveAccountData(data))
+
+	esi := &testutil.MockESIService{}
+	assoc := account.NewAssociationService(logger, store, esi)
+	return account.NewAccountService(logger, store, esi, assoc), store, esi
+}
+
+func TestMoveCharacter(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	svc, store, esi := newStoredAccountService(t, model.AccountData{
+		Accounts: []model.Account{
+			{Name: "Main", Characters: []model.CharacterIdentity{tokenCharacter(1, "Pilot", "refresh-1", expiry)}},
+			{Name: "Mian", ID: 12345, Characters: []model.CharacterIdentity{tokenCharacter(2, "Hauler", "refresh-2", expiry)}},
+		},
+		Associations: []model.Association{{UserId: "10", CharId: "2", CharName: "Hauler"}},
+	})
+	esi.On("GetCharacter", "1").Return(&model.CharacterResponse{Name: "Pilot"}, nil).Once()
+
+	require.NoError(t, svc.MoveCharacter(2, "Main"))
+
+	data, err := store.FetchAccountData()
+	require.NoError(t, err)
+	require.Len(t, data.Accounts, 1, "the emptied account is removed")
+	main := data.Accounts[0]
+	assert.Equal(t, int64(10), main.ID)
+	require.Len(t, main.Characters, 2)
+	assert.Equal(t, "refresh-2", main.Characters[1].Token.RefreshToken)
+	assert.True(t, expiry.Equal(main.Characters[1].Token.Expiry))
+	assert.ElementsMatch(t, []model.Association{
+		{UserId: "10", CharId: "2", CharName: "Hauler"},
+		{UserId: "10", CharId: "1", CharName: "Pilot"},
+	}, data.Associations)
+	esi.AssertExpectations(t)
+}
+
+func TestMoveCharacter_Errors(t *testing.T) {
+	svc, _, _ := newStoredAccountService(t, model.AccountData{Accounts: []model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{tokenCharacter(1, "Pilot", "r1", time.Now())}},
+		{Name: "Test", Server: model.ServerSingularity, Characters: []model.CharacterIdentity{tokenCharacter(2, "Tester", "r2", time.Now())}},
+	}})
+
+	assert.EqualError(t, svc.MoveCharacter(3, "Main"), "character not found")
+	assert.EqualError(t, svc.MoveCharacter(1, "Nope"), "account Nope not found")
+	assert.EqualError(t, svc.MoveCharacter(1, "Main"), "character is already in account Main")
+	assert.EqualError(t, svc.MoveCharacter(2, "Main"), "account Test is on singularity and Main on tranquility")
+}
+
+func TestMergeAccounts(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	omega := now.Add(30 * 24 * time.Hour)
+	svc, store, _ := newStoredAccountService(t, model.AccountData{
+		Accounts: []model.Account{
+			{Name: "Main", ID: 10, Characters: []model.CharacterIdentity{tokenCharacter(1, "Pilot", "old", now)}},
+			{Name: "Main2", ID: 777, OmegaExpiry: &omega, Characters: []model.CharacterIdentity{
+				tokenCharacter(1, "Pilot", "new", now.Add(time.Hour)),
+				tokenCharacter(2, "Hauler", "r2", now),
+			}},
+		},
+		Associations: []model.Association{
+			{UserId: "10", CharId: "1", CharName: "Pilot"},
+			{UserId: "10", CharId: "2", CharName: "Hauler"},
+		},
+	})
+
+	assert.Error(t, svc.MergeAccounts("Main", "Main"))
+	require.NoError(t, svc.MergeAccounts("Main2", "Main"))
+
+	data, err := store.FetchAccountData()
+	require.NoError(t, err)
+	require.Len(t, data.Accounts, 1)
+	main := data.Accounts[0]
+	assert.Equal(t, int64(10), main.ID)
+	require.NotNil(t, main.OmegaExpiry)
+	assert.True(t, omega.Equal(*main.OmegaExpiry))
+	require.Len(t, main.Characters, 2)
+	assert.Equal(t, "new", main.Characters[0].Token.RefreshToken, "the longer lasting token is kept")
+	assert.Equal(t, "r2", main.Characters[1].Token.RefreshToken)
+	assert.Len(t, data.Associations, 2)
+}
@@ -2,12 +2,14 @@
 package account
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"golang.org/x/oauth2"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
@@ -52,18 +54,28 @@ func (a *accountService) GetAccountNameByID(id string) (string, bool) {
 	return "", false
 }
 
-func (a *accountService) FindOrCreateAccount(state string, char *model.UserInfoResponse, token *oauth2.Token) error {
+func (a *accountService) FindOrCreateAccount(accountName, server string, char *model.UserInfoResponse, token *oauth2.Token) error {
+	normalized, ok := model.NormalizeServer(server)
+	if !ok {
+		return fmt.Errorf("unknown server %s", server)
+	}
+	server = normalized
+
 	accountData, err := a.accountRepo.FetchAccountData()
 	if err != nil {
 		return err
 	}
 	accounts := accountData.Accounts
 
-	account := a.FindAccountByName(state, accounts)
+	account := a.FindAccountByName(accountName, accounts)
 	if account == nil {
-		account = createNewAccountWithCharacter(state, token, char)
+		account = createNewAccountWithCharacter(accountName, server, token, char)
 		accounts = append(accounts, *account)
 	} else {
+		if existing, _ := model.NormalizeServer(account.Server); existing != server {
+			return fmt.Errorf("account %s is on %s, not %s", account.Name, existing, server)
+		}
+
 		// Check if character already exists in this account
 		var characterAssigned bool
 		for i := range account.Characters {
@@ -117,7 +129,7 @@ func (a *accountService) DeleteAllAccounts() error {
 	return nil
 }
 
-func createNewAccountWithCharacter(name string, token *oauth2.Token, user *model.UserInfoResponse) *model.Account {
+func createNewAccountWithCharacter(name, server string, token *oauth2.Token, user *model.UserInfoResponse) *model.Account {
 	newChar := model.CharacterIdentity{
 		Token: *token,
 		Character: model.Character{
@@ -130,6 +142,7 @@ func createNewAccountWithCharacter(name string, token *oauth2.Token, user *model
 		Status:     model.Alpha,
 		Characters: []model.CharacterIdentity{newChar},
 		ID:         time.Now().Unix(),
+		Server:     server,
 	}
 }
 
@@ -186,6 +199,7 @@ func (a *accountService) ToggleAccountStatus(accountID int64) error {
 			} else {
 				accounts[i].Status = "Alpha"
 			}
+			accounts[i].StatusReason = "Set manually"
 			accountFound = true
 			break
 		}
@@ -273,24 +287,39 @@ func (a *accountService) RefreshAccountData(characterSvc interfaces.CharacterSer
 	for i := range accounts {
 		account := &accounts[i]
 		a.logger.Debugf("Processing account: %s", account.Name)
+		// tokens of a server without an SSO client cannot be refreshed, the account is kept as it was
+		if _, err := a.esi.ForServer(account.Server); err != nil {
+			a.logger.Warnf("Skipping account %s: %v", account.Name, err)
+			continue
+		}
 
 		for j := range account.Characters {
 			charIdentity := &account.Characters[j]
 			a.logger.Debugf("Processing character: %s (ID: %d)", charIdentity.Character.CharacterName, charIdentity.Character.CharacterID)
 
-			updatedCharIdentity, err := characterSvc.ProcessIdentity(charIdentity)
+			updatedCharIdentity, err := characterSvc.ProcessIdentity(charIdentity, account.Server)
 			if err != nil {
 				a.logger.Errorf("Failed to process identity for character %d: %v", charIdentity.Character.CharacterID, err)
+				// only failing to renew the token says anything about it, other failures keep the last state
+				switch {
+				case errors.Is(err, flyErrors.ErrTokenRevoked):
+					charIdentity.TokenRevoked = true
+					updateTokenState(charIdentity)
+				case errors.Is(err, flyErrors.ErrTokenRefreshFailed):
+					charIdentity.RefreshFailures++
+					updateTokenState(charIdentity)
+				}
 				continue
 			}
 
-			if updatedCharIdentity.MCT && updatedCharIdentity.Character.TotalSP > AlphaMaxSp {
-				account.Status = model.Omega
-			}
-
+			updatedCharIdentity.TokenRevoked = false
+			updatedCharIdentity.RefreshFailures = 0
+			updateTokenState(updatedCharIdentity)
 			account.Characters[j] = *updatedCharIdentity
 		}
 
+		classifyAccount(account, time.Now())
+
 		a.logger.Debugf("Account %s has %d characters after processing", account.Name, len(account.Characters))
 	}
 
@@ -1,13 +1,16 @@
 package account_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"golang.org/x/oauth2"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/account"
+	"github.com/guarzo/canifly/internal/services/interfaces"
 	"github.com/guarzo/canifly/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -33,7 +36,7 @@ func TestFindOrCreateAccount_NewAccount(t *testing.T) {
 	// After creation, we must save
 	repo.On("SaveAccountData", mock.Anything).Return(nil).Once()
 
-	err := svc.FindOrCreateAccount("testAccount", char, token)
+	err := svc.FindOrCreateAccount("testAccount", "", char, token)
 	assert.NoError(t, err)
 
 	repo.AssertExpectations(t)
@@ -65,13 +68,43 @@ func TestFindOrCreateAccount_ExistingAccount(t *testing.T) {
 	assoc.On("UpdateAssociationsAfterNewCharacter", mock.Anything, int64(9999)).Return(nil).Once()
 	repo.On("SaveAccountData", mock.Anything).Return(nil).Once()
 
-	err := svc.FindOrCreateAccount("testAccount", char, token)
+	err := svc.FindOrCreateAccount("testAccount", "", char, token)
 	assert.NoError(t, err)
 
 	repo.AssertExpectations(t)
 	assoc.AssertExpectations(t)
 }
 
+func TestFindOrCreateAccount_Server(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockAccountDataRepository{}
+	assoc := &testutil.MockAssociationService{}
+	svc := account.NewAccountService(logger, repo, &testutil.MockESIService{}, assoc)
+
+	char := &model.UserInfoResponse{CharacterID: 9999, CharacterName: "TestChar"}
+	token := &oauth2.Token{AccessToken: "xyz"}
+	existing := model.Account{Name: "Main", Status: model.Alpha, ID: 1}
+
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: []model.Account{existing}}, nil)
+	assoc.On("UpdateAssociationsAfterNewCharacter", mock.Anything, int64(9999)).Return(nil)
+	var saved model.AccountData
+	repo.On("SaveAccountData", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(model.AccountData)
+	}).Return(nil)
+
+	// accounts saved before servers existed are on Tranquility
+	err := svc.FindOrCreateAccount("Main", model.ServerSingularity, char, token)
+	assert.Error(t, err)
+	err = svc.FindOrCreateAccount("Test", "duality", char, token)
+	assert.Error(t, err)
+
+	err = svc.FindOrCreateAccount("Test", model.ServerSingularity, char, token)
+	assert.NoError(t, err)
+	if assert.Len(t, saved.Accounts, 2) {
+		assert.Equal(t, model.ServerSingularity, saved.Accounts[1].Server)
+	}
+}
+
 func TestUpdateAccountName(t *testing.T) {
 	logger := &testutil.MockLogger{}
 	repo := &testutil.MockAccountDataRepository{}
@@ -207,3 +240,250 @@ func TestFetchAccounts_Error(t *testing.T) {
 	assert.Nil(t, result)
 	repo.AssertExpectations(t)
 }
+
+func refreshSingleAccount(t *testing.T, status model.AccountStatus, char model.Character) model.Account {
+	t.Helper()
+	return refreshAccount(t, model.Account{Name: "Acc", Status: status, Characters: []model.CharacterIdentity{{Character: char}}})
+}
+
+func refreshAccount(t *testing.T, acc model.Account) model.Account {
+	t.Helper()
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	assoc := &testutil.MockAssociationService{}
+	charSvc := &testutil.MockCharacterService{}
+
+	svc := account.NewAccountService(logger, repo, esi, assoc)
+
+	identity := acc.Characters[0]
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: []model.Account{acc}}, nil).Once()
+	charSvc.On("ProcessIdentity", mock.Anything, "").Return(&identity, nil).Once()
+	repo.On("SaveAccountData", mock.Anything).Return(nil).Once()
+	esi.On("SaveEsiCache").Return(nil).Once()
+
+	data, err := svc.RefreshAccountData(charSvc)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	return data.Accounts[0]
+}
+
+func TestRefreshAccountData_DetectsLapsedOmega(t *testing.T) {
+	char := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterName: "Lapsed"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{
+			TotalSP: 40000000,
+			Skills: []model.SkillResponse{
+				{SkillID: 3300, ActiveSkillLevel: 4, TrainedSkillLevel: 5},
+				{SkillID: 20533, ActiveSkillLevel: 0, TrainedSkillLevel: 3},
+			},
+		},
+	}
+
+	acc := refreshSingleAccount(t, model.Omega, char)
+	assert.Equal(t, model.Alpha, acc.Status)
+	assert.Contains(t, acc.StatusReason, "2 skills active below their trained level")
+}
+
+func TestRefreshAccountData_DetectsOmegaOnlySkill(t *testing.T) {
+	char := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterName: "Capsuleer"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{
+			Skills: []model.SkillResponse{{SkillID: 12096, ActiveSkillLevel: 4, TrainedSkillLevel: 4}},
+		},
+	}
+
+	acc := refreshSingleAccount(t, model.Alpha, char)
+	assert.Equal(t, model.Omega, acc.Status)
+	assert.Contains(t, acc.StatusReason, "Logistics Cruisers")
+}
+
+func TestRefreshAccountData_DetectsOmegaTrainingRate(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	finish := start.Add(10 * time.Hour)
+	char := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterName: "Trainer"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{
+			TotalSP: 1000000,
+			Skills:  []model.SkillResponse{{SkillID: 3300, ActiveSkillLevel: 3, TrainedSkillLevel: 3}},
+		},
+		// 18000 SP over 10 hours is 1800 SP/hour, beyond any alpha clone
+		SkillQueue: []model.SkillQueue{{SkillID: 3300, StartDate: &start, FinishDate: &finish, TrainingStartSP: 8000, LevelEndSP: 26000}},
+	}
+
+	acc := refreshSingleAccount(t, model.Alpha, char)
+	assert.Equal(t, model.Omega, acc.Status)
+	assert.Contains(t, acc.StatusReason, "1800 SP/hour")
+}
+
+func TestRefreshAccountData_KeepsStatusWithoutEvidence(t *testing.T) {
+	char := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterName: "Idle"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{
+			TotalSP: 1000000,
+			Skills:  []model.SkillResponse{{SkillID: 3300, ActiveSkillLevel: 3, TrainedSkillLevel: 3}},
+		},
+	}
+
+	acc := refreshSingleAccount(t, model.Omega, char)
+	assert.Equal(t, model.Omega, acc.Status)
+	assert.Contains(t, acc.StatusReason, "Not verified")
+
+	// an omega expiry still ahead is trusted
+	expiry := time.Now().Add(48 * time.Hour)
+	acc = refreshAccount(t, model.Account{Name: "Acc", Status: model.Omega, StatusReason: "Set manually", OmegaExpiry: &expiry,
+		Characters: []model.CharacterIdentity{{Character: char}}})
+	assert.Equal(t, model.Omega, acc.Status)
+	assert.Equal(t, "Set manually", acc.StatusReason)
+}
+
+func TestRefreshAccountData_ExpiredOmegaWithoutEvidenceTurnsAlpha(t *testing.T) {
+	// below the alpha skill caps with an idle queue, nothing in ESI tells the clone state
+	char := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterName: "Idle"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{
+			TotalSP: 1000000,
+			Skills:  []model.SkillResponse{{SkillID: 3300, ActiveSkillLevel: 3, TrainedSkillLevel: 3}},
+		},
+	}
+	expiry := time.Now().Add(-24 * time.Hour)
+
+	acc := refreshAccount(t, model.Account{Name: "Acc", Status: model.Omega, OmegaExpiry: &expiry,
+		Characters: []model.CharacterIdentity{{Character: char}}})
+	assert.Equal(t, model.Alpha, acc.Status)
+	assert.Contains(t, acc.StatusReason, "Omega expired on "+expiry.Format("2006-01-02"))
+}
+
+func TestSetAccountExpiry(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	assoc := &testutil.MockAssociationService{}
+
+	svc := account.NewAccountService(logger, repo, esi, assoc)
+
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: []model.Account{{Name: "Acc", ID: 7}}}, nil).Twice()
+	repo.On("SaveAccountData", mock.MatchedBy(func(data model.AccountData) bool {
+		return data.Accounts[0].OmegaExpiry != nil && data.Accounts[0].OmegaExpiry.Equal(expiry)
+	})).Return(nil).Once()
+
+	assert.NoError(t, svc.SetAccountExpiry(7, &expiry))
+	assert.EqualError(t, svc.SetAccountExpiry(8, &expiry), "account not found")
+	repo.AssertExpectations(t)
+}
+
+func TestSetCharacterMCTExpiry(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	assoc := &testutil.MockAssociationService{}
+
+	svc := account.NewAccountService(logger, repo, esi, assoc)
+
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	char := model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 42}}}
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: []model.Account{{Name: "Acc", Characters: []model.CharacterIdentity{char}}}}, nil).Twice()
+	repo.On("SaveAccountData", mock.MatchedBy(func(data model.AccountData) bool {
+		return data.Accounts[0].Characters[0].MCTExpiry.Equal(expiry)
+	})).Return(nil).Once()
+
+	assert.NoError(t, svc.SetCharacterMCTExpiry(42, &expiry))
+	assert.EqualError(t, svc.SetCharacterMCTExpiry(43, &expiry), "character not found")
+	repo.AssertExpectations(t)
+}
+
+func TestGetExpiryWarnings(t *testing.T) {
+	svc := account.NewAccountService(&testutil.MockLogger{}, &testutil.MockAccountDataRepository{}, &testutil.MockESIService{}, &testutil.MockAssociationService{})
+
+	soon := time.Now().Add(50 * time.Hour)
+	expired := time.Now().Add(-30 * time.Hour)
+	later := time.Now().Add(30 * 24 * time.Hour)
+	accounts := []model.Account{
+		{
+			Name:        "Soon",
+			ID:          1,
+			OmegaExpiry: &soon,
+			Characters: []model.CharacterIdentity{{
+				Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 10, CharacterName: "Slot"}},
+				MCTExpiry: &expired,
+			}},
+		},
+		{Name: "Later", ID: 2, OmegaExpiry: &later},
+		{Name: "Unknown", ID: 3},
+	}
+
+	warnings := svc.GetExpiryWarnings(accounts)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, model.ExpiryMCT, warnings[0].Kind)
+		assert.Equal(t, "Slot", warnings[0].CharacterName)
+		assert.Equal(t, -2, warnings[0].DaysLeft)
+		assert.Equal(t, model.ExpiryOmega, warnings[1].Kind)
+		assert.Equal(t, "Soon", warnings[1].AccountName)
+		assert.Equal(t, 2, warnings[1].DaysLeft)
+	}
+}
+
+func TestRefreshAccountData_FlagsRevokedToken(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	assoc := &testutil.MockAssociationService{}
+	charSvc := &testutil.MockCharacterService{}
+
+	svc := account.NewAccountService(logger, repo, esi, assoc)
+
+	revoked := model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Revoked"}}}
+	restored := model.CharacterIdentity{TokenRevoked: true, Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Restored"}}}
+	accounts := []model.Account{{Name: "Acc", Characters: []model.CharacterIdentity{revoked, restored}}}
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: accounts}, nil).Once()
+	charSvc.On("ProcessIdentity", mock.MatchedBy(func(c *model.CharacterIdentity) bool { return c.Character.CharacterID == 1 }), "").
+		Return((*model.CharacterIdentity)(nil), fmt.Errorf("failed to get user info: %w", flyErrors.ErrTokenRevoked)).Once()
+	charSvc.On("ProcessIdentity", mock.MatchedBy(func(c *model.CharacterIdentity) bool { return c.Character.CharacterID == 2 }), "").
+		Return(&restored, nil).Once()
+	repo.On("SaveAccountData", mock.Anything).Return(nil).Once()
+	esi.On("SaveEsiCache").Return(nil).Once()
+
+	data, err := svc.RefreshAccountData(charSvc)
+	assert.NoError(t, err)
+	assert.True(t, data.Accounts[0].Characters[0].TokenRevoked)
+	assert.False(t, data.Accounts[0].Characters[1].TokenRevoked)
+}
+
+// tranquilityOnly is an ESI service without a Singularity client
+type tranquilityOnly struct {
+	*testutil.MockESIService
+}
+
+func (e tranquilityOnly) ForServer(server string) (interfaces.ESIService, error) {
+	if name, _ := model.NormalizeServer(server); name != model.ServerTranquility {
+		return nil, flyErrors.ErrServerNotConfigured
+	}
+	return e.MockESIService, nil
+}
+
+func TestRefreshAccountData_SkipsUnconfiguredServer(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	charSvc := &testutil.MockCharacterService{}
+	svc := account.NewAccountService(&testutil.MockLogger{}, repo, tranquilityOnly{esi}, &testutil.MockAssociationService{})
+
+	tester := model.CharacterIdentity{
+		Token:      oauth2.Token{AccessToken: "sisi", RefreshToken: "sisi", Expiry: time.Now().Add(-time.Hour)},
+		TokenState: model.TokenStateValid,
+		Character:  model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Tester"}},
+	}
+	pilot := model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"}}}
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: []model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{pilot}},
+		{Name: "Test", Server: model.ServerSingularity, Characters: []model.CharacterIdentity{tester}},
+	}}, nil).Once()
+	charSvc.On("ProcessIdentity", mock.Anything, "").Return(&pilot, nil).Once()
+	repo.On("SaveAccountData", mock.Anything).Return(nil).Once()
+	esi.On("SaveEsiCache").Return(nil).Once()
+
+	data, err := svc.RefreshAccountData(charSvc)
+	assert.NoError(t, err)
+	assert.Equal(t, tester, data.Accounts[1].Characters[0], "the Singularity character is left as it was")
+	charSvc.AssertExpectations(t)
+}
@@ -0,0 +1,294 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+)
+
+func (assoc *associationService) GetAssociationRules() (model.AssociationRules, error) {
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return model.AssociationRules{}, fmt.Errorf("failed to fetch account data: %w", err)
+	}
+	return accountData.AssociationRules, nil
+}
+
+// UpdateAssociationRules replaces the rules, existing associations breaking them are reported by the checker.
+func (assoc *associationService) UpdateAssociationRules(rules model.AssociationRules) error {
+	if rules.MaxCharactersPerUser < 0 {
+		return fmt.Errorf("characters per user cannot be negative")
+	}
+
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return fmt.Errorf("failed to fetch account data: %w", err)
+	}
+	accountData.AssociationRules = rules
+	if err := assoc.accountRepo.SaveAccountData(accountData); err != nil {
+		return fmt.Errorf("failed to save updated account data: %w", err)
+	}
+	return nil
+}
+
+// CheckAssociations reports associations and accounts that disagree with each other, with the profiles
+// or with the rules. Orphaned associations are only looked for when profiles are given.
+func (assoc *associationService) CheckAssociations(profiles []model.EveProfile) ([]model.AssociationIssue, error) {
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account data: %w", err)
+	}
+	return assoc.findAssociationIssues(accountData, profiles), nil
+}
+
+// RepairAssociations drops duplicate and removed character associations and corrects account IDs that the
+// associations of their characters agree on. It returns the issues it repaired. Orphans are left alone, a
+// settings root that could not be read leaves its user files out of the profiles.
+func (assoc *associationService) RepairAssociations() ([]model.AssociationIssue, error) {
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account data: %w", err)
+	}
+
+	var repaired []model.AssociationIssue
+	dropped := make(map[[2]string]bool)
+	for _, issue := range assoc.findAssociationIssues(accountData, nil) {
+		if !issue.Repairable {
+			continue
+		}
+		// duplicates go while rebuilding the list and account IDs follow the associations left
+		if issue.Kind == model.AssociationRemovedCharacter {
+			dropped[[2]string{issue.UserId, issue.CharId}] = true
+		}
+		repaired = append(repaired, issue)
+	}
+	if len(repaired) == 0 {
+		return repaired, nil
+	}
+
+	seen := make(map[[2]string]bool)
+	kept := make([]model.Association, 0, len(accountData.Associations))
+	for _, a := range accountData.Associations {
+		key := [2]string{a.UserId, a.CharId}
+		if dropped[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, a)
+	}
+	accountData.Associations = kept
+
+	for i := range accountData.Accounts {
+		if userId, ok := associatedUserID(&accountData.Accounts[i], kept); ok {
+			accountData.Accounts[i].ID = userId
+		}
+	}
+
+	if err := assoc.accountRepo.SaveAccountData(accountData); err != nil {
+		return nil, fmt.Errorf("failed to save updated account data: %w", err)
+	}
+	assoc.logger.Infof("repaired %d association issues", len(repaired))
+	return repaired, nil
+}
+
+// RemoveOrphanedAssociations removes the orphans the user confirmed, those that meanwhile turned up in the
+// profiles are kept. It returns the associations it removed.
+func (assoc *associationService) RemoveOrphanedAssociations(profiles []model.EveProfile, orphans []model.Association) ([]model.Association, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles loaded to check the user files against")
+	}
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account data: %w", err)
+	}
+
+	userFiles := profileUserIDs(profiles)
+	remove := make(map[[2]string]bool)
+	for _, o := range orphans {
+		if !userFiles[o.UserId] {
+			remove[[2]string{o.UserId, o.CharId}] = true
+		}
+	}
+
+	removed := make([]model.Association, 0)
+	kept := make([]model.Association, 0, len(accountData.Associations))
+	for _, a := range accountData.Associations {
+		if remove[[2]string{a.UserId, a.CharId}] {
+			removed = append(removed, a)
+			continue
+		}
+		kept = append(kept, a)
+	}
+	if len(removed) == 0 {
+		return removed, nil
+	}
+	accountData.Associations = kept
+
+	for i := range accountData.Accounts {
+		if userId, ok := associatedUserID(&accountData.Accounts[i], kept); ok {
+			accountData.Accounts[i].ID = userId
+		}
+	}
+
+	if err := assoc.accountRepo.SaveAccountData(accountData); err != nil {
+		return nil, fmt.Errorf("failed to save updated account data: %w", err)
+	}
+	assoc.logger.Infof("removed %d orphaned associations", len(removed))
+	return removed, nil
+}
+
+func (assoc *associationService) findAssociationIssues(accountData model.AccountData, profiles []model.EveProfile) []model.AssociationIssue {
+	var issues []model.AssociationIssue
+	rules := accountData.AssociationRules
+
+	userFiles := profileUserIDs(profiles)
+	seen := make(map[[2]string]bool)
+	removed := make(map[string]bool)
+	usersByChar := make(map[string][]string)
+	charsByUser := make(map[string]int)
+	for _, a := range accountData.Associations {
+		key := [2]string{a.UserId, a.CharId}
+		if seen[key] {
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationDuplicate, UserId: a.UserId, CharId: a.CharId, Repairable: true,
+				Message: fmt.Sprintf("%s is associated with user file %s more than once", charLabel(a), a.UserId),
+			})
+			continue
+		}
+		seen[key] = true
+
+		if len(profiles) > 0 && !userFiles[a.UserId] {
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationOrphaned, UserId: a.UserId, CharId: a.CharId,
+				Message: fmt.Sprintf("user file %s of %s is in none of the profiles, remove it if no settings root is missing", a.UserId, charLabel(a)),
+			})
+		}
+
+		if _, checked := removed[a.CharId]; !checked {
+			removed[a.CharId] = assoc.characterRemoved(a.CharId)
+		}
+		if removed[a.CharId] {
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationRemovedCharacter, UserId: a.UserId, CharId: a.CharId, Repairable: true,
+				Message: fmt.Sprintf("%s no longer exists", charLabel(a)),
+			})
+		}
+
+		usersByChar[a.CharId] = append(usersByChar[a.CharId], a.UserId)
+		charsByUser[a.UserId]++
+	}
+
+	for _, userId := range sortedKeys(charsByUser) {
+		if limit := rules.CharacterLimit(); charsByUser[userId] > limit {
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationRuleViolation, UserId: userId,
+				Message: fmt.Sprintf("user file %s has %d characters, the limit is %d", userId, charsByUser[userId], limit),
+			})
+		}
+	}
+	if !rules.AllowSharedCharacters {
+		for _, charId := range sortedKeys(usersByChar) {
+			if users := usersByChar[charId]; len(users) > 1 {
+				issues = append(issues, model.AssociationIssue{
+					Kind: model.AssociationRuleViolation, CharId: charId,
+					Message: fmt.Sprintf("character %s is associated with user files %v", charId, users),
+				})
+			}
+		}
+	}
+
+	names := make(map[string]int)
+	for i := range accountData.Accounts {
+		account := &accountData.Accounts[i]
+		names[account.Name]++
+
+		users := accountUserIDs(account, accountData.Associations)
+		switch {
+		case len(users) > 1:
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationAccountMismatch, Account: account.Name,
+				Message: fmt.Sprintf("characters of %s are associated with different user files %v", account.Name, users),
+			})
+		case len(users) == 1 && strconv.FormatInt(account.ID, 10) != users[0]:
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationAccountMismatch, Account: account.Name, UserId: users[0], Repairable: true,
+				Message: fmt.Sprintf("%s has ID %d but its characters are associated with user file %s", account.Name, account.ID, users[0]),
+			})
+		}
+	}
+	for _, name := range sortedKeys(names) {
+		if names[name] > 1 {
+			issues = append(issues, model.AssociationIssue{
+				Kind: model.AssociationDuplicateName, Account: name,
+				Message: fmt.Sprintf("%d accounts are named %s, rename them apart", names[name], name),
+			})
+		}
+	}
+
+	return issues
+}
+
+// characterRemoved is true only when ESI no longer knows the character, not when it cannot be reached.
+func (assoc *associationService) characterRemoved(charId string) bool {
+	_, err := assoc.esi.GetCharacter(charId)
+	var customErr *flyErrors.CustomError
+	return err != nil && errors.As(err, &customErr) && customErr.StatusCode == http.StatusNotFound
+}
+
+func profileUserIDs(profiles []model.EveProfile) map[string]bool {
+	userFiles := make(map[string]bool)
+	for _, profile := range profiles {
+		for _, uf := range profile.AvailableUserFiles {
+			userFiles[uf.UserId] = true
+		}
+	}
+	return userFiles
+}
+
+// accountUserIDs returns the user files the characters of an account are associated with.
+func accountUserIDs(account *model.Account, associations []model.Association) []string {
+	var users []string
+	for _, ch := range account.Characters {
+		charId := strconv.FormatInt(ch.Character.CharacterID, 10)
+		for _, a := range associations {
+			if a.CharId == charId && !slices.Contains(users, a.UserId) {
+				users = append(users, a.UserId)
+			}
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// associatedUserID returns the ID an account should have going by its associations, false when they disagree
+// or there are none. An account without associations keeps the ID it was created with.
+func associatedUserID(account *model.Account, associations []model.Association) (int64, bool) {
+	users := accountUserIDs(account, associations)
+	if len(users) != 1 {
+		return 0, false
+	}
+	userId, err := strconv.ParseInt(users[0], 10, 64)
+	return userId, err == nil && userId != 0
+}
+
+func charLabel(a model.Association) string {
+	if a.CharName != "" {
+		return fmt.Sprintf("%s (%s)", a.CharName, a.CharId)
+	}
+	return "character " + a.CharId
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
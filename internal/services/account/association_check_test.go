@@ -0,0 +1,211 @@
+package account_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/account"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func TestAssociateCharacter_Rules(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	repo.On("FetchAccountData").Return(model.AccountData{
+		Associations:     []model.Association{{UserId: "200", CharId: "300"}},
+		AssociationRules: model.AssociationRules{MaxCharactersPerUser: 1},
+	}, nil).Once()
+	err := assocSvc.AssociateCharacter("200", "301")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum of 1 associated characters")
+
+	// the character may also belong to a second user file
+	repo.On("FetchAccountData").Return(model.AccountData{
+		Associations:     []model.Association{{UserId: "200", CharId: "300"}},
+		AssociationRules: model.AssociationRules{AllowSharedCharacters: true},
+	}, nil).Once()
+	esi.On("GetCharacter", "300").Return(&model.CharacterResponse{Name: "Char300"}, nil).Once()
+	repo.On("SaveAccountData", mock.MatchedBy(func(data model.AccountData) bool {
+		return len(data.Associations) == 2
+	})).Return(nil).Once()
+	assert.NoError(t, assocSvc.AssociateCharacter("201", "300"))
+
+	repo.AssertExpectations(t)
+	esi.AssertExpectations(t)
+}
+
+func TestUpdateAssociationRules_Invalid(t *testing.T) {
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, &testutil.MockAccountDataRepository{}, &testutil.MockESIService{})
+	assert.Error(t, assocSvc.UpdateAssociationRules(model.AssociationRules{MaxCharactersPerUser: -1}))
+}
+
+func inconsistentAccountData() model.AccountData {
+	character := func(id int64) model.CharacterIdentity {
+		return model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: id}}}
+	}
+	return model.AccountData{
+		Accounts: []model.Account{
+			{Name: "Main", ID: 99, Characters: []model.CharacterIdentity{character(1)}},
+			{Name: "Alt", ID: 20, Characters: []model.CharacterIdentity{character(4)}},
+			{Name: "Alt", ID: 0, Characters: []model.CharacterIdentity{character(5)}},
+		},
+		Associations: []model.Association{
+			{UserId: "10", CharId: "1", CharName: "Pilot"},
+			{UserId: "10", CharId: "1", CharName: "Pilot"},
+			{UserId: "10", CharId: "2", CharName: "Biomassed"},
+			{UserId: "30", CharId: "3", CharName: "Gone"},
+		},
+	}
+}
+
+func checkFixtures() (*testutil.MockAccountDataRepository, *testutil.MockESIService, []model.EveProfile) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacter", "1").Return(&model.CharacterResponse{Name: "Pilot"}, nil)
+	esi.On("GetCharacter", "2").Return((*model.CharacterResponse)(nil), flyErrors.NewCustomError(404, "not found"))
+	// unreachable is not removed
+	esi.On("GetCharacter", "3").Return((*model.CharacterResponse)(nil), fmt.Errorf("timeout"))
+	profiles := []model.EveProfile{{AvailableUserFiles: []model.UserFile{{UserId: "10"}}}}
+	return repo, esi, profiles
+}
+
+func TestCheckAssociations(t *testing.T) {
+	repo, esi, profiles := checkFixtures()
+	repo.On("FetchAccountData").Return(inconsistentAccountData(), nil)
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	issues, err := assocSvc.CheckAssociations(profiles)
+	require.NoError(t, err)
+
+	kinds := make(map[string]int)
+	for _, issue := range issues {
+		kinds[issue.Kind]++
+	}
+	assert.Equal(t, map[string]int{
+		model.AssociationDuplicate:        1,
+		model.AssociationRemovedCharacter: 1, // 2
+		model.AssociationOrphaned:         1, // 3, user file 30
+		model.AssociationAccountMismatch:  1, // Main is 10
+		model.AssociationDuplicateName:    1,
+	}, kinds)
+
+	// without profiles nothing is an orphan
+	issues, err = assocSvc.CheckAssociations(nil)
+	require.NoError(t, err)
+	for _, issue := range issues {
+		assert.NotEqual(t, model.AssociationOrphaned, issue.Kind)
+	}
+}
+
+func TestCheckAssociations_RuleViolations(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacter", mock.Anything).Return(&model.CharacterResponse{}, nil)
+	repo.On("FetchAccountData").Return(model.AccountData{
+		Associations: []model.Association{
+			{UserId: "10", CharId: "1"}, {UserId: "10", CharId: "2"}, {UserId: "20", CharId: "2"},
+		},
+		AssociationRules: model.AssociationRules{MaxCharactersPerUser: 1},
+	}, nil)
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	issues, err := assocSvc.CheckAssociations(nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, model.AssociationRuleViolation, issues[0].Kind)
+	assert.Equal(t, "10", issues[0].UserId)
+	assert.Equal(t, "2", issues[1].CharId)
+	assert.False(t, issues[1].Repairable)
+}
+
+func TestRepairAssociations(t *testing.T) {
+	repo, esi, _ := checkFixtures()
+	repo.On("FetchAccountData").Return(inconsistentAccountData(), nil).Once()
+	var saved model.AccountData
+	repo.On("SaveAccountData", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(model.AccountData)
+	}).Return(nil).Once()
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	repaired, err := assocSvc.RepairAssociations()
+	require.NoError(t, err)
+	assert.Len(t, repaired, 3)
+
+	// the orphan is left to be removed explicitly
+	assert.Equal(t, []model.Association{
+		{UserId: "10", CharId: "1", CharName: "Pilot"},
+		{UserId: "30", CharId: "3", CharName: "Gone"},
+	}, saved.Associations)
+	assert.Equal(t, int64(10), saved.Accounts[0].ID)
+	assert.Equal(t, int64(20), saved.Accounts[1].ID, "an account without associations keeps its ID")
+	// duplicate names are left to the user
+	assert.Equal(t, "Alt", saved.Accounts[2].Name)
+	repo.AssertExpectations(t)
+}
+
+func TestRemoveOrphanedAssociations(t *testing.T) {
+	repo, esi, profiles := checkFixtures()
+	repo.On("FetchAccountData").Return(model.AccountData{
+		Accounts: []model.Account{{Name: "Main", ID: 30, Characters: []model.CharacterIdentity{
+			{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 3}}},
+		}}},
+		Associations: []model.Association{
+			{UserId: "10", CharId: "1", CharName: "Pilot"},
+			{UserId: "30", CharId: "3", CharName: "Gone"},
+		},
+	}, nil).Once()
+	var saved model.AccountData
+	repo.On("SaveAccountData", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(model.AccountData)
+	}).Return(nil).Once()
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	_, err := assocSvc.RemoveOrphanedAssociations(nil, []model.Association{{UserId: "30", CharId: "3"}})
+	assert.Error(t, err, "without profiles every user file looks orphaned")
+
+	// user file 10 is in the profiles, so it is kept although it was asked for
+	removed, err := assocSvc.RemoveOrphanedAssociations(profiles, []model.Association{{UserId: "30", CharId: "3"}, {UserId: "10", CharId: "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Association{{UserId: "30", CharId: "3", CharName: "Gone"}}, removed)
+	assert.Equal(t, []model.Association{{UserId: "10", CharId: "1", CharName: "Pilot"}}, saved.Associations)
+	assert.Equal(t, int64(30), saved.Accounts[0].ID)
+	repo.AssertExpectations(t)
+}
+
+func TestRepairAssociations_KeepsIDsOfUnassociatedAccounts(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacter", "9").Return(&model.CharacterResponse{Name: "Pilot"}, nil)
+	character := func(id int64) model.CharacterIdentity {
+		return model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: id}}}
+	}
+	// new accounts are created with a timestamp ID before any user file is associated
+	repo.On("FetchAccountData").Return(model.AccountData{
+		Accounts: []model.Account{
+			{Name: "First", ID: 1700000000, Characters: []model.CharacterIdentity{character(1)}},
+			{Name: "Second", ID: 1700000060, Characters: []model.CharacterIdentity{character(2)}},
+		},
+		Associations: []model.Association{{UserId: "10", CharId: "9"}, {UserId: "10", CharId: "9"}},
+	}, nil).Once()
+	var saved model.AccountData
+	repo.On("SaveAccountData", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(model.AccountData)
+	}).Return(nil).Once()
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	repaired, err := assocSvc.RepairAssociations()
+	require.NoError(t, err)
+	require.Len(t, repaired, 1)
+	assert.Equal(t, model.AssociationDuplicate, repaired[0].Kind)
+	assert.Equal(t, int64(1700000000), saved.Accounts[0].ID)
+	assert.Equal(t, int64(1700000060), saved.Accounts[1].ID)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,163 @@
+package account
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// coWriteWindow is how far apart the client writes the user and char file when a character logs out
+const coWriteWindow = 10 * time.Second
+
+// SuggestAssociations infers associations for characters that have none from the profiles: the client
+// writes the char file and the user file of its account together on logout, so a user file that keeps
+// being written with a char file across profiles is likely its account.
+//
+// In every profile holding the char file, each user file written within coWriteWindow of it gets an
+// equal share of one match. The confidence is the lead of the best user file over the runner-up,
+// divided by the profiles holding the char file, so files written together by a sync or by two
+// accounts logging out at once count for little.
+func (assoc *associationService) SuggestAssociations(profiles []model.EveProfile) ([]model.AssociationSuggestion, error) {
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account data: %w", err)
+	}
+	associated := assoc.getAssociationMap(accountData.Associations)
+
+	type evidence struct {
+		score   float64
+		matches int
+	}
+	charProfiles := make(map[string]int)
+	charNames := make(map[string]string)
+	userNames := make(map[string]string)
+	scores := make(map[string]map[string]*evidence)
+
+	for _, profile := range profiles {
+		userTimes := make(map[string]time.Time)
+		for _, uf := range profile.AvailableUserFiles {
+			mtime, err := time.Parse(time.RFC3339, uf.Mtime)
+			if err != nil {
+				continue
+			}
+			userTimes[uf.UserId] = mtime
+			userNames[uf.UserId] = uf.Name
+		}
+
+		for _, cf := range profile.AvailableCharFiles {
+			if _, ok := associated[cf.CharId]; ok {
+				continue
+			}
+			mtime, err := time.Parse(time.RFC3339, cf.Mtime)
+			if err != nil {
+				continue
+			}
+			charProfiles[cf.CharId]++
+			charNames[cf.CharId] = cf.Name
+
+			var candidates []string
+			for userId, userTime := range userTimes {
+				if diff := mtime.Sub(userTime); diff <= coWriteWindow && diff >= -coWriteWindow {
+					candidates = append(candidates, userId)
+				}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+			if scores[cf.CharId] == nil {
+				scores[cf.CharId] = make(map[string]*evidence)
+			}
+			for _, userId := range candidates {
+				e, ok := scores[cf.CharId][userId]
+				if !ok {
+					e = &evidence{}
+					scores[cf.CharId][userId] = e
+				}
+				e.score += 1 / float64(len(candidates))
+				e.matches++
+			}
+		}
+	}
+
+	var suggestions []model.AssociationSuggestion
+	for charId, users := range scores {
+		ranked := make([]string, 0, len(users))
+		for userId := range users {
+			ranked = append(ranked, userId)
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if users[ranked[i]].score != users[ranked[j]].score {
+				return users[ranked[i]].score > users[ranked[j]].score
+			}
+			return ranked[i] < ranked[j]
+		})
+		bestUser, best, runnerUp := ranked[0], users[ranked[0]].score, 0.0
+		if len(ranked) > 1 {
+			runnerUp = users[ranked[1]].score
+		}
+		confidence := (best - runnerUp) / float64(charProfiles[charId])
+		if confidence <= 0 {
+			continue
+		}
+		suggestions = append(suggestions, model.AssociationSuggestion{
+			UserId:     bestUser,
+			UserName:   userNames[bestUser],
+			CharId:     charId,
+			CharName:   charNames[charId],
+			Confidence: confidence,
+			Matches:    users[bestUser].matches,
+			Profiles:   charProfiles[charId],
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CharId < suggestions[j].CharId
+	})
+
+	// the most likely characters take the free slots of a user file
+	used := make(map[string]int)
+	for _, a := range accountData.Associations {
+		used[a.UserId]++
+	}
+	result := make([]model.AssociationSuggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		if used[s.UserId] >= accountData.AssociationRules.CharacterLimit() {
+			continue
+		}
+		used[s.UserId]++
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// AcceptSuggestions associates every suggested character with its user file and saves once. Suggestions
+// that cannot be associated are returned with the reason.
+func (assoc *associationService) AcceptSuggestions(suggestions []model.Association) ([]model.Association, []model.AssociationRejection, error) {
+	accountData, err := assoc.accountRepo.FetchAccountData()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch account data: %w", err)
+	}
+
+	accepted := make([]model.Association, 0, len(suggestions))
+	rejected := make([]model.AssociationRejection, 0)
+	for _, s := range suggestions {
+		if err := assoc.associateInAccountData(&accountData, s.UserId, s.CharId); err != nil {
+			assoc.logger.Warnf("rejected suggested association of %s with %s: %v", s.CharId, s.UserId, err)
+			rejected = append(rejected, model.AssociationRejection{UserId: s.UserId, CharId: s.CharId, Reason: err.Error()})
+			continue
+		}
+		accepted = append(accepted, s)
+	}
+
+	if len(accepted) > 0 {
+		if err := assoc.accountRepo.SaveAccountData(accountData); err != nil {
+			return nil, nil, fmt.Errorf("failed to save updated account data: %w", err)
+		}
+	}
+	return accepted, rejected, nil
+}
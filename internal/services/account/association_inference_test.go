@@ -0,0 +1,100 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/account"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func mtime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func TestSuggestAssociations(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, &testutil.MockESIService{})
+
+	// user 20 has no free slot
+	repo.On("FetchAccountData").Return(model.AccountData{Associations: []model.Association{
+		{UserId: "20", CharId: "4"}, {UserId: "20", CharId: "5"}, {UserId: "20", CharId: "6"},
+	}}, nil)
+
+	played := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	synced := played.Add(24 * time.Hour)
+	profiles := []model.EveProfile{
+		{
+			Profile: "settings_Default",
+			AvailableUserFiles: []model.UserFile{
+				{UserId: "10", Name: "Main", Mtime: mtime(played)},
+				{UserId: "20", Name: "Alts", Mtime: mtime(played.Add(time.Hour))},
+			},
+			AvailableCharFiles: []model.CharFile{
+				{CharId: "1", Name: "Pilot", Mtime: mtime(played.Add(3 * time.Second))},
+				{CharId: "2", Name: "Hauler", Mtime: mtime(played.Add(time.Hour + time.Second))},
+				{CharId: "3", Name: "Idle", Mtime: mtime(played.Add(-2 * time.Hour))},
+				{CharId: "4", Name: "Known", Mtime: mtime(played.Add(time.Hour))},
+			},
+		},
+		{
+			// every file written at once by a sync
+			Profile: "settings_Copy",
+			AvailableUserFiles: []model.UserFile{
+				{UserId: "10", Name: "Main", Mtime: mtime(synced)},
+				{UserId: "20", Name: "Alts", Mtime: mtime(synced)},
+			},
+			AvailableCharFiles: []model.CharFile{
+				{CharId: "1", Name: "Pilot", Mtime: mtime(synced)},
+				{CharId: "2", Name: "Hauler", Mtime: mtime(synced)},
+			},
+		},
+	}
+
+	suggestions, err := assocSvc.SuggestAssociations(profiles)
+	require.NoError(t, err)
+	assert.Equal(t, []model.AssociationSuggestion{{
+		UserId:     "10",
+		UserName:   "Main",
+		CharId:     "1",
+		CharName:   "Pilot",
+		Confidence: 0.5,
+		Matches:    2,
+		Profiles:   2,
+	}}, suggestions)
+}
+
+func TestAcceptSuggestions(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	assocSvc := account.NewAssociationService(&testutil.MockLogger{}, repo, esi)
+
+	repo.On("FetchAccountData").Return(model.AccountData{
+		Accounts: []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{
+			{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"}}},
+		}}},
+		Associations: []model.Association{{UserId: "20", CharId: "2"}},
+	}, nil).Once()
+	esi.On("GetCharacter", "1").Return(&model.CharacterResponse{Name: "Pilot"}, nil).Once()
+	repo.On("SaveAccountData", mock.MatchedBy(func(data model.AccountData) bool {
+		return len(data.Associations) == 2 && data.Associations[1].CharName == "Pilot" && data.Accounts[0].ID == 10
+	})).Return(nil).Once()
+
+	accepted, rejected, err := assocSvc.AcceptSuggestions([]model.Association{
+		{UserId: "10", CharId: "1"},
+		{UserId: "10", CharId: "2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Association{{UserId: "10", CharId: "1"}}, accepted)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "2", rejected[0].CharId)
+	assert.Contains(t, rejected[0].Reason, "already associated")
+
+	repo.AssertExpectations(t)
+	esi.AssertExpectations(t)
+}
@@ -31,7 +31,7 @@ func (assoc *associationService) UpdateAssociationsAfterNewCharacter(account *mo
 		return err
 	}
 
-	updatedAssociations, err := assoc.syncAccountWithUserFileAndAssociations(account, charID, accountData.Associations)
+	updatedAssociations, err := assoc.syncAccountWithUserFileAndAssociations(account, charID, accountData.Associations, accountData.AssociationRules)
 	if err != nil {
 		return err
 	}
@@ -48,15 +48,19 @@ func (assoc *associationService) AssociateCharacter(userId, charId string) error
 	if err != nil {
 		return fmt.Errorf("failed to fetch account data: %w", err)
 	}
-	associations := accountData.Associations
-	associations, err = assoc.associateCharacter(userId, charId, associations)
-	if err != nil {
+	if err = assoc.associateInAccountData(&accountData, userId, charId); err != nil {
 		return err
 	}
-	accountData.Associations = associations
 
-	assoc.logger.Infof("assocations after associate character %v", associations)
+	if err = assoc.accountRepo.SaveAccountData(accountData); err != nil {
+		return fmt.Errorf("failed to save updated account data: %w", err)
+	}
 
+	return nil
+}
+
+// associateInAccountData associates a character with a user file and gives its account the user ID.
+func (assoc *associationService) associateInAccountData(accountData *model.AccountData, userId, charId string) error {
 	charIdInt, err := strconv.ParseInt(charId, 10, 64)
 	if err != nil {
 		return fmt.Errorf("invalid charId %s: %w", charId, err)
@@ -67,12 +71,20 @@ func (assoc *associationService) AssociateCharacter(userId, charId string) error
 		return fmt.Errorf("invalid userId %s: %w", userId, err)
 	}
 
+	associations, err := assoc.associateCharacter(userId, charId, accountData.Associations, accountData.AssociationRules)
+	if err != nil {
+		return err
+	}
+	accountData.Associations = associations
+
+	assoc.logger.Infof("assocations after associate character %v", associations)
+
 	foundAccount := assoc.findAccountByCharacterID(accountData.Accounts, charIdInt)
 	if foundAccount == nil {
 		assoc.logger.Infof("no matching account found for charId %s", charId)
 	} else if foundAccount.ID != userIdInt {
 		foundAccount.ID = userIdInt
-		updatedAssociations, err := assoc.associateMissingCharacters(foundAccount, userId, accountData.Associations)
+		updatedAssociations, err := assoc.associateMissingCharacters(foundAccount, userId, accountData.Associations, accountData.AssociationRules)
 		if err != nil {
 			assoc.logger.Warnf("failed to associate missing characters for account %s, userId %s: %v", foundAccount.Name, userId, err)
 		} else {
@@ -80,12 +92,7 @@ func (assoc *associationService) AssociateCharacter(userId, charId string) error
 		}
 	}
 
-	assoc.logger.Infof("assocations after assign missing %v", associations)
-
-	if err = assoc.accountRepo.SaveAccountData(accountData); err != nil {
-		return fmt.Errorf("failed to save updated account data: %w", err)
-	}
-
+	assoc.logger.Infof("assocations after assign missing %v", accountData.Associations)
 	return nil
 }
 
@@ -190,19 +197,19 @@ func (assoc *associationService) findAccountByCharacterID(accounts []model.Accou
 	return nil
 }
 
-func (assoc *associationService) associateCharacter(userId string, charId string, associations []model.Association) ([]model.Association, error) {
-	// Enforce a maximum of 3 characters per user
+func (assoc *associationService) associateCharacter(userId string, charId string, associations []model.Association, rules model.AssociationRules) ([]model.Association, error) {
+	// Enforce a maximum of characters per user
 	userAssociations := 0
 	for _, a := range associations {
 		if a.UserId == userId {
 			userAssociations++
 		}
 	}
-	if userAssociations >= 3 {
-		return nil, fmt.Errorf("user ID %s already has the maximum of 3 associated characters", userId)
+	if limit := rules.CharacterLimit(); userAssociations >= limit {
+		return nil, fmt.Errorf("user ID %s already has the maximum of %d associated characters", userId, limit)
 	}
 
-	if err := checkForExistingAssociation(associations, charId); err != nil {
+	if err := checkForExistingAssociation(associations, userId, charId, rules); err != nil {
 		assoc.logger.Errorf("already associated")
 		return nil, err
 	}
@@ -222,23 +229,23 @@ func (assoc *associationService) associateCharacter(userId string, charId string
 	return associations, nil
 }
 
-func checkForExistingAssociation(associations []model.Association, charId string) error {
+func checkForExistingAssociation(associations []model.Association, userId, charId string, rules model.AssociationRules) error {
 	for _, assoc := range associations {
-		if assoc.CharId == charId {
+		if assoc.CharId == charId && (assoc.UserId == userId || !rules.AllowSharedCharacters) {
 			return fmt.Errorf("character ID %s is already associated with User ID %s", charId, assoc.UserId)
 		}
 	}
 	return nil
 }
 
-func (assoc *associationService) associateMissingCharacters(foundAccount *model.Account, userId string, associations []model.Association) ([]model.Association, error) {
+func (assoc *associationService) associateMissingCharacters(foundAccount *model.Account, userId string, associations []model.Association, rules model.AssociationRules) ([]model.Association, error) {
 	assocCharIds := assoc.getAssociationMap(associations)
 
 	for _, ch := range foundAccount.Characters {
 		cidStr := fmt.Sprintf("%d", ch.Character.CharacterID)
-		err := checkForExistingAssociation(associations, cidStr)
+		err := checkForExistingAssociation(associations, userId, cidStr, rules)
 		if _, hasId := assocCharIds[cidStr]; !hasId && err == nil {
-			updatedAssociations, err := assoc.associateCharacter(userId, cidStr, associations)
+			updatedAssociations, err := assoc.associateCharacter(userId, cidStr, associations, rules)
 			if err != nil {
 				assoc.logger.Warnf("failed to associate character %d: %v", ch.Character.CharacterID, err)
 			} else {
@@ -257,6 +264,7 @@ func (assoc *associationService) syncAccountWithUserFileAndAssociations(
 	account *model.Account,
 	charID int64,
 	associations []model.Association,
+	rules model.AssociationRules,
 ) ([]model.Association, error) {
 	foundUserID, err := assoc.getUserIdWithCharId(associations, strconv.FormatInt(charID, 10))
 	if err != nil {
@@ -268,7 +276,7 @@ func (assoc *associationService) syncAccountWithUserFileAndAssociations(
 	}
 	assoc.logger.Infof("associated user: %s with account %s", foundUserID, account.Name)
 
-	updatedAssociations, err := assoc.associateMissingCharacters(account, foundUserID, associations)
+	updatedAssociations, err := assoc.associateMissingCharacters(account, foundUserID, associations, rules)
 	if err != nil {
 		assoc.logger.Warnf("failed to associate missing characters for account %s, userId %s: %v", account.Name, foundUserID, err)
 		return associations, nil // intentionally not returning the error to allow the account update to save
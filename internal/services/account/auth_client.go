@@ -14,13 +14,14 @@ import (
 
 	"golang.org/x/oauth2"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
 
 var _ interfaces.AuthClient = (*authClient)(nil)
 
 const (
-	tokenURL        = "https://login.eveonline.com/v2/oauth/token"
 	requestTimeout  = 10 * time.Second
 	contentType     = "application/x-www-form-urlencoded"
 	authorization   = "Authorization"
@@ -34,26 +35,19 @@ type authClient struct {
 	client *http.Client
 }
 
-// NewAuthClient initializes and returns an AuthClient implementation.
-func NewAuthClient(logger interfaces.Logger, clientID, clientSecret, callbackURL string) interfaces.AuthClient {
+// NewAuthClient initializes and returns an AuthClient implementation. ssoBaseURL is the EVE SSO
+// host, https://login.eveonline.com outside of tests.
+func NewAuthClient(logger interfaces.Logger, clientID, clientSecret, callbackURL, ssoBaseURL string) interfaces.AuthClient {
 	return &authClient{
 		logger: logger,
 		config: &oauth2.Config{
 			ClientID:     clientID,
 			ClientSecret: clientSecret,
 			RedirectURL:  callbackURL,
-			Scopes: []string{
-				"publicData",
-				"esi-location.read_location.v1",
-				"esi-skills.read_skills.v1",
-				"esi-clones.read_clones.v1",
-				"esi-clones.read_implants.v1",
-				"esi-skills.read_skillqueue.v1",
-				"esi-characters.read_corporation_roles.v1",
-			},
+			Scopes:       model.EsiScopes,
 			Endpoint: oauth2.Endpoint{
-				AuthURL:  "https://login.eveonline.com/v2/oauth/authorize",
-				TokenURL: tokenURL,
+				AuthURL:  ssoBaseURL + "/v2/oauth/authorize",
+				TokenURL: ssoBaseURL + "/v2/oauth/token",
 			},
 		},
 		// Inject a custom HTTP client if needed, otherwise use default
@@ -82,7 +76,7 @@ func (a *authClient) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	data.Set("grant_type", "refresh_token")
 	data.Set("refresh_token", refreshToken)
 
-	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequest(http.MethodPost, a.config.Endpoint.TokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		a.logger.Errorf("Failed to create request to refresh token: %v", err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -107,6 +101,14 @@ func (a *authClient) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 		bodyString := string(bodyBytes)
 
 		a.logger.Warnf("Received non-OK status code %d for request to refresh token. Response body: %s", resp.StatusCode, bodyString)
+		// only invalid_grant is about the refresh token, SSO answers it once the token was revoked or has
+		// expired. invalid_client and malformed requests are configuration errors every token would hit.
+		var oauthErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(bodyBytes, &oauthErr) == nil && oauthErr.Error == "invalid_grant" {
+			return nil, fmt.Errorf("%w: %s", flyErrors.ErrTokenRevoked, bodyString)
+		}
 		return nil, fmt.Errorf("received non-OK status code %d: %s", resp.StatusCode, bodyString)
 	}
 
@@ -115,6 +117,10 @@ func (a *authClient) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 		a.logger.Errorf("Failed to decode response body: %v", decodeErr)
 		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
 	}
+	// SSO sends the lifetime of the access token, not when it expires
+	if token.Expiry.IsZero() && token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
 
 	return &token, nil
 }
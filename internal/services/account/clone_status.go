@@ -0,0 +1,134 @@
+package account
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// alphaMaxSpPerHour is above the fastest alpha training rate (half of omega, with alpha-usable implants)
+// and below the slowest omega rate, so a queue training faster than this must be omega.
+const alphaMaxSpPerHour = 1440
+
+// omegaOnlySkills cannot be used at any level by an alpha clone
+var omegaOnlySkills = map[int32]string{
+	3456:  "Jump Drive Operation",
+	12092: "Interceptors",
+	12093: "Covert Ops",
+	12096: "Logistics Cruisers",
+	16591: "Heavy Assault Cruisers",
+	20342: "Advanced Spaceship Command",
+	20533: "Capital Ships",
+	22761: "Recon Ships",
+	23950: "Command Ships",
+	24242: "Infomorph Psychology",
+	28656: "Black Ops",
+	28667: "Marauders",
+}
+
+// cloneStateEvidence is what a single character's ESI data says about its clone state
+type cloneStateEvidence struct {
+	status model.AccountStatus
+	reason string
+}
+
+// detectCloneState looks for facts in a character's skills and queue that only hold for one clone state.
+// It returns nil when the data is consistent with both.
+func detectCloneState(char model.Character, now time.Time) *cloneStateEvidence {
+	// ESI reports active levels below trained levels while the clone is alpha restricted
+	limited := 0
+	for _, skill := range char.Skills {
+		if skill.ActiveSkillLevel < skill.TrainedSkillLevel {
+			limited++
+		}
+	}
+	if limited > 0 {
+		return &cloneStateEvidence{
+			status: model.Alpha,
+			reason: fmt.Sprintf("%s has %d skills active below their trained level", char.CharacterName, limited),
+		}
+	}
+
+	for _, skill := range char.Skills {
+		if name, ok := omegaOnlySkills[skill.SkillID]; ok && skill.ActiveSkillLevel > 0 {
+			return &cloneStateEvidence{
+				status: model.Omega,
+				reason: fmt.Sprintf("%s can use omega-only skill %s", char.CharacterName, name),
+			}
+		}
+	}
+
+	if rate, ok := currentTrainingRate(char.SkillQueue, now); ok {
+		if rate > alphaMaxSpPerHour {
+			return &cloneStateEvidence{
+				status: model.Omega,
+				reason: fmt.Sprintf("%s is training at %.0f SP/hour, faster than an alpha clone can", char.CharacterName, rate),
+			}
+		}
+		if char.TotalSP > AlphaMaxSp {
+			return &cloneStateEvidence{
+				status: model.Omega,
+				reason: fmt.Sprintf("%s is training beyond the alpha limit of %d SP", char.CharacterName, AlphaMaxSp),
+			}
+		}
+	}
+
+	return nil
+}
+
+// currentTrainingRate returns the SP per hour of the queue entry in training at now.
+func currentTrainingRate(queue []model.SkillQueue, now time.Time) (float64, bool) {
+	for _, q := range queue {
+		if q.StartDate == nil || q.FinishDate == nil || q.StartDate.After(now) || !q.FinishDate.After(now) {
+			continue
+		}
+		hours := q.FinishDate.Sub(*q.StartDate).Hours()
+		sp := float64(q.LevelEndSP - q.TrainingStartSP)
+		if hours <= 0 || sp <= 0 {
+			return 0, false
+		}
+		return sp / hours, true
+	}
+	return 0, false
+}
+
+// classifyAccount applies the evidence from every character to the account. Alpha evidence wins
+// because restricted skills are only reported while the account is actually alpha. Without any
+// evidence an omega account turns alpha once its omega expiry has passed, otherwise the previous
+// status is kept.
+func classifyAccount(account *model.Account, now time.Time) {
+	var omega *cloneStateEvidence
+	for _, charIdentity := range account.Characters {
+		evidence := detectCloneState(charIdentity.Character, now)
+		if evidence == nil {
+			continue
+		}
+		if evidence.status == model.Alpha {
+			account.Status = model.Alpha
+			account.StatusReason = evidence.reason
+			return
+		}
+		if omega == nil {
+			omega = evidence
+		}
+	}
+
+	if omega != nil {
+		account.Status = model.Omega
+		account.StatusReason = omega.reason
+		return
+	}
+
+	// an idle lapsed account shows nothing, the expiry the user entered is all there is to go by
+	if account.Status != model.Omega {
+		return
+	}
+	switch {
+	case account.OmegaExpiry != nil && !account.OmegaExpiry.After(now):
+		account.Status = model.Alpha
+		account.StatusReason = fmt.Sprintf("Omega expired on %s and no character shows omega activity", account.OmegaExpiry.Format("2006-01-02"))
+	case account.OmegaExpiry == nil:
+		account.StatusReason = "Not verified, no character shows omega activity"
+	}
+}
@@ -0,0 +1,95 @@
+package account
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// expiryWarningWindow is how far ahead of an omega or MCT expiry the dashboard starts warning
+const expiryWarningWindow = 7 * 24 * time.Hour
+
+// SetAccountExpiry records when the omega subscription of an account runs out, nil clears it.
+func (a *accountService) SetAccountExpiry(accountID int64, expiry *time.Time) error {
+	accountData, err := a.accountRepo.FetchAccountData()
+	if err != nil {
+		return fmt.Errorf("error fetching account data: %w", err)
+	}
+
+	for i := range accountData.Accounts {
+		if accountData.Accounts[i].ID == accountID {
+			accountData.Accounts[i].OmegaExpiry = expiry
+			if err := a.accountRepo.SaveAccountData(accountData); err != nil {
+				return fmt.Errorf("failed to save account data: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("account not found")
+}
+
+// SetCharacterMCTExpiry records when the MCT slot used by a character runs out, nil clears it.
+func (a *accountService) SetCharacterMCTExpiry(characterID int64, expiry *time.Time) error {
+	accountData, err := a.accountRepo.FetchAccountData()
+	if err != nil {
+		return fmt.Errorf("error fetching account data: %w", err)
+	}
+
+	for i := range accountData.Accounts {
+		for j := range accountData.Accounts[i].Characters {
+			if accountData.Accounts[i].Characters[j].Character.CharacterID == characterID {
+				accountData.Accounts[i].Characters[j].MCTExpiry = expiry
+				if err := a.accountRepo.SaveAccountData(accountData); err != nil {
+					return fmt.Errorf("failed to save account data: %w", err)
+				}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("character not found")
+}
+
+// GetExpiryWarnings lists the omega subscriptions and MCT slots that expire within the warning
+// window, including ones that already expired and have not been renewed, soonest first.
+func (a *accountService) GetExpiryWarnings(accounts []model.Account) []model.ExpiryWarning {
+	now := time.Now()
+	warnings := make([]model.ExpiryWarning, 0)
+
+	for _, account := range accounts {
+		if account.OmegaExpiry != nil && account.OmegaExpiry.Before(now.Add(expiryWarningWindow)) {
+			warnings = append(warnings, model.ExpiryWarning{
+				Kind:        model.ExpiryOmega,
+				AccountID:   account.ID,
+				AccountName: account.Name,
+				Expires:     *account.OmegaExpiry,
+				DaysLeft:    daysUntil(now, *account.OmegaExpiry),
+			})
+		}
+
+		for _, charIdentity := range account.Characters {
+			if charIdentity.MCTExpiry == nil || !charIdentity.MCTExpiry.Before(now.Add(expiryWarningWindow)) {
+				continue
+			}
+			warnings = append(warnings, model.ExpiryWarning{
+				Kind:          model.ExpiryMCT,
+				AccountID:     account.ID,
+				AccountName:   account.Name,
+				CharacterID:   charIdentity.Character.CharacterID,
+				CharacterName: charIdentity.Character.CharacterName,
+				Expires:       *charIdentity.MCTExpiry,
+				DaysLeft:      daysUntil(now, *charIdentity.MCTExpiry),
+			})
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Expires.Before(warnings[j].Expires) })
+	return warnings
+}
+
+// daysUntil rounds down so an expiry later today reports 0 days left.
+func daysUntil(now, t time.Time) int {
+	return int(math.Floor(t.Sub(now).Hours() / 24))
+}
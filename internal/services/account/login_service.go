@@ -21,18 +21,38 @@ func NewLoginService(logger interfaces.Logger, loginRepo interfaces.LoginReposit
 	}
 }
 
-func (l *loginService) GenerateAndStoreInitialState(value string) (string, error) {
+func (l *loginService) GenerateAndStoreInitialState(accountName, server string) (string, error) {
 	state, err := persist.GenerateRandomString(16)
 	if err != nil {
 		return "", err
 	}
 	l.loginRepo.Set(state, &model.AuthStatus{
-		AccountName:      value,
+		AccountName:      accountName,
 		CallBackComplete: false,
+		Server:           server,
 	})
 	return state, nil
 }
 
+func (l *loginService) GenerateReauthState(characterID int64, accountName, server string) (string, error) {
+	state, err := l.GenerateAndStoreInitialState(accountName, server)
+	if err != nil {
+		return "", err
+	}
+	authStatus, _ := l.loginRepo.Get(state)
+	authStatus.ReauthCharacterID = characterID
+	l.loginRepo.Set(state, authStatus)
+	return state, nil
+}
+
+func (l *loginService) ResolveReauthCharacterByState(state string) int64 {
+	authStatus, ok := l.loginRepo.Get(state)
+	if !ok {
+		return 0
+	}
+	return authStatus.ReauthCharacterID
+}
+
 func (l *loginService) ResolveAccountAndStatusByState(state string) (string, bool, bool) {
 	authStatus, ok := l.loginRepo.Get(state)
 	if !ok {
@@ -41,6 +61,14 @@ func (l *loginService) ResolveAccountAndStatusByState(state string) (string, boo
 	return authStatus.AccountName, authStatus.CallBackComplete, true
 }
 
+func (l *loginService) ResolveServerByState(state string) (string, bool) {
+	authStatus, ok := l.loginRepo.Get(state)
+	if !ok {
+		return "", false
+	}
+	return authStatus.Server, true
+}
+
 func (l *loginService) UpdateStateStatusAfterCallBack(state string) error {
 	authStatus, ok := l.loginRepo.Get(state)
 	if !ok {
@@ -0,0 +1,127 @@
+package account
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// tokenRefreshFailureLimit is how many account refreshes in a row may fail to renew a token before it is
+// flagged, a single failure is usually SSO being briefly unreachable
+const tokenRefreshFailureLimit = 3
+
+// tokenScopes returns the scopes EVE SSO granted, read from the scp claim of the access token. It is false
+// for tokens that are not JWTs.
+func tokenScopes(token *oauth2.Token) ([]string, bool) {
+	parts := strings.Split(token.AccessToken, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, false
+	}
+	var claims struct {
+		Scp json.RawMessage `json:"scp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Scp == nil {
+		return nil, false
+	}
+
+	// a single scope is a string, more are an array
+	var scopes []string
+	if err := json.Unmarshal(claims.Scp, &scopes); err == nil {
+		return scopes, true
+	}
+	var scope string
+	if err := json.Unmarshal(claims.Scp, &scope); err == nil {
+		return []string{scope}, true
+	}
+	return nil, false
+}
+
+// updateTokenState sets the token state of a character after its data was refreshed or its token could
+// not be renewed.
+func updateTokenState(identity *model.CharacterIdentity) {
+	identity.MissingScopes = nil
+	if identity.TokenRevoked {
+		identity.TokenState = model.TokenStateRevoked
+		return
+	}
+
+	if granted, ok := tokenScopes(&identity.Token); ok {
+		for _, scope := range model.EsiScopes {
+			if !slices.Contains(granted, scope) {
+				identity.MissingScopes = append(identity.MissingScopes, scope)
+			}
+		}
+		if len(identity.MissingScopes) > 0 {
+			identity.TokenState = model.TokenStateMissingScopes
+			return
+		}
+	}
+
+	if identity.RefreshFailures >= tokenRefreshFailureLimit {
+		identity.TokenState = model.TokenStateExpiring
+		return
+	}
+	identity.TokenState = model.TokenStateValid
+}
+
+// GetTokenWarnings returns the characters whose token is not valid.
+func (a *accountService) GetTokenWarnings(accounts []model.Account) []model.TokenWarning {
+	warnings := make([]model.TokenWarning, 0)
+	for _, account := range accounts {
+		for _, ch := range account.Characters {
+			if ch.TokenState == "" || ch.TokenState == model.TokenStateValid {
+				continue
+			}
+			warnings = append(warnings, model.TokenWarning{
+				AccountName:   account.Name,
+				CharacterID:   ch.Character.CharacterID,
+				CharacterName: ch.Character.CharacterName,
+				State:         ch.TokenState,
+				MissingScopes: ch.MissingScopes,
+			})
+		}
+	}
+	return warnings
+}
+
+// ReauthorizeCharacter replaces the token of a character that logged in again, wherever its account is.
+func (a *accountService) ReauthorizeCharacter(characterID int64, server string, token *oauth2.Token) error {
+	accountData, err := a.accountRepo.FetchAccountData()
+	if err != nil {
+		return fmt.Errorf("error fetching account data: %w", err)
+	}
+
+	for i := range accountData.Accounts {
+		account := &accountData.Accounts[i]
+		j := characterIndex(account, characterID)
+		if j < 0 {
+			continue
+		}
+		if accountServer, _ := model.NormalizeServer(account.Server); accountServer != server {
+			return fmt.Errorf("account %s is on %s, not %s", account.Name, accountServer, server)
+		}
+
+		identity := &account.Characters[j]
+		identity.Token = *token
+		identity.TokenRevoked = false
+		identity.RefreshFailures = 0
+		updateTokenState(identity)
+		a.logger.Infof("replaced the token of %s in account %s", identity.Character.CharacterName, account.Name)
+
+		if err := a.accountRepo.SaveAccountData(accountData); err != nil {
+			return fmt.Errorf("failed to save account data: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("character not found")
+}
@@ -0,0 +1,118 @@
+package account_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/account"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+// jwtToken returns a token whose access token carries scp like EVE SSO access tokens, unsigned.
+func jwtToken(t *testing.T, scp interface{}) oauth2.Token {
+	payload, err := json.Marshal(map[string]interface{}{"scp": scp, "sub": "CHARACTER:EVE:1"})
+	require.NoError(t, err)
+	access := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+	return oauth2.Token{AccessToken: access, RefreshToken: "refresh", Expiry: time.Now().Add(20 * time.Minute)}
+}
+
+func identity(id int64, token oauth2.Token) model.CharacterIdentity {
+	return model.CharacterIdentity{Token: token, Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: id, CharacterName: fmt.Sprintf("Char%d", id)}}}
+}
+
+func TestRefreshAccountData_TokenStates(t *testing.T) {
+	repo := &testutil.MockAccountDataRepository{}
+	esi := &testutil.MockESIService{}
+	charSvc := &testutil.MockCharacterService{}
+	svc := account.NewAccountService(&testutil.MockLogger{}, repo, esi, &testutil.MockAssociationService{})
+
+	full := identity(1, jwtToken(t, model.EsiScopes))
+	// logged in before the app asked for assets
+	withoutAssets := identity(2, jwtToken(t, []string{"publicData", "esi-location.read_location.v1", "esi-skills.read_skills.v1",
+		"esi-clones.read_clones.v1", "esi-clones.read_implants.v1", "esi-skills.read_skillqueue.v1",
+		"esi-characters.read_corporation_roles.v1", "esi-wallet.read_character_wallet.v1", "esi-universe.read_structures.v1"}))
+	single := identity(3, jwtToken(t, "publicData"))
+	revoked := identity(4, jwtToken(t, model.EsiScopes))
+	// an expired access token while ESI was unreachable says nothing about the token
+	unreachable := identity(5, oauth2.Token{AccessToken: "opaque", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)})
+	unreachable.TokenState = model.TokenStateValid
+	opaque := identity(6, oauth2.Token{AccessToken: "opaque", RefreshToken: "refresh", Expiry: time.Now().Add(20 * time.Minute)})
+	// a token that could not be renewed on the previous refreshes either
+	unrenewable := identity(7, oauth2.Token{AccessToken: "opaque", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)})
+	unrenewable.RefreshFailures = 2
+	// a first failure to renew is not flagged yet
+	renewFailedOnce := identity(8, oauth2.Token{AccessToken: "opaque", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)})
+	renewFailedOnce.TokenState = model.TokenStateValid
+
+	accounts := []model.Account{{Name: "Acc", Characters: []model.CharacterIdentity{full, withoutAssets, single, revoked, unreachable, opaque, unrenewable, renewFailedOnce}}}
+	repo.On("FetchAccountData").Return(model.AccountData{Accounts: accounts}, nil).Once()
+	for _, ch := range []model.CharacterIdentity{full, withoutAssets, single, opaque} {
+		ch := ch
+		charSvc.On("ProcessIdentity", mock.MatchedBy(func(c *model.CharacterIdentity) bool {
+			return c.Character.CharacterID == ch.Character.CharacterID
+		}), "").Return(&ch, nil).Once()
+	}
+	charSvc.On("ProcessIdentity", mock.MatchedBy(func(c *model.CharacterIdentity) bool { return c.Character.CharacterID == 4 }), "").
+		Return((*model.CharacterIdentity)(nil), fmt.Errorf("failed to get user info: %w", flyErrors.ErrTokenRevoked)).Once()
+	charSvc.On("ProcessIdentity", mock.MatchedBy(func(c *model.CharacterIdentity) bool { return c.Character.CharacterID == 5 }), "").
+		Return((*model.CharacterIdentity)(nil), fmt.Errorf("failed to get user info: timeout")).Once()
+	charSvc.On("ProcessIdentity", mock.MatchedBy(func(c *model.CharacterIdentity) bool { return c.Character.CharacterID >= 7 }), "").
+		Return((*model.CharacterIdentity)(nil), fmt.Errorf("failed to get user info: %w", flyErrors.ErrTokenRefreshFailed)).Twice()
+	repo.On("SaveAccountData", mock.Anything).Return(nil).Once()
+	esi.On("SaveEsiCache").Return(nil).Once()
+
+	data, err := svc.RefreshAccountData(charSvc)
+	require.NoError(t, err)
+	chars := data.Accounts[0].Characters
+	assert.Equal(t, model.TokenStateValid, chars[0].TokenState)
+	assert.Equal(t, model.TokenStateMissingScopes, chars[1].TokenState)
+	assert.Equal(t, []string{"esi-assets.read_assets.v1"}, chars[1].MissingScopes)
+	assert.Equal(t, model.TokenStateMissingScopes, chars[2].TokenState)
+	assert.Len(t, chars[2].MissingScopes, len(model.EsiScopes)-1)
+	assert.Equal(t, model.TokenStateRevoked, chars[3].TokenState)
+	assert.Equal(t, model.TokenStateValid, chars[4].TokenState, "the last state is kept")
+	assert.Equal(t, model.TokenStateValid, chars[5].TokenState, "scopes of opaque tokens are unknown")
+	assert.Equal(t, model.TokenStateExpiring, chars[6].TokenState)
+	assert.Equal(t, 3, chars[6].RefreshFailures)
+	assert.Equal(t, model.TokenStateValid, chars[7].TokenState)
+	assert.Equal(t, 1, chars[7].RefreshFailures)
+
+	warnings := svc.GetTokenWarnings(data.Accounts)
+	require.Len(t, warnings, 4)
+	assert.Equal(t, model.TokenWarning{AccountName: "Acc", CharacterID: 2, CharacterName: "Char2",
+		State: model.TokenStateMissingScopes, MissingScopes: []string{"esi-assets.read_assets.v1"}}, warnings[0])
+}
+
+func TestReauthorizeCharacter(t *testing.T) {
+	old := identity(1, oauth2.Token{AccessToken: "old", RefreshToken: "old"})
+	old.TokenRevoked = true
+	old.TokenState = model.TokenStateRevoked
+	svc, store, _ := newStoredAccountService(t, model.AccountData{Accounts: []model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{old, identity(2, oauth2.Token{})}},
+	}})
+
+	token := jwtToken(t, model.EsiScopes)
+	require.NoError(t, svc.ReauthorizeCharacter(1, model.ServerTranquility, &token))
+
+	data, err := store.FetchAccountData()
+	require.NoError(t, err)
+	require.Len(t, data.Accounts, 1)
+	require.Len(t, data.Accounts[0].Characters, 2)
+	reauthorized := data.Accounts[0].Characters[0]
+	assert.Equal(t, token.AccessToken, reauthorized.Token.AccessToken)
+	assert.False(t, reauthorized.TokenRevoked)
+	assert.Equal(t, model.TokenStateValid, reauthorized.TokenState)
+
+	assert.EqualError(t, svc.ReauthorizeCharacter(1, model.ServerSingularity, &token), "account Main is on tranquility, not singularity")
+	assert.EqualError(t, svc.ReauthorizeCharacter(3, model.ServerTranquility, &token), "character not found")
+}
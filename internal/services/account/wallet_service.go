@@ -0,0 +1,197 @@
+package account
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.WalletService = (*walletService)(nil)
+
+const (
+	// balanceHistoryRetention is how long balance snapshots are kept, the last one is kept regardless
+	balanceHistoryRetention = 365 * 24 * time.Hour
+	// maxJournalEntries is how many journal entries are kept per character
+	maxJournalEntries = 1000
+)
+
+type walletService struct {
+	logger     interfaces.Logger
+	esi        interfaces.ESIService
+	walletRepo interfaces.WalletRepository
+	mut        sync.Mutex // held while the wallet data is read, changed and saved
+	syncing    atomic.Bool
+}
+
+func NewWalletService(logger interfaces.Logger, esi interfaces.ESIService, walletRepo interfaces.WalletRepository) interfaces.WalletService {
+	return &walletService{
+		logger:     logger,
+		esi:        esi,
+		walletRepo: walletRepo,
+	}
+}
+
+func (w *walletService) RefreshWallets(accounts []model.Account) model.WalletSummary {
+	summary := summarizeWallets(accounts)
+
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	data, err := w.walletRepo.FetchWalletData()
+	if err != nil {
+		w.logger.Warnf("Failed to load wallet data: %v", err)
+		return summary
+	}
+
+	now := time.Now()
+	changed := false
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+			if char.WalletBalance == nil {
+				continue
+			}
+			if history, ok := appendSnapshot(data.History[char.CharacterID], *char.WalletBalance, now); ok {
+				data.History[char.CharacterID] = history
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		if err := w.walletRepo.SaveWalletData(data); err != nil {
+			w.logger.Warnf("Failed to save wallet data: %v", err)
+		}
+	}
+	return summary
+}
+
+func (w *walletService) SyncJournals(accounts []model.Account) {
+	if !w.syncing.CompareAndSwap(false, true) {
+		w.logger.Debugf("wallet journals are already being synced")
+		return
+	}
+	defer w.syncing.Store(false)
+
+	fetched := make(map[int64][]model.WalletJournalEntry)
+	for _, account := range accounts {
+		esi, err := w.esi.ForServer(account.Server)
+		if err != nil {
+			continue
+		}
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+			// without a balance the wallet scope has not been granted
+			if char.WalletBalance == nil {
+				continue
+			}
+			token := charIdentity.Token
+			journal, err := esi.GetCharacterWalletJournal(char.CharacterID, &token)
+			if err != nil {
+				w.logger.Warnf("Failed to get wallet journal for character %d: %v", char.CharacterID, err)
+				continue
+			}
+			fetched[char.CharacterID] = journal
+		}
+	}
+	if len(fetched) == 0 {
+		return
+	}
+
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	data, err := w.walletRepo.FetchWalletData()
+	if err != nil {
+		w.logger.Warnf("Failed to load wallet data: %v", err)
+		return
+	}
+	for characterID, journal := range fetched {
+		data.Journals[characterID] = mergeJournal(data.Journals[characterID], journal)
+	}
+	if err := w.walletRepo.SaveWalletData(data); err != nil {
+		w.logger.Warnf("Failed to save wallet data: %v", err)
+	}
+}
+
+func (w *walletService) GetBalanceHistory(characterID int64) ([]model.BalanceSnapshot, error) {
+	data, err := w.walletRepo.FetchWalletData()
+	if err != nil {
+		return nil, err
+	}
+	if history, ok := data.History[characterID]; ok {
+		return history, nil
+	}
+	return []model.BalanceSnapshot{}, nil
+}
+
+func (w *walletService) GetJournal(characterID int64) ([]model.WalletJournalEntry, error) {
+	data, err := w.walletRepo.FetchWalletData()
+	if err != nil {
+		return nil, err
+	}
+	if journal, ok := data.Journals[characterID]; ok {
+		return journal, nil
+	}
+	return []model.WalletJournalEntry{}, nil
+}
+
+// summarizeWallets adds up the known balances per account, characters without the wallet scope are left out.
+func summarizeWallets(accounts []model.Account) model.WalletSummary {
+	summary := model.WalletSummary{Accounts: make([]model.AccountWallet, 0, len(accounts))}
+	for _, account := range accounts {
+		wallet := model.AccountWallet{AccountID: account.ID, AccountName: account.Name}
+		for _, charIdentity := range account.Characters {
+			if balance := charIdentity.Character.WalletBalance; balance != nil {
+				wallet.Balance += *balance
+				wallet.Characters++
+			}
+		}
+		summary.Total += wallet.Balance
+		summary.Accounts = append(summary.Accounts, wallet)
+	}
+	return summary
+}
+
+// appendSnapshot records the balance when it changed, dropping snapshots past the retention. It is false
+// when there was nothing to record.
+func appendSnapshot(history []model.BalanceSnapshot, balance float64, now time.Time) ([]model.BalanceSnapshot, bool) {
+	if n := len(history); n > 0 && history[n-1].Balance == balance {
+		return history, false
+	}
+	cutoff := now.Add(-balanceHistoryRetention)
+	dropped := 0
+	for dropped < len(history)-1 && history[dropped].Time.Before(cutoff) {
+		dropped++
+	}
+	history = append(history[dropped:len(history):len(history)], model.BalanceSnapshot{Time: now, Balance: balance})
+	return history, true
+}
+
+// mergeJournal adds the fetched entries to the stored ones by ID, newest first, keeping at most maxJournalEntries.
+func mergeJournal(stored, fetched []model.WalletJournalEntry) []model.WalletJournalEntry {
+	byID := make(map[int64]model.WalletJournalEntry, len(stored)+len(fetched))
+	for _, entry := range stored {
+		byID[entry.ID] = entry
+	}
+	for _, entry := range fetched {
+		byID[entry.ID] = entry
+	}
+
+	merged := make([]model.WalletJournalEntry, 0, len(byID))
+	for _, entry := range byID {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if !merged[i].Date.Equal(merged[j].Date) {
+			return merged[i].Date.After(merged[j].Date)
+		}
+		return merged[i].ID > merged[j].ID
+	})
+	if len(merged) > maxJournalEntries {
+		merged = merged[:maxJournalEntries]
+	}
+	return merged
+}
@@ -0,0 +1,103 @@
+package account_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	persistAccount "github.com/guarzo/canifly/internal/persist/account"
+	"github.com/guarzo/canifly/internal/services/account"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func walletCharacter(id int64, balance *float64) model.CharacterIdentity {
+	return model.CharacterIdentity{Character: model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: id},
+		WalletBalance:    balance,
+	}}
+}
+
+func isk(v float64) *float64 { return &v }
+
+func TestWalletService_RefreshWallets(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	esi := &testutil.MockESIService{}
+	store := persistAccount.NewWalletStore(logger, persist.OSFileSystem{}, t.TempDir())
+	svc := account.NewWalletService(logger, esi, store)
+
+	accounts := []model.Account{
+		{ID: 1, Name: "Main", Characters: []model.CharacterIdentity{walletCharacter(10, isk(1000)), walletCharacter(11, nil)}},
+		{ID: 2, Name: "Alt", Characters: []model.CharacterIdentity{walletCharacter(20, isk(250.5))}},
+	}
+
+	older := model.WalletJournalEntry{ID: 1, Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), RefType: "bounty_prizes", Amount: 500}
+	newer := model.WalletJournalEntry{ID: 2, Date: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), RefType: "player_trading", Amount: -200}
+	esi.On("GetCharacterWalletJournal", int64(10), mock.Anything).Return([]model.WalletJournalEntry{older}, nil).Once()
+	esi.On("GetCharacterWalletJournal", int64(10), mock.Anything).Return([]model.WalletJournalEntry{newer, older}, nil).Once()
+	esi.On("GetCharacterWalletJournal", int64(20), mock.Anything).Return([]model.WalletJournalEntry(nil), errors.New("forbidden")).Twice()
+
+	summary := svc.RefreshWallets(accounts)
+	svc.SyncJournals(accounts)
+	assert.Equal(t, 1250.5, summary.Total)
+	assert.Equal(t, []model.AccountWallet{
+		{AccountID: 1, AccountName: "Main", Balance: 1000, Characters: 1},
+		{AccountID: 2, AccountName: "Alt", Balance: 250.5, Characters: 1},
+	}, summary.Accounts)
+
+	// An unchanged balance is not recorded again, a changed one is
+	accounts[1].Characters[0].Character.WalletBalance = isk(300)
+	svc.RefreshWallets(accounts)
+	svc.SyncJournals(accounts)
+
+	history, err := svc.GetBalanceHistory(10)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	history, err = svc.GetBalanceHistory(20)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 300.0, history[1].Balance)
+
+	journal, err := svc.GetJournal(10)
+	require.NoError(t, err)
+	require.Len(t, journal, 2)
+	assert.Equal(t, int64(2), journal[0].ID)
+
+	journal, err = svc.GetJournal(11)
+	require.NoError(t, err)
+	assert.Empty(t, journal)
+
+	esi.AssertExpectations(t)
+}
+
+func TestWalletService_BalanceHistoryRetention(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	store := persistAccount.NewWalletStore(logger, persist.OSFileSystem{}, t.TempDir())
+	svc := account.NewWalletService(logger, &testutil.MockESIService{}, store)
+
+	now := time.Now()
+	require.NoError(t, store.SaveWalletData(model.WalletData{History: map[int64][]model.BalanceSnapshot{10: {
+		{Time: now.AddDate(-2, 0, 0), Balance: 100},
+		{Time: now.AddDate(-1, 0, -1), Balance: 200},
+		{Time: now.AddDate(0, -1, 0), Balance: 300},
+	}}}))
+	accounts := []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{walletCharacter(10, isk(300))}}}
+
+	svc.RefreshWallets(accounts)
+	history, err := svc.GetBalanceHistory(10)
+	require.NoError(t, err)
+	assert.Len(t, history, 3, "nothing is recorded or pruned while the balance stays the same")
+
+	accounts[0].Characters[0].Character.WalletBalance = isk(400)
+	svc.RefreshWallets(accounts)
+	history, err = svc.GetBalanceHistory(10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 300.0, history[0].Balance)
+	assert.Equal(t, 400.0, history[1].Balance)
+}
@@ -41,6 +41,13 @@ func (s *appStateService) UpdateAndSaveAppState(data model.AppState) error {
 	return nil
 }
 
+func (s *appStateService) UpdateEveProfiles(update func(profiles []model.EveProfile) []model.EveProfile) error {
+	if err := s.stateRepo.UpdateEveProfiles(update); err != nil {
+		return fmt.Errorf("failed to update eve profiles: %w", err)
+	}
+	return nil
+}
+
 func (s *appStateService) ClearAppState() {
 	s.stateRepo.ClearAppState()
 }
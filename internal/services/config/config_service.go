@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -65,6 +67,67 @@ func (s *configService) GetSettingsDir() (string, error) {
 	return configData.SettingsDir, nil
 }
 
+// GetSettingsRoots returns SettingsDir as model.PrimarySettingsRoot, even when it is not set,
+// followed by the added roots.
+func (s *configService) GetSettingsRoots() ([]model.SettingsRoot, error) {
+	configData, err := s.configRepo.FetchConfigData()
+	if err != nil {
+		return nil, err
+	}
+	roots := []model.SettingsRoot{{Name: model.PrimarySettingsRoot, Path: configData.SettingsDir}}
+	return append(roots, configData.SettingsRoots...), nil
+}
+
+func (s *configService) AddSettingsRoot(root model.SettingsRoot) error {
+	root.Name = strings.TrimSpace(root.Name)
+	if root.Name == "" || root.Path == "" {
+		return fmt.Errorf("settings root needs a name and a path")
+	}
+	if root.Name == model.PrimarySettingsRoot {
+		return fmt.Errorf("%s is the settings directory, choose another name", model.PrimarySettingsRoot)
+	}
+	if info, err := os.Stat(root.Path); err != nil || !info.IsDir() {
+		return fmt.Errorf("unable to find directory %s", root.Path)
+	}
+
+	configData, err := s.configRepo.FetchConfigData()
+	if err != nil {
+		return err
+	}
+	if filepath.Clean(root.Path) == filepath.Clean(configData.SettingsDir) {
+		return fmt.Errorf("%s is already the settings directory", root.Path)
+	}
+	for _, existing := range configData.SettingsRoots {
+		if existing.Name == root.Name {
+			return fmt.Errorf("settings root %s already exists", root.Name)
+		}
+		if filepath.Clean(existing.Path) == filepath.Clean(root.Path) {
+			return fmt.Errorf("%s is already settings root %s", root.Path, existing.Name)
+		}
+	}
+
+	configData.SettingsRoots = append(configData.SettingsRoots, root)
+	return s.configRepo.SaveConfigData(configData)
+}
+
+func (s *configService) RemoveSettingsRoot(name string) error {
+	configData, err := s.configRepo.FetchConfigData()
+	if err != nil {
+		return err
+	}
+	for i, root := range configData.SettingsRoots {
+		if root.Name == name {
+			configData.SettingsRoots = append(configData.SettingsRoots[:i], configData.SettingsRoots[i+1:]...)
+			return s.configRepo.SaveConfigData(configData)
+		}
+	}
+	return fmt.Errorf("settings root %s not found", name)
+}
+
+func (s *configService) DiscoverSettingsDirs() ([]model.SettingsDirCandidate, error) {
+	return s.configRepo.DiscoverSettingsDirs()
+}
+
 func (s *configService) FetchUserSelections() (model.DropDownSelections, error) {
 	return s.configRepo.FetchUserSelections()
 }
@@ -93,7 +156,7 @@ func (s *configService) EnsureSettingsDir() error {
 	}
 
 	if _, err = os.Stat(defaultDir); os.IsNotExist(err) {
-		// Attempt to find "c_ccp_eve_online_tq_tranquility"
+		// Attempt to find "c_ccp_eve_online_tq_tranquility", then Singularity's settings
 		homeDir, homeErr := os.UserHomeDir()
 		if homeErr != nil {
 			return fmt.Errorf("default directory does not exist and failed to get home directory: %v", homeErr)
@@ -101,7 +164,10 @@ func (s *configService) EnsureSettingsDir() error {
 
 		searchPath, findErr := s.findEveSettingsDir(homeDir, "c_ccp_eve_online_tq_tranquility")
 		if findErr != nil {
-			return fmt.Errorf("default directory does not exist and failed to find c_ccp_eve_online_tq_tranquility: %w", findErr)
+			var sisiErr error
+			if searchPath, sisiErr = s.findEveSettingsDir(homeDir, "_sisi_singularity"); sisiErr != nil {
+				return fmt.Errorf("default directory does not exist and failed to find c_ccp_eve_online_tq_tranquility: %w", findErr)
+			}
 		}
 		configData.SettingsDir = searchPath
 	} else {
@@ -165,3 +231,35 @@ func (s *configService) GetRoles() ([]string, error) {
 func (s *configService) FetchConfigData() (*model.ConfigData, error) {
 	return s.configRepo.FetchConfigData()
 }
+
+func (s *configService) GetCalendarToken() (string, error) {
+	configData, err := s.configRepo.FetchConfigData()
+	if err != nil {
+		return "", err
+	}
+	if configData.CalendarToken != "" {
+		return configData.CalendarToken, nil
+	}
+	return s.saveNewCalendarToken(configData)
+}
+
+// ResetCalendarToken replaces the calendar feed token, invalidating previously shared feed URLs.
+func (s *configService) ResetCalendarToken() (string, error) {
+	configData, err := s.configRepo.FetchConfigData()
+	if err != nil {
+		return "", err
+	}
+	return s.saveNewCalendarToken(configData)
+}
+
+func (s *configService) saveNewCalendarToken(configData *model.ConfigData) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	configData.CalendarToken = hex.EncodeToString(buf)
+	if err := s.configRepo.SaveConfigData(configData); err != nil {
+		return "", err
+	}
+	return configData.CalendarToken, nil
+}
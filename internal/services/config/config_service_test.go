@@ -240,3 +240,57 @@ func TestFetchConfigData(t *testing.T) {
 
 	repo.AssertExpectations(t)
 }
+
+func TestCalendarToken_CreatedOnceAndReset(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockConfigRepository{}
+	svc := config.NewConfigService(logger, repo)
+
+	configData := &model.ConfigData{}
+	repo.On("FetchConfigData").Return(configData, nil)
+	repo.On("SaveConfigData", configData).Return(nil).Twice()
+
+	token, err := svc.GetCalendarToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, 32)
+
+	again, err := svc.GetCalendarToken()
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	reset, err := svc.ResetCalendarToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, reset)
+	assert.Equal(t, reset, configData.CalendarToken)
+
+	repo.AssertExpectations(t)
+}
+
+func TestSettingsRoots_AddAndRemove(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockConfigRepository{}
+	svc := config.NewConfigService(logger, repo)
+
+	tqDir, sisiDir := t.TempDir(), t.TempDir()
+	configData := &model.ConfigData{SettingsDir: tqDir}
+	repo.On("FetchConfigData").Return(configData, nil)
+	repo.On("SaveConfigData", mock.Anything).Return(nil)
+
+	assert.NoError(t, svc.AddSettingsRoot(model.SettingsRoot{Name: "sisi", Path: sisiDir}))
+	assert.Error(t, svc.AddSettingsRoot(model.SettingsRoot{Name: "sisi", Path: t.TempDir()}), "duplicate name")
+	assert.Error(t, svc.AddSettingsRoot(model.SettingsRoot{Name: "other", Path: sisiDir}), "duplicate path")
+	assert.Error(t, svc.AddSettingsRoot(model.SettingsRoot{Name: "tq", Path: tqDir}), "settings directory")
+	assert.Error(t, svc.AddSettingsRoot(model.SettingsRoot{Name: model.PrimarySettingsRoot, Path: t.TempDir()}))
+	assert.Error(t, svc.AddSettingsRoot(model.SettingsRoot{Name: "gone", Path: filepath.Join(sisiDir, "missing")}))
+
+	roots, err := svc.GetSettingsRoots()
+	assert.NoError(t, err)
+	assert.Equal(t, []model.SettingsRoot{
+		{Name: model.PrimarySettingsRoot, Path: tqDir},
+		{Name: "sisi", Path: sisiDir},
+	}, roots)
+
+	assert.NoError(t, svc.RemoveSettingsRoot("sisi"))
+	assert.Error(t, svc.RemoveSettingsRoot("sisi"))
+	assert.Empty(t, configData.SettingsRoots)
+}
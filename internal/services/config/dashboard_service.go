@@ -19,6 +19,8 @@ type dashboardService struct {
 	configService     interfaces.ConfigService
 	eveProfileService interfaces.EveProfilesService
 	stateService      interfaces.AppStateService
+	notifications     interfaces.NotificationService
+	wallets           interfaces.WalletService
 }
 
 func NewDashboardService(
@@ -29,6 +31,8 @@ func NewDashboardService(
 	conSvc interfaces.ConfigService,
 	stateSvc interfaces.AppStateService,
 	eveSvc interfaces.EveProfilesService,
+	notifySvc interfaces.NotificationService,
+	walletSvc interfaces.WalletService,
 ) interfaces.DashboardService {
 	return &dashboardService{
 		logger:            logger,
@@ -38,6 +42,8 @@ func NewDashboardService(
 		configService:     conSvc,
 		stateService:      stateSvc,
 		eveProfileService: eveSvc,
+		notifications:     notifySvc,
+		wallets:           walletSvc,
 	}
 }
 
@@ -50,10 +56,17 @@ func (d *dashboardService) RefreshAccountsAndState() (model.AppState, error) {
 
 	updatedData := d.prepareAppData(accountData)
 
+	if _, err := d.notifications.EvaluateAlerts(accountData.Accounts, updatedData.EveData.SkillPlans, updatedData.EveData.Doctrines); err != nil {
+		d.logger.Warnf("Failed to evaluate alerts: %v", err)
+	}
+
 	if err = d.stateService.UpdateAndSaveAppState(updatedData); err != nil {
 		d.logger.Errorf("Failed to update persist and session: %v", err)
 	}
 
+	// journals take a request per character, they are not needed to show the dashboard
+	go d.wallets.SyncJournals(accountData.Accounts)
+
 	return updatedData, nil
 }
 
@@ -62,10 +75,16 @@ func (d *dashboardService) GetCurrentAppState() model.AppState {
 }
 
 func (d *dashboardService) prepareAppData(accountData *model.AccountData) model.AppState {
+	skillTypes := d.skillService.GetSkillTypes()
 	skillPlans, eveConversions := d.skillService.GetPlanAndConversionData(
 		accountData.Accounts,
 		d.skillService.GetSkillPlans(),
-		d.skillService.GetSkillTypes(),
+		skillTypes,
+	)
+	doctrines := d.skillService.GetDoctrinesWithStatus(
+		accountData.Accounts,
+		d.skillService.GetDoctrines(),
+		skillTypes,
 	)
 
 	configData, err := d.configService.FetchConfigData()
@@ -83,6 +102,7 @@ func (d *dashboardService) prepareAppData(accountData *model.AccountData) model.
 	eveData := &model.EveData{
 		EveProfiles:    subDirData,
 		SkillPlans:     skillPlans,
+		Doctrines:      doctrines,
 		EveConversions: eveConversions,
 	}
 
@@ -91,6 +111,10 @@ func (d *dashboardService) prepareAppData(accountData *model.AccountData) model.
 		AccountData: *accountData,
 		EveData:     *eveData,
 		ConfigData:  *configData,
+
+		ExpiryWarnings: d.accountService.GetExpiryWarnings(accountData.Accounts),
+		TokenWarnings:  d.accountService.GetTokenWarnings(accountData.Accounts),
+		Wallets:        d.wallets.RefreshWallets(accountData.Accounts),
 	}
 }
 
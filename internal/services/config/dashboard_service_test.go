@@ -19,8 +19,10 @@ func TestRefreshAccountsAndState_Success(t *testing.T) {
 	cSvc := &testutil.MockCharacterService{}
 	stateSvc := &testutil.MockAppStateService{}
 	esi := &testutil.MockEveProfilesService{}
+	notify := &testutil.MockNotificationService{}
+	wallets := &testutil.MockWalletService{}
 
-	ds := config.NewDashboardService(logger, sk, cSvc, as, cs, stateSvc, esi)
+	ds := config.NewDashboardService(logger, sk, cSvc, as, cs, stateSvc, esi, notify, wallets)
 
 	accounts := []model.Account{{Name: "Acc1"}}
 	as.On("RefreshAccountData", cSvc).Return(&model.AccountData{Accounts: accounts}, nil).Once()
@@ -29,6 +31,15 @@ func TestRefreshAccountsAndState_Success(t *testing.T) {
 	sk.On("GetSkillTypes").Return(map[string]model.SkillType{}).Once()
 	sk.On("GetPlanAndConversionData", accounts, mock.Anything, mock.Anything).
 		Return(map[string]model.SkillPlanWithStatus{}, map[string]string{}).Once()
+	sk.On("GetDoctrines").Return(map[string]model.Doctrine{}).Once()
+	sk.On("GetDoctrinesWithStatus", accounts, mock.Anything, mock.Anything).
+		Return(map[string]model.DoctrineWithStatus{}).Once()
+
+	as.On("GetExpiryWarnings", accounts).Return([]model.ExpiryWarning{}).Once()
+	as.On("GetTokenWarnings", accounts).Return([]model.TokenWarning{}).Once()
+	wallets.On("RefreshWallets", accounts).Return(model.WalletSummary{}).Once()
+	wallets.On("SyncJournals", accounts).Return().Maybe()
+	notify.On("EvaluateAlerts", accounts, mock.Anything, mock.Anything).Return([]model.Alert{}, nil).Once()
 
 	esi.On("LoadCharacterSettings").Return([]model.EveProfile{}, nil).Once()
 	cs.On("FetchConfigData").Return(&model.ConfigData{}, nil).Once()
@@ -44,6 +55,8 @@ func TestRefreshAccountsAndState_Success(t *testing.T) {
 	cs.AssertExpectations(t)
 	esi.AssertExpectations(t)
 	stateSvc.AssertExpectations(t)
+	notify.AssertExpectations(t)
+	wallets.AssertExpectations(t)
 }
 
 func TestRefreshAccountsAndState_AccountDataError(t *testing.T) {
@@ -54,8 +67,10 @@ func TestRefreshAccountsAndState_AccountDataError(t *testing.T) {
 	conSvc := &testutil.MockConfigService{}
 	stateSvc := &testutil.MockAppStateService{}
 	eveSvc := &testutil.MockEveProfilesService{}
+	notify := &testutil.MockNotificationService{}
+	wallets := &testutil.MockWalletService{}
 
-	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc)
+	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc, notify, wallets)
 
 	accSvc.On("RefreshAccountData", charSvc).Return((*model.AccountData)(nil), errors.New("fetch error")).Once()
 
@@ -74,8 +89,10 @@ func TestGetCurrentAppState(t *testing.T) {
 	conSvc := &testutil.MockConfigService{}
 	stateSvc := &testutil.MockAppStateService{}
 	eveSvc := &testutil.MockEveProfilesService{}
+	notify := &testutil.MockNotificationService{}
+	wallets := &testutil.MockWalletService{}
 
-	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc)
+	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc, notify, wallets)
 
 	expectedState := model.AppState{LoggedIn: false}
 	stateSvc.On("GetAppState").Return(expectedState).Once()
@@ -93,8 +110,10 @@ func TestRefreshDataInBackground_Success(t *testing.T) {
 	conSvc := &testutil.MockConfigService{}
 	stateSvc := &testutil.MockAppStateService{}
 	eveSvc := &testutil.MockEveProfilesService{}
+	notify := &testutil.MockNotificationService{}
+	wallets := &testutil.MockWalletService{}
 
-	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc)
+	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc, notify, wallets)
 
 	accountData := &model.AccountData{
 		Accounts: []model.Account{{Name: "SomeAccount"}},
@@ -106,6 +125,14 @@ func TestRefreshDataInBackground_Success(t *testing.T) {
 	// Mock GetPlanAndConversionData
 	skillSvc.On("GetPlanAndConversionData", accountData.Accounts, mock.Anything, mock.Anything).
 		Return(map[string]model.SkillPlanWithStatus{}, map[string]string{}).Once()
+	skillSvc.On("GetDoctrines").Return(map[string]model.Doctrine{}).Once()
+	skillSvc.On("GetDoctrinesWithStatus", accountData.Accounts, mock.Anything, mock.Anything).
+		Return(map[string]model.DoctrineWithStatus{}).Once()
+	accSvc.On("GetExpiryWarnings", accountData.Accounts).Return([]model.ExpiryWarning{}).Once()
+	accSvc.On("GetTokenWarnings", accountData.Accounts).Return([]model.TokenWarning{}).Once()
+	wallets.On("RefreshWallets", accountData.Accounts).Return(model.WalletSummary{}).Once()
+	wallets.On("SyncJournals", accountData.Accounts).Return().Maybe()
+	notify.On("EvaluateAlerts", accountData.Accounts, mock.Anything, mock.Anything).Return([]model.Alert{}, nil).Once()
 
 	conSvc.On("FetchConfigData").Return(&model.ConfigData{}, nil).Once()
 	eveSvc.On("LoadCharacterSettings").Return([]model.EveProfile{}, nil).Once()
@@ -119,6 +146,8 @@ func TestRefreshDataInBackground_Success(t *testing.T) {
 	conSvc.AssertExpectations(t)
 	eveSvc.AssertExpectations(t)
 	stateSvc.AssertExpectations(t)
+	notify.AssertExpectations(t)
+	wallets.AssertExpectations(t)
 }
 
 func TestRefreshDataInBackground_Error(t *testing.T) {
@@ -129,8 +158,10 @@ func TestRefreshDataInBackground_Error(t *testing.T) {
 	conSvc := &testutil.MockConfigService{}
 	stateSvc := &testutil.MockAppStateService{}
 	eveSvc := &testutil.MockEveProfilesService{}
+	notify := &testutil.MockNotificationService{}
+	wallets := &testutil.MockWalletService{}
 
-	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc)
+	ds := config.NewDashboardService(logger, skillSvc, charSvc, accSvc, conSvc, stateSvc, eveSvc, notify, wallets)
 
 	accSvc.On("RefreshAccountData", charSvc).Return((*model.AccountData)(nil), errors.New("account refresh error")).Once()
 
@@ -0,0 +1,455 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.NotificationService = (*notificationService)(nil)
+
+const (
+	defaultQueueEndingHours     = 24
+	defaultEventIntervalMinutes = 15
+	defaultDoctrineMention      = "@here"
+	resolvedAlertRetention      = 30 * 24 * time.Hour
+)
+
+// milestoneRules are announced only when they start holding after a character was first evaluated,
+// matches a character already had then, as when it was just added, are recorded as acknowledged.
+var milestoneRules = []string{model.AlertPlanQualified, model.AlertDoctrineReady}
+
+type notificationService struct {
+	logger       interfaces.Logger
+	repo         interfaces.NotificationRepository
+	skillService interfaces.SkillService
+	notifier     interfaces.Notifier
+	mut          sync.Mutex    // held while the state is read, changed and saved
+	deliver      chan struct{} // wakes the delivery worker, requests made while it posts are coalesced
+}
+
+// NewNotificationService starts a worker posting pending alerts to the webhook, so a slow webhook does
+// not hold up the refresh that raised them. The worker stops when ctx is done.
+func NewNotificationService(ctx context.Context, logger interfaces.Logger, repo interfaces.NotificationRepository, skillSvc interfaces.SkillService, notifier interfaces.Notifier) interfaces.NotificationService {
+	n := &notificationService{
+		logger:       logger,
+		repo:         repo,
+		skillService: skillSvc,
+		notifier:     notifier,
+		deliver:      make(chan struct{}, 1),
+	}
+	go n.deliveryWorker(ctx)
+	return n
+}
+
+func (n *notificationService) EvaluateAlerts(accounts []model.Account, skillPlans map[string]model.SkillPlanWithStatus, doctrines map[string]model.DoctrineWithStatus) ([]model.Alert, error) {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	state, err := n.repo.FetchNotificationState()
+	if err != nil {
+		return nil, err
+	}
+	if state.LastSent == nil {
+		state.LastSent = make(map[string]time.Time)
+	}
+
+	now := time.Now()
+	settings := withNotificationDefaults(state.Settings)
+	current := n.evaluateAlertRules(accounts, skillPlans, doctrines, settings, now)
+
+	alerts := make(map[string]model.Alert, len(state.Alerts))
+	for _, alert := range state.Alerts {
+		alerts[alert.ID] = alert
+	}
+
+	raised := make([]model.Alert, 0)
+	for id, alert := range current {
+		if existing, ok := alerts[id]; ok && existing.ResolvedAt == nil {
+			continue
+		}
+		alert.RaisedAt = now
+		if isMilestoneRule(alert.Rule) && !state.BaselinedCharacters[alert.CharacterID] {
+			alert.Acknowledged = true
+		} else {
+			alert.PendingDelivery = settings.WebhookURL != "" && settings.Events[alert.Rule].Enabled
+			raised = append(raised, alert)
+		}
+		alerts[id] = alert
+	}
+	// characters that were removed start over when they are added again
+	state.BaselinedCharacters = make(map[int64]bool)
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			state.BaselinedCharacters[charIdentity.Character.CharacterID] = true
+		}
+	}
+
+	for id, alert := range alerts {
+		if _, holds := current[id]; holds {
+			continue
+		}
+		if alert.ResolvedAt == nil {
+			resolvedAt := now
+			alert.ResolvedAt = &resolvedAt
+			alerts[id] = alert
+		} else if now.Sub(*alert.ResolvedAt) > resolvedAlertRetention {
+			delete(alerts, id)
+		}
+	}
+
+	state.Alerts = sortedAlerts(alerts)
+	if err := n.repo.SaveNotificationState(state); err != nil {
+		return nil, err
+	}
+	n.requestDelivery()
+
+	sort.Slice(raised, func(i, j int) bool { return raised[i].ID < raised[j].ID })
+	return raised, nil
+}
+
+func (n *notificationService) GetAlerts() ([]model.Alert, error) {
+	state, err := n.repo.FetchNotificationState()
+	if err != nil {
+		return nil, err
+	}
+	if state.Alerts == nil {
+		return []model.Alert{}, nil
+	}
+	return state.Alerts, nil
+}
+
+func (n *notificationService) AcknowledgeAlert(id string) error {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	state, err := n.repo.FetchNotificationState()
+	if err != nil {
+		return err
+	}
+	for i := range state.Alerts {
+		if state.Alerts[i].ID == id {
+			state.Alerts[i].Acknowledged = true
+			return n.repo.SaveNotificationState(state)
+		}
+	}
+	return fmt.Errorf("alert not found")
+}
+
+func (n *notificationService) GetSettings() (model.NotificationSettings, error) {
+	state, err := n.repo.FetchNotificationState()
+	if err != nil {
+		return model.NotificationSettings{}, err
+	}
+	return withNotificationDefaults(state.Settings), nil
+}
+
+func (n *notificationService) UpdateSettings(settings model.NotificationSettings) error {
+	if settings.QueueEndingHours < 0 {
+		return fmt.Errorf("queue ending hours cannot be negative")
+	}
+	for rule, event := range settings.Events {
+		if !isAlertRule(rule) {
+			return fmt.Errorf("unknown alert rule %s", rule)
+		}
+		if event.MinIntervalMinutes < 0 {
+			return fmt.Errorf("minimum interval for %s cannot be negative", rule)
+		}
+	}
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	state, err := n.repo.FetchNotificationState()
+	if err != nil {
+		return err
+	}
+	state.Settings = settings
+	return n.repo.SaveNotificationState(state)
+}
+
+func (n *notificationService) requestDelivery() {
+	select {
+	case n.deliver <- struct{}{}:
+	default:
+	}
+}
+
+func (n *notificationService) deliveryWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.deliver:
+			n.deliverPending()
+		}
+	}
+}
+
+// deliverPending posts the alerts waiting for delivery, one batch per rule. A rule that was
+// posted less than its minimum interval ago keeps its alerts pending until the next evaluation,
+// as does a failed post. The state is not locked while posting, the outcome is recorded after.
+func (n *notificationService) deliverPending() {
+	n.mut.Lock()
+	state, err := n.repo.FetchNotificationState()
+	n.mut.Unlock()
+	if err != nil {
+		n.logger.Warnf("failed to load alerts to deliver: %v", err)
+		return
+	}
+	settings := withNotificationDefaults(state.Settings)
+	if settings.WebhookURL == "" {
+		return
+	}
+
+	pending := make(map[string][]model.Alert)
+	for _, alert := range state.Alerts {
+		if alert.PendingDelivery {
+			pending[alert.Rule] = append(pending[alert.Rule], alert)
+		}
+	}
+
+	now := time.Now()
+	sent := make(map[string]bool)
+	// by ID and when they were raised, an alert raised again in the meantime is still to be delivered
+	settled := make(map[string]time.Time)
+	for _, rule := range model.AlertRules {
+		batch := pending[rule]
+		if len(batch) == 0 {
+			continue
+		}
+		event := settings.Events[rule]
+		if event.Enabled {
+			interval := time.Duration(event.MinIntervalMinutes) * time.Minute
+			if last, ok := state.LastSent[rule]; ok && now.Sub(last) < interval {
+				n.logger.Debugf("holding %d %s alerts, last posted %s ago", len(batch), rule, now.Sub(last).Round(time.Second))
+				continue
+			}
+
+			sort.Slice(batch, func(i, j int) bool {
+				if !batch[i].RaisedAt.Equal(batch[j].RaisedAt) {
+					return batch[i].RaisedAt.Before(batch[j].RaisedAt)
+				}
+				return batch[i].ID < batch[j].ID
+			})
+			if err := n.notifier.Notify(settings.WebhookURL, rule, event.Mention, batch); err != nil {
+				n.logger.Warnf("failed to send %d %s alerts to webhook: %v", len(batch), rule, err)
+				continue
+			}
+			sent[rule] = true
+		}
+
+		// Delivered, or the rule was disabled while its alerts were waiting
+		for _, alert := range batch {
+			settled[alert.ID] = alert.RaisedAt
+		}
+	}
+	if len(settled) == 0 {
+		return
+	}
+
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	state, err = n.repo.FetchNotificationState()
+	if err != nil {
+		n.logger.Warnf("failed to load alerts to record their delivery: %v", err)
+		return
+	}
+	if state.LastSent == nil {
+		state.LastSent = make(map[string]time.Time)
+	}
+	for rule := range sent {
+		state.LastSent[rule] = now
+	}
+	for i := range state.Alerts {
+		if raisedAt, ok := settled[state.Alerts[i].ID]; ok && raisedAt.Equal(state.Alerts[i].RaisedAt) {
+			state.Alerts[i].PendingDelivery = false
+		}
+	}
+	if err := n.repo.SaveNotificationState(state); err != nil {
+		n.logger.Warnf("failed to record alert delivery: %v", err)
+	}
+}
+
+// evaluateAlertRules returns the alerts whose rule currently holds, keyed by alert ID.
+func (n *notificationService) evaluateAlertRules(
+	accounts []model.Account,
+	skillPlans map[string]model.SkillPlanWithStatus,
+	doctrines map[string]model.DoctrineWithStatus,
+	settings model.NotificationSettings,
+	now time.Time,
+) map[string]model.Alert {
+	current := make(map[string]model.Alert)
+	window := time.Duration(settings.QueueEndingHours) * time.Hour
+
+	type owner struct {
+		account string
+		id      int64
+	}
+	owners := make(map[string]owner)
+
+	for _, account := range accounts {
+		// an omega account trains one character at a time, its alts are only idle when nobody trains
+		accountTraining := false
+		for _, charIdentity := range account.Characters {
+			accountTraining = accountTraining || charIdentity.MCT
+		}
+
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+			owners[char.CharacterName] = owner{account: account.Name, id: char.CharacterID}
+
+			if charIdentity.TokenRevoked {
+				alert := newAlert(model.AlertTokenRevoked, account.Name, char, "")
+				alert.Message = fmt.Sprintf("%s needs to log in again, EVE SSO revoked the token", char.CharacterName)
+				current[alert.ID] = alert
+			}
+
+			for _, q := range char.SkillQueue {
+				if q.FinishDate == nil || q.FinishDate.After(now) {
+					continue
+				}
+				alert := newAlert(model.AlertSkillCompleted, account.Name, char, "")
+				alert.ID = fmt.Sprintf("%s:%d:%d", alert.ID, q.SkillID, q.FinishedLevel)
+				alert.Message = fmt.Sprintf("%s finished training %s %d", char.CharacterName, n.skillService.GetSkillName(q.SkillID), q.FinishedLevel)
+				current[alert.ID] = alert
+			}
+
+			if len(char.SkillQueue) == 0 {
+				alert := newAlert(model.AlertQueueEmpty, account.Name, char, "")
+				alert.Message = fmt.Sprintf("%s's skill queue is empty", char.CharacterName)
+				current[alert.ID] = alert
+			}
+
+			if !charIdentity.MCT {
+				if account.Status == model.Omega && !accountTraining {
+					alert := newAlert(model.AlertIdleOmega, account.Name, char, "")
+					alert.Message = fmt.Sprintf("%s is not training on omega account %s", char.CharacterName, account.Name)
+					current[alert.ID] = alert
+				}
+				continue
+			}
+
+			if end := queueEnd(char.SkillQueue); end != nil && end.Before(now.Add(window)) {
+				alert := newAlert(model.AlertQueueEnding, account.Name, char, "")
+				alert.Message = fmt.Sprintf("%s's skill queue ends in %s", char.CharacterName, end.Sub(now).Round(time.Minute))
+				current[alert.ID] = alert
+			}
+		}
+	}
+
+	ownedAlert := func(rule, charName, planName string) (model.Alert, bool) {
+		o, ok := owners[charName]
+		if !ok {
+			return model.Alert{}, false
+		}
+		char := model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: o.id, CharacterName: charName}}
+		return newAlert(rule, o.account, char, planName), true
+	}
+
+	for planName, plan := range skillPlans {
+		for _, charName := range plan.QualifiedCharacters {
+			if alert, ok := ownedAlert(model.AlertPlanQualified, charName, planName); ok {
+				alert.Message = fmt.Sprintf("%s now qualifies for %s", charName, planName)
+				current[alert.ID] = alert
+			}
+		}
+	}
+
+	for doctrineName, doctrine := range doctrines {
+		for _, plan := range doctrine.Plans {
+			// a character is in the list of the highest tier it meets, either one is fleet-ready
+			ready := append(slices.Clone(plan.MinimumCharacters), plan.RecommendedCharacters...)
+			for _, charName := range ready {
+				if alert, ok := ownedAlert(model.AlertDoctrineReady, charName, doctrineName+" / "+plan.Name); ok {
+					alert.Message = fmt.Sprintf("%s is fleet-ready for %s as %s", charName, doctrineName, plan.Name)
+					current[alert.ID] = alert
+				}
+			}
+		}
+	}
+
+	return current
+}
+
+func newAlert(rule, accountName string, char model.Character, planName string) model.Alert {
+	id := fmt.Sprintf("%s:%d", rule, char.CharacterID)
+	if planName != "" {
+		id += ":" + planName
+	}
+	return model.Alert{
+		ID:            id,
+		Rule:          rule,
+		AccountName:   accountName,
+		CharacterID:   char.CharacterID,
+		CharacterName: char.CharacterName,
+		PlanName:      planName,
+	}
+}
+
+// queueEnd returns the latest finish date in the queue.
+func queueEnd(queue []model.SkillQueue) *time.Time {
+	var end *time.Time
+	for _, q := range queue {
+		if q.FinishDate != nil && (end == nil || q.FinishDate.After(*end)) {
+			end = q.FinishDate
+		}
+	}
+	return end
+}
+
+// withNotificationDefaults fills in unset values, rules without event settings are enabled.
+func withNotificationDefaults(settings model.NotificationSettings) model.NotificationSettings {
+	if settings.QueueEndingHours == 0 {
+		settings.QueueEndingHours = defaultQueueEndingHours
+	}
+
+	events := make(map[string]model.NotificationEventSettings, len(model.AlertRules))
+	for _, rule := range model.AlertRules {
+		event, ok := settings.Events[rule]
+		if !ok {
+			event = model.NotificationEventSettings{Enabled: true, MinIntervalMinutes: defaultEventIntervalMinutes}
+			if rule == model.AlertDoctrineReady {
+				event.Mention = defaultDoctrineMention
+			}
+		}
+		events[rule] = event
+	}
+	settings.Events = events
+	return settings
+}
+
+func isMilestoneRule(rule string) bool {
+	for _, r := range milestoneRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func isAlertRule(rule string) bool {
+	for _, r := range model.AlertRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedAlerts orders alerts newest first.
+func sortedAlerts(alerts map[string]model.Alert) []model.Alert {
+	sorted := make([]model.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		sorted = append(sorted, alert)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].RaisedAt.Equal(sorted[j].RaisedAt) {
+			return sorted[i].RaisedAt.After(sorted[j].RaisedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
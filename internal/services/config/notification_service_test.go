@@ -0,0 +1,440 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	persistConfig "github.com/guarzo/canifly/internal/persist/config"
+	"github.com/guarzo/canifly/internal/services/config"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func alertIDs(alerts []model.Alert) []string {
+	ids := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+type webhookMessage struct {
+	Username string        `json:"username"`
+	Content  string        `json:"content"`
+	Text     string        `json:"text"`
+	Event    string        `json:"event"`
+	Alerts   []model.Alert `json:"alerts"`
+}
+
+// webhookReceiver records every message posted to it
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	messages []webhookMessage
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	rec := &webhookReceiver{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg webhookMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		rec.mu.Lock()
+		rec.messages = append(rec.messages, msg)
+		rec.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func (r *webhookReceiver) received() []webhookMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhookMessage(nil), r.messages...)
+}
+
+// waitForDelivery waits until the webhook received count messages and the worker recorded their
+// delivery, leaving only the pending alerts still to be delivered.
+func waitForDelivery(t *testing.T, svc interfaces.NotificationService, receiver *webhookReceiver, count int, pending ...string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		if len(receiver.received()) != count {
+			return false
+		}
+		alerts, err := svc.GetAlerts()
+		require.NoError(t, err)
+		for _, alert := range alerts {
+			if alert.PendingDelivery != slices.Contains(pending, alert.ID) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func newTestNotificationService(t *testing.T, skillSvc *testutil.MockSkillService) (interfaces.NotificationService, *testutil.MockLogger) {
+	logger := &testutil.MockLogger{}
+	store := persistConfig.NewNotificationStore(logger, persist.OSFileSystem{}, t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return config.NewNotificationService(ctx, logger, store, skillSvc, config.NewWebhookNotifier(logger)), logger
+}
+
+func TestNotificationService_RaisesDeduplicatesAndResolves(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	svc, _ := newTestNotificationService(t, &testutil.MockSkillService{})
+	require.NoError(t, svc.UpdateSettings(model.NotificationSettings{
+		WebhookURL: receiver.URL,
+		Events:     map[string]model.NotificationEventSettings{model.AlertQueueEmpty: {Enabled: false}},
+	}))
+
+	idle := model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Idler"}}}
+	accounts := []model.Account{{Name: "Main", Status: model.Omega, Characters: []model.CharacterIdentity{idle}}}
+	plans := map[string]model.SkillPlanWithStatus{"Old Plan": {Name: "Old Plan", QualifiedCharacters: []string{"Idler"}}}
+
+	// Plans qualified before the first evaluation are recorded but not announced
+	raised, err := svc.EvaluateAlerts(accounts, plans, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"idle_omega:1", "queue_empty:1"}, alertIDs(raised))
+	waitForDelivery(t, svc, receiver, 1)
+	received := receiver.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, model.AlertIdleOmega, received[0].Event)
+	require.Len(t, received[0].Alerts, 1)
+	assert.Equal(t, "idle_omega:1", received[0].Alerts[0].ID)
+
+	// Nothing changed, nothing new
+	raised, err = svc.EvaluateAlerts(accounts, plans, nil)
+	require.NoError(t, err)
+	assert.Empty(t, raised)
+
+	// The character starts training but the queue ends soon, and qualifies for a new plan
+	finish := time.Now().Add(5 * time.Hour)
+	accounts[0].Characters[0].MCT = true
+	accounts[0].Characters[0].Character.SkillQueue = []model.SkillQueue{{FinishDate: &finish}}
+	plans["New Plan"] = model.SkillPlanWithStatus{Name: "New Plan", QualifiedCharacters: []string{"Idler"}}
+
+	raised, err = svc.EvaluateAlerts(accounts, plans, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plan_qualified:1:New Plan", "queue_ending:1"}, alertIDs(raised))
+	waitForDelivery(t, svc, receiver, 3)
+
+	alerts, err := svc.GetAlerts()
+	require.NoError(t, err)
+	byID := make(map[string]model.Alert)
+	for _, a := range alerts {
+		byID[a.ID] = a
+	}
+	assert.NotNil(t, byID["idle_omega:1"].ResolvedAt)
+	assert.True(t, byID["plan_qualified:1:Old Plan"].Acknowledged)
+	assert.Nil(t, byID["queue_ending:1"].ResolvedAt)
+
+	require.NoError(t, svc.AcknowledgeAlert("queue_ending:1"))
+	assert.EqualError(t, svc.AcknowledgeAlert("missing"), "alert not found")
+
+	// Going idle again raises a fresh alert
+	accounts[0].Characters[0].MCT = false
+	raised, err = svc.EvaluateAlerts(accounts, plans, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"idle_omega:1"}, alertIDs(raised))
+	// posted idle alerts less than the minimum interval ago
+	waitForDelivery(t, svc, receiver, 3, "idle_omega:1")
+}
+
+func TestNotificationService_DefaultSettings(t *testing.T) {
+	svc, _ := newTestNotificationService(t, &testutil.MockSkillService{})
+
+	settings, err := svc.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, 24, settings.QueueEndingHours)
+	assert.Empty(t, settings.WebhookURL)
+	require.Len(t, settings.Events, len(model.AlertRules))
+	assert.True(t, settings.Events[model.AlertSkillCompleted].Enabled)
+	assert.Equal(t, "@here", settings.Events[model.AlertDoctrineReady].Mention)
+
+	assert.Error(t, svc.UpdateSettings(model.NotificationSettings{QueueEndingHours: -1}))
+	assert.EqualError(t, svc.UpdateSettings(model.NotificationSettings{
+		Events: map[string]model.NotificationEventSettings{"unknown": {Enabled: true}},
+	}), "unknown alert rule unknown")
+	assert.Error(t, svc.UpdateSettings(model.NotificationSettings{
+		Events: map[string]model.NotificationEventSettings{model.AlertQueueEmpty: {MinIntervalMinutes: -5}},
+	}))
+}
+
+func TestNotificationService_TrainingEventsAreRateLimited(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillName", int32(3300)).Return("Gunnery")
+	skillSvc.On("GetSkillName", int32(3301)).Return("Small Hybrid Turret")
+	svc, _ := newTestNotificationService(t, skillSvc)
+	require.NoError(t, svc.UpdateSettings(model.NotificationSettings{
+		WebhookURL: receiver.URL,
+		Events: map[string]model.NotificationEventSettings{
+			model.AlertSkillCompleted: {Enabled: true, MinIntervalMinutes: 60},
+			model.AlertDoctrineReady:  {Enabled: true, Mention: "@here"},
+			model.AlertTokenRevoked:   {Enabled: true},
+		},
+	}))
+
+	finished := time.Now().Add(-time.Hour)
+	training := time.Now().Add(48 * time.Hour)
+	pilot := model.CharacterIdentity{
+		MCT: true,
+		Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+			SkillQueue: []model.SkillQueue{
+				{SkillID: 3300, FinishedLevel: 3, FinishDate: &finished},
+				{SkillID: 3301, FinishedLevel: 2, FinishDate: &training},
+			},
+		},
+	}
+	lapsed := model.CharacterIdentity{
+		MCT:          true,
+		TokenRevoked: true,
+		Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Lapsed"},
+			SkillQueue:       []model.SkillQueue{{SkillID: 3300, FinishedLevel: 1, FinishDate: &training}},
+		},
+	}
+	accounts := []model.Account{{Name: "Main", Status: model.Omega, Characters: []model.CharacterIdentity{pilot, lapsed}}}
+	doctrines := map[string]model.DoctrineWithStatus{
+		"Ferox Fleet": {Name: "Ferox Fleet", Plans: []model.DoctrinePlanWithStatus{{DoctrinePlan: model.DoctrinePlan{Name: "Ferox"}}}},
+	}
+
+	raised, err := svc.EvaluateAlerts(accounts, nil, doctrines)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill_completed:1:3300:3", "token_revoked:2"}, alertIDs(raised))
+	waitForDelivery(t, svc, receiver, 2)
+
+	received := receiver.received()
+	require.Len(t, received, 2)
+	assert.Equal(t, model.AlertSkillCompleted, received[0].Event)
+	assert.Equal(t, "Pilot finished training Gunnery 3", received[0].Content)
+	assert.Equal(t, received[0].Content, received[0].Text)
+	assert.Equal(t, "CanIFly", received[0].Username)
+	assert.Equal(t, model.AlertTokenRevoked, received[1].Event)
+
+	// The next skill finishes within the hour and the pilot becomes fleet-ready
+	queue := &accounts[0].Characters[0].Character.SkillQueue
+	(*queue)[1].FinishDate = &finished
+	*queue = append(*queue, model.SkillQueue{SkillID: 3300, FinishedLevel: 4, FinishDate: &training})
+	doctrines["Ferox Fleet"].Plans[0].MinimumCharacters = []string{"Pilot"}
+
+	raised, err = svc.EvaluateAlerts(accounts, nil, doctrines)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"doctrine_ready:1:Ferox Fleet / Ferox", "skill_completed:1:3301:2"}, alertIDs(raised))
+
+	// the second skill waits for the minimum interval
+	waitForDelivery(t, svc, receiver, 3, "skill_completed:1:3301:2")
+	received = receiver.received()
+	assert.Equal(t, model.AlertDoctrineReady, received[2].Event)
+	assert.Equal(t, "@here\nPilot is fleet-ready for Ferox Fleet as Ferox", received[2].Content)
+}
+
+func TestWebhookNotifier_ReportsFailedDelivery(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer receiver.Close()
+
+	notifier := config.NewWebhookNotifier(&testutil.MockLogger{})
+	err := notifier.Notify(receiver.URL, model.AlertQueueEmpty, "", []model.Alert{{ID: "queue_empty:1", Message: "empty"}})
+	assert.EqualError(t, err, "webhook returned status 429")
+}
+
+func TestNotificationService_DoctrineReadyAtEitherTier(t *testing.T) {
+	svc, _ := newTestNotificationService(t, &testutil.MockSkillService{})
+	character := func(id int64, name string) model.CharacterIdentity {
+		return model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: id, CharacterName: name}}}
+	}
+	accounts := []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{character(1, "Pilot"), character(2, "Alt")}}}
+	plan := model.DoctrinePlanWithStatus{DoctrinePlan: model.DoctrinePlan{Name: "Ferox"}}
+	doctrines := map[string]model.DoctrineWithStatus{"Ferox Fleet": {Name: "Ferox Fleet", Plans: []model.DoctrinePlanWithStatus{plan}}}
+	doctrineAlerts := func(alerts []model.Alert) []string {
+		var ids []string
+		for _, alert := range alerts {
+			if alert.Rule == model.AlertDoctrineReady && alert.ResolvedAt == nil {
+				ids = append(ids, alert.ID)
+			}
+		}
+		return ids
+	}
+
+	_, err := svc.EvaluateAlerts(accounts, nil, doctrines)
+	require.NoError(t, err)
+
+	// Pilot trains straight to the recommended tier
+	doctrines["Ferox Fleet"].Plans[0].RecommendedCharacters = []string{"Pilot"}
+	doctrines["Ferox Fleet"].Plans[0].MinimumCharacters = []string{"Alt"}
+	raised, err := svc.EvaluateAlerts(accounts, nil, doctrines)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"doctrine_ready:1:Ferox Fleet / Ferox", "doctrine_ready:2:Ferox Fleet / Ferox"}, doctrineAlerts(raised))
+
+	// Alt moving up a tier is still fleet-ready
+	doctrines["Ferox Fleet"].Plans[0].RecommendedCharacters = []string{"Pilot", "Alt"}
+	doctrines["Ferox Fleet"].Plans[0].MinimumCharacters = nil
+	raised, err = svc.EvaluateAlerts(accounts, nil, doctrines)
+	require.NoError(t, err)
+	assert.Empty(t, doctrineAlerts(raised))
+
+	alerts, err := svc.GetAlerts()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"doctrine_ready:1:Ferox Fleet / Ferox", "doctrine_ready:2:Ferox Fleet / Ferox"}, doctrineAlerts(alerts))
+}
+
+func TestNotificationService_NewCharacterMilestonesAreNotAnnounced(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	svc, _ := newTestNotificationService(t, &testutil.MockSkillService{})
+	require.NoError(t, svc.UpdateSettings(model.NotificationSettings{
+		WebhookURL: receiver.URL,
+		Events: map[string]model.NotificationEventSettings{
+			model.AlertPlanQualified: {Enabled: true},
+		},
+	}))
+	training := time.Now().Add(48 * time.Hour)
+	character := func(id int64, name string) model.CharacterIdentity {
+		return model.CharacterIdentity{MCT: true, Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterID: id, CharacterName: name},
+			SkillQueue:       []model.SkillQueue{{FinishDate: &training}},
+		}}
+	}
+	accounts := []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{character(1, "Pilot")}}}
+	plans := map[string]model.SkillPlanWithStatus{"Plan": {Name: "Plan"}}
+
+	_, err := svc.EvaluateAlerts(accounts, plans, nil)
+	require.NoError(t, err)
+
+	// An alt that already qualifies is added while the pilot newly qualifies
+	accounts = append(accounts, model.Account{Name: "Alt", Characters: []model.CharacterIdentity{character(2, "Alt")}})
+	plans["Plan"] = model.SkillPlanWithStatus{Name: "Plan", QualifiedCharacters: []string{"Pilot", "Alt"}}
+	raised, err := svc.EvaluateAlerts(accounts, plans, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plan_qualified:1:Plan"}, alertIDs(raised))
+	waitForDelivery(t, svc, receiver, 1)
+	received := receiver.received()
+	require.Len(t, received[0].Alerts, 1)
+	assert.Equal(t, "Pilot", received[0].Alerts[0].CharacterName)
+
+	alerts, err := svc.GetAlerts()
+	require.NoError(t, err)
+	for _, alert := range alerts {
+		if alert.ID == "plan_qualified:2:Plan" {
+			assert.True(t, alert.Acknowledged)
+			assert.False(t, alert.PendingDelivery)
+		}
+	}
+}
+
+func TestNotificationService_SlowWebhookDoesNotBlockEvaluation(t *testing.T) {
+	release := make(chan struct{})
+	receiver := &webhookReceiver{}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		receiver.mu.Lock()
+		receiver.messages = append(receiver.messages, webhookMessage{})
+		receiver.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+
+	svc, _ := newTestNotificationService(t, &testutil.MockSkillService{})
+	require.NoError(t, svc.UpdateSettings(model.NotificationSettings{WebhookURL: receiver.URL}))
+	idle := model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Idler"}}}
+	accounts := []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{idle}}}
+
+	start := time.Now()
+	raised, err := svc.EvaluateAlerts(accounts, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queue_empty:1"}, alertIDs(raised))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the alert can be acknowledged while it is being posted
+	require.NoError(t, svc.AcknowledgeAlert("queue_empty:1"))
+	close(release)
+	waitForDelivery(t, svc, receiver, 1)
+
+	alerts, err := svc.GetAlerts()
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.True(t, alerts[0].Acknowledged)
+}
+
+func TestNotificationService_IdleOmegaOnlyWhenNoCharacterTrains(t *testing.T) {
+	svc, _ := newTestNotificationService(t, &testutil.MockSkillService{})
+
+	finish := time.Now().Add(72 * time.Hour)
+	trainer := model.CharacterIdentity{MCT: true, Character: model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Trainer"},
+		SkillQueue:       []model.SkillQueue{{FinishDate: &finish}},
+	}}
+	alt := model.CharacterIdentity{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Alt"}}}
+	accounts := []model.Account{{Name: "Main", Status: model.Omega, Characters: []model.CharacterIdentity{trainer, alt}}}
+
+	// the account is training its main, so the alt is not wasting omega time
+	raised, err := svc.EvaluateAlerts(accounts, nil, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, alertIDs(raised), "idle_omega:2")
+
+	accounts[0].Characters[0].MCT = false
+	raised, err = svc.EvaluateAlerts(accounts, nil, nil)
+	require.NoError(t, err)
+	assert.Subset(t, alertIDs(raised), []string{"idle_omega:1", "idle_omega:2"})
+}
+
+func TestWebhookNotifier_SplitsLongBatches(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	notifier := config.NewWebhookNotifier(&testutil.MockLogger{})
+
+	var alerts []model.Alert
+	for i := 0; i < 60; i++ {
+		alerts = append(alerts, model.Alert{
+			ID:      fmt.Sprintf("alert-%d", i),
+			Rule:    model.AlertRules[0],
+			Message: fmt.Sprintf("alert %02d %s", i, strings.Repeat("x", 90)),
+		})
+	}
+	require.NoError(t, notifier.Notify(receiver.URL, model.AlertRules[0], "@here", alerts))
+
+	messages := receiver.received()
+	require.Greater(t, len(messages), 1)
+	var delivered []string
+	for _, msg := range messages {
+		assert.LessOrEqual(t, utf8.RuneCountInString(msg.Content), 2000)
+		assert.True(t, strings.HasPrefix(msg.Content, "@here\n"))
+		assert.NotContains(t, msg.Content, "...")
+		assert.Equal(t, len(msg.Alerts), strings.Count(msg.Content, "\n"))
+		delivered = append(delivered, alertIDs(msg.Alerts)...)
+	}
+	assert.Equal(t, alertIDs(alerts), delivered)
+}
+
+func TestWebhookNotifier_TruncatesOversizedAlert(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	notifier := config.NewWebhookNotifier(&testutil.MockLogger{})
+
+	alerts := []model.Alert{
+		{ID: "long", Message: strings.Repeat("y", 2500)},
+		{ID: "short", Message: "short"},
+	}
+	require.NoError(t, notifier.Notify(receiver.URL, model.AlertRules[0], "", alerts))
+
+	messages := receiver.received()
+	require.Len(t, messages, 2)
+	assert.Equal(t, 2000, utf8.RuneCountInString(messages[0].Content))
+	assert.True(t, strings.HasSuffix(messages[0].Content, "..."))
+	assert.Equal(t, "short", messages[1].Content)
+}
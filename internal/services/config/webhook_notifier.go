@@ -0,0 +1,133 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.Notifier = (*webhookNotifier)(nil)
+
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookUsername = "CanIFly"
+	// discordContentLimit is the longest message content Discord accepts
+	discordContentLimit = 2000
+)
+
+// webhookPayload is accepted by both Discord (content, username) and Slack (text) incoming
+// webhooks, each ignores the fields meant for the other. Event and Alerts are for custom receivers.
+type webhookPayload struct {
+	Username string        `json:"username"`
+	Content  string        `json:"content"`
+	Text     string        `json:"text"`
+	Event    string        `json:"event"`
+	Alerts   []model.Alert `json:"alerts"`
+}
+
+type webhookNotifier struct {
+	logger interfaces.Logger
+	client *http.Client
+}
+
+func NewWebhookNotifier(logger interfaces.Logger) interfaces.Notifier {
+	return &webhookNotifier{
+		logger: logger,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// alertMessage is one webhook post and the alerts listed in it
+type alertMessage struct {
+	content string
+	alerts  []model.Alert
+}
+
+// Notify posts the alerts in as many messages as needed to keep each under discordContentLimit,
+// every message is prefixed with the mention. It stops at the first message that fails.
+func (w *webhookNotifier) Notify(url, rule, mention string, alerts []model.Alert) error {
+	messages := splitAlertMessages(mention, alerts)
+	for _, message := range messages {
+		if err := w.post(url, rule, message); err != nil {
+			return err
+		}
+	}
+	if len(messages) > 0 {
+		w.logger.Debugf("posted %d %s alerts to webhook in %d messages", len(alerts), rule, len(messages))
+	}
+	return nil
+}
+
+// splitAlertMessages lists the alerts one per line, starting a new message whenever the next line
+// would take the content past discordContentLimit. A single alert too long on its own is truncated.
+func splitAlertMessages(mention string, alerts []model.Alert) []alertMessage {
+	prefix := 0
+	if mention != "" {
+		prefix = len([]rune(mention)) + 1
+	}
+
+	var messages []alertMessage
+	var lines []string
+	var batch []model.Alert
+	size := prefix
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if mention != "" {
+			lines = append([]string{mention}, lines...)
+		}
+		messages = append(messages, alertMessage{content: strings.Join(lines, "\n"), alerts: batch})
+		lines, batch, size = nil, nil, prefix
+	}
+
+	for _, alert := range alerts {
+		line := alert.Message
+		if runes := []rune(line); prefix+len(runes) > discordContentLimit {
+			line = string(runes[:discordContentLimit-prefix-3]) + "..."
+		}
+		length := len([]rune(line))
+		if len(batch) > 0 {
+			length++ // the newline joining it to the previous alert
+		}
+		if size+length > discordContentLimit {
+			flush()
+			length = len([]rune(line))
+		}
+		lines = append(lines, line)
+		batch = append(batch, alert)
+		size += length
+	}
+	flush()
+	return messages
+}
+
+func (w *webhookNotifier) post(url, rule string, message alertMessage) error {
+	body, err := json.Marshal(webhookPayload{
+		Username: webhookUsername,
+		Content:  message.content,
+		Text:     message.content,
+		Event:    rule,
+		Alerts:   message.alerts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := w.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package eve
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.AssetService = (*assetService)(nil)
+
+const (
+	maxAssetResults = 500
+	// maxContainerDepth guards against cycles when walking from an item up to its location
+	maxContainerDepth = 10
+)
+
+type assetService struct {
+	logger         interfaces.Logger
+	esi            interfaces.ESIService
+	accountService interfaces.AccountService
+	skillService   interfaces.SkillService
+	sysRepo        interfaces.SystemRepository
+	assetRepo      interfaces.AssetRepository
+
+	// mut serializes refreshes and guards the name caches
+	mut       sync.Mutex
+	locations map[int64]resolvedLocation
+	typeNames map[int64]string
+}
+
+type resolvedLocation struct {
+	name     string
+	systemID int64
+}
+
+func NewAssetService(
+	logger interfaces.Logger,
+	esi interfaces.ESIService,
+	accountSvc interfaces.AccountService,
+	skillSvc interfaces.SkillService,
+	sysRepo interfaces.SystemRepository,
+	assetRepo interfaces.AssetRepository,
+) interfaces.AssetService {
+	return &assetService{
+		logger:         logger,
+		esi:            esi,
+		accountService: accountSvc,
+		skillService:   skillSvc,
+		sysRepo:        sysRepo,
+		assetRepo:      assetRepo,
+		locations:      make(map[int64]resolvedLocation),
+		typeNames:      make(map[int64]string),
+	}
+}
+
+// RefreshAssets re-indexes every character. A character whose assets cannot be fetched keeps
+// its previous items and records the error.
+func (a *assetService) RefreshAssets() error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	accounts, err := a.accountService.FetchAccounts()
+	if err != nil {
+		return fmt.Errorf("failed to fetch accounts: %w", err)
+	}
+	index, err := a.assetRepo.FetchAssetIndex()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	current := make(map[int64]bool)
+	for _, account := range accounts {
+		// the index of characters on a server that is not configured is kept, marked with the error
+		esi, esiErr := a.esi.ForServer(account.Server)
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+			current[char.CharacterID] = true
+
+			entry := index.Characters[char.CharacterID]
+			entry.CharacterID = char.CharacterID
+			entry.CharacterName = char.CharacterName
+
+			token := charIdentity.Token
+			var items []model.AssetItem
+			err := esiErr
+			if err == nil {
+				items, err = a.indexCharacter(esi, account.Name, char, &token)
+			}
+			if err != nil {
+				a.logger.Warnf("Failed to index assets for character %d: %v", char.CharacterID, err)
+				entry.LastError = err.Error()
+			} else {
+				entry.Items = items
+				entry.UpdatedAt = now
+				entry.LastError = ""
+			}
+			index.Characters[char.CharacterID] = entry
+		}
+	}
+
+	for id := range index.Characters {
+		if !current[id] {
+			delete(index.Characters, id)
+		}
+	}
+
+	return a.assetRepo.SaveAssetIndex(index)
+}
+
+func (a *assetService) SearchAssets(query, location string) ([]model.AssetItem, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	location = strings.ToLower(strings.TrimSpace(location))
+	if len(terms) == 0 && location == "" {
+		return nil, fmt.Errorf("query or location required")
+	}
+
+	index, err := a.assetRepo.FetchAssetIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.AssetItem, 0)
+	for _, entry := range index.Characters {
+		for _, item := range entry.Items {
+			if !matchesAllTerms(strings.ToLower(item.TypeName+" "+item.Name), terms) {
+				continue
+			}
+			if location != "" &&
+				!strings.Contains(strings.ToLower(item.LocationName), location) &&
+				!strings.Contains(strings.ToLower(item.SystemName), location) {
+				continue
+			}
+			results = append(results, item)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.TypeName != b.TypeName {
+			return a.TypeName < b.TypeName
+		}
+		if a.CharacterName != b.CharacterName {
+			return a.CharacterName < b.CharacterName
+		}
+		if a.LocationName != b.LocationName {
+			return a.LocationName < b.LocationName
+		}
+		return a.ItemID < b.ItemID
+	})
+	if len(results) > maxAssetResults {
+		results = results[:maxAssetResults]
+	}
+	return results, nil
+}
+
+func (a *assetService) GetAssetStatus() ([]model.CharacterAssets, error) {
+	index, err := a.assetRepo.FetchAssetIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]model.CharacterAssets, 0, len(index.Characters))
+	for _, entry := range index.Characters {
+		entry.Items = nil
+		status = append(status, entry)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].CharacterName < status[j].CharacterName })
+	return status, nil
+}
+
+// indexCharacter fetches the character's assets from the server of its account and resolves
+// their names and locations.
+func (a *assetService) indexCharacter(esi interfaces.ESIService, accountName string, char model.Character, token *oauth2.Token) ([]model.AssetItem, error) {
+	assets, err := esi.GetCharacterAssets(char.CharacterID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]model.EsiAsset, len(assets))
+	singletons := make([]int64, 0)
+	for _, asset := range assets {
+		byID[asset.ItemID] = asset
+		if asset.IsSingleton {
+			singletons = append(singletons, asset.ItemID)
+		}
+	}
+
+	names, err := esi.GetCharacterAssetNames(char.CharacterID, token, singletons)
+	if err != nil {
+		a.logger.Warnf("Failed to get asset names for character %d: %v", char.CharacterID, err)
+		names = map[int64]string{}
+	}
+	a.resolveTypeNames(esi, assets)
+
+	items := make([]model.AssetItem, 0, len(assets))
+	for _, asset := range assets {
+		root := asset
+		var container *model.EsiAsset
+		for depth := 0; depth < maxContainerDepth; depth++ {
+			parent, ok := byID[root.LocationID]
+			if !ok {
+				break
+			}
+			if container == nil {
+				container = &parent
+			}
+			root = parent
+		}
+
+		loc := a.resolveLocation(esi, root.LocationID, root.LocationType, token)
+		item := model.AssetItem{
+			CharacterID:   char.CharacterID,
+			CharacterName: char.CharacterName,
+			AccountName:   accountName,
+			ItemID:        asset.ItemID,
+			TypeID:        asset.TypeID,
+			TypeName:      a.typeName(asset.TypeID),
+			Name:          names[asset.ItemID],
+			Quantity:      asset.Quantity,
+			LocationFlag:  asset.LocationFlag,
+			LocationID:    root.LocationID,
+			LocationName:  loc.name,
+			SystemID:      loc.systemID,
+		}
+		if loc.systemID != 0 {
+			item.SystemName = a.sysRepo.GetSystemName(loc.systemID)
+		}
+		if container != nil {
+			item.ContainerID = container.ItemID
+			item.ContainerName = names[container.ItemID]
+			if item.ContainerName == "" {
+				item.ContainerName = a.typeName(container.TypeID)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// resolveTypeNames names every type in assets, from the static type data where possible and otherwise from
+// the ESI of the server the assets were read from.
+func (a *assetService) resolveTypeNames(esi interfaces.ESIService, assets []model.EsiAsset) {
+	unknown := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, asset := range assets {
+		if _, ok := a.typeNames[asset.TypeID]; ok || seen[asset.TypeID] {
+			continue
+		}
+		seen[asset.TypeID] = true
+		if skillType, ok := a.skillService.GetSkillTypeByID(strconv.FormatInt(asset.TypeID, 10)); ok {
+			a.typeNames[asset.TypeID] = skillType.TypeName
+			continue
+		}
+		unknown = append(unknown, asset.TypeID)
+	}
+	if len(unknown) == 0 {
+		return
+	}
+
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+	names, err := esi.ResolveUniverseNames(unknown)
+	if err != nil {
+		a.logger.Warnf("Failed to resolve %d type names: %v", len(unknown), err)
+	}
+	for id, name := range names {
+		a.typeNames[id] = name
+	}
+}
+
+// typeName returns the resolved name of a type. Unresolved types are not cached so the next
+// refresh tries again.
+func (a *assetService) typeName(typeID int64) string {
+	if name, ok := a.typeNames[typeID]; ok {
+		return name
+	}
+	return fmt.Sprintf("Type %d", typeID)
+}
+
+// resolveLocation names a top level location. Systems come from the system data, stations and
+// structures from ESI. Structures only resolve for characters with docking access, so failures
+// are not cached and the next character gets to try.
+func (a *assetService) resolveLocation(esi interfaces.ESIService, id int64, locationType string, token *oauth2.Token) resolvedLocation {
+	if loc, ok := a.locations[id]; ok {
+		return loc
+	}
+
+	var loc resolvedLocation
+	switch {
+	case locationType == "solar_system" || isSolarSystemID(id):
+		loc = resolvedLocation{name: a.sysRepo.GetSystemName(id), systemID: id}
+	case isStationID(id):
+		station, err := esi.GetStation(id)
+		if err != nil {
+			a.logger.Warnf("Failed to resolve station %d: %v", id, err)
+			return resolvedLocation{name: fmt.Sprintf("Station %d", id)}
+		}
+		loc = resolvedLocation{name: station.Name, systemID: station.SystemID}
+	default:
+		structure, err := esi.GetStructure(id, token)
+		if err != nil {
+			a.logger.Debugf("Failed to resolve structure %d: %v", id, err)
+			return resolvedLocation{name: fmt.Sprintf("Structure %d", id)}
+		}
+		loc = resolvedLocation{name: structure.Name, systemID: structure.SystemID}
+	}
+
+	a.locations[id] = loc
+	return loc
+}
+
+func isSolarSystemID(id int64) bool {
+	return id >= 30000000 && id < 33000000
+}
+
+func isStationID(id int64) bool {
+	return id >= 60000000 && id < 64000000
+}
+
+func matchesAllTerms(text string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
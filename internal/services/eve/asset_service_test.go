@@ -0,0 +1,150 @@
+package eve_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/persist/eve"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+const jitaStation = int64(60003760)
+
+func assetAccounts() []model.Account {
+	return []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{
+		{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Hauler"}}},
+		{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Trader"}}},
+	}}}
+}
+
+func newAssetService(t *testing.T) (interfaces.AssetService, *testutil.MockESIService, *eve.AssetStore) {
+	logger := &testutil.MockLogger{}
+	store := eve.NewAssetStore(logger, persist.OSFileSystem{}, t.TempDir())
+
+	esi := &testutil.MockESIService{}
+	accountSvc := &testutil.MockAccountService{}
+	accountSvc.On("FetchAccounts").Return(assetAccounts(), nil)
+
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillTypeByID", "3170").Return(model.SkillType{TypeID: "3170", TypeName: "Light Neutron Blaster II"}, true)
+	skillSvc.On("GetSkillTypeByID", mock.Anything).Return(model.SkillType{}, false)
+
+	sysRepo := &testutil.MockSystemRepository{}
+	sysRepo.On("GetSystemName", int64(30000142)).Return("Jita")
+
+	return eveSvc.NewAssetService(logger, esi, accountSvc, skillSvc, sysRepo, store), esi, store
+}
+
+func TestAssetService_RefreshResolvesContainersAndLocations(t *testing.T) {
+	svc, esi, store := newAssetService(t)
+
+	require.NoError(t, store.SaveAssetIndex(model.AssetIndex{Characters: map[int64]model.CharacterAssets{
+		2: {CharacterID: 2, CharacterName: "Trader", Items: []model.AssetItem{{CharacterID: 2, ItemID: 900, TypeName: "Tritanium"}}},
+	}}))
+
+	esi.On("GetCharacterAssets", int64(1), mock.Anything).Return([]model.EsiAsset{
+		{ItemID: 100, TypeID: 37480, Quantity: 1, LocationID: jitaStation, LocationType: "station", LocationFlag: "Hangar", IsSingleton: true},
+		{ItemID: 101, TypeID: 3170, Quantity: 2, LocationID: 100, LocationType: "item", LocationFlag: "Cargo"},
+	}, nil)
+	esi.On("GetCharacterAssets", int64(2), mock.Anything).Return([]model.EsiAsset(nil), errors.New("token expired"))
+	esi.On("GetCharacterAssetNames", int64(1), mock.Anything, []int64{100}).Return(map[int64]string{100: "Brick"}, nil)
+	esi.On("ResolveUniverseNames", []int64{37480}).Return(map[int64]string{37480: "Ferox Navy Issue"}, nil)
+	esi.On("GetStation", jitaStation).Return(&model.Station{ID: jitaStation, SystemID: 30000142, Name: "Jita IV - Moon 4"}, nil).Once()
+
+	require.NoError(t, svc.RefreshAssets())
+
+	items, err := svc.SearchAssets("neutron blaster", "jita")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(101), items[0].ItemID)
+	assert.Equal(t, "Hauler", items[0].CharacterName)
+	assert.Equal(t, "Main", items[0].AccountName)
+	assert.Equal(t, int64(100), items[0].ContainerID)
+	assert.Equal(t, "Brick", items[0].ContainerName)
+	assert.Equal(t, jitaStation, items[0].LocationID)
+	assert.Equal(t, "Jita IV - Moon 4", items[0].LocationName)
+	assert.Equal(t, "Jita", items[0].SystemName)
+
+	ships, err := svc.SearchAssets("brick", "")
+	require.NoError(t, err)
+	require.Len(t, ships, 1)
+	assert.Equal(t, "Ferox Navy Issue", ships[0].TypeName)
+
+	// the failed character keeps the items from the previous refresh
+	kept, err := svc.SearchAssets("tritanium", "")
+	require.NoError(t, err)
+	assert.Len(t, kept, 1)
+
+	status, err := svc.GetAssetStatus()
+	require.NoError(t, err)
+	require.Len(t, status, 2)
+	assert.Equal(t, "Hauler", status[0].CharacterName)
+	assert.Empty(t, status[0].LastError)
+	assert.Nil(t, status[0].Items)
+	assert.Equal(t, "token expired", status[1].LastError)
+
+	esi.AssertExpectations(t)
+}
+
+func TestAssetService_SearchRequiresQueryOrLocation(t *testing.T) {
+	svc, _, _ := newAssetService(t)
+
+	_, err := svc.SearchAssets("  ", "")
+	assert.Error(t, err)
+}
+
+// serverClients routes ESI calls to the client of each server
+type serverClients struct {
+	*testutil.MockESIService
+	servers map[string]*testutil.MockESIService
+}
+
+func (e serverClients) ForServer(server string) (interfaces.ESIService, error) {
+	name, _ := model.NormalizeServer(server)
+	return e.servers[name], nil
+}
+
+func TestAssetService_ResolvesTypeNamesOnTheAccountServer(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	store := eve.NewAssetStore(logger, persist.OSFileSystem{}, t.TempDir())
+
+	tq := &testutil.MockESIService{}
+	sisi := &testutil.MockESIService{}
+	esi := serverClients{MockESIService: tq, servers: map[string]*testutil.MockESIService{
+		model.ServerTranquility: tq,
+		model.ServerSingularity: sisi,
+	}}
+
+	accountSvc := &testutil.MockAccountService{}
+	accountSvc.On("FetchAccounts").Return([]model.Account{{Name: "Test", Server: model.ServerSingularity, Characters: []model.CharacterIdentity{
+		{Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Tester"}}},
+	}}}, nil)
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillTypeByID", mock.Anything).Return(model.SkillType{}, false)
+	sysRepo := &testutil.MockSystemRepository{}
+	sysRepo.On("GetSystemName", int64(30000142)).Return("Jita")
+
+	sisi.On("GetCharacterAssets", int64(1), mock.Anything).Return([]model.EsiAsset{
+		{ItemID: 100, TypeID: 37480, Quantity: 1, LocationID: jitaStation, LocationType: "station", LocationFlag: "Hangar"},
+	}, nil)
+	sisi.On("GetCharacterAssetNames", int64(1), mock.Anything, []int64{}).Return(map[int64]string{}, nil)
+	sisi.On("ResolveUniverseNames", []int64{37480}).Return(map[int64]string{37480: "Ferox Navy Issue"}, nil)
+	sisi.On("GetStation", jitaStation).Return(&model.Station{ID: jitaStation, SystemID: 30000142, Name: "Jita IV - Moon 4"}, nil)
+
+	svc := eveSvc.NewAssetService(logger, esi, accountSvc, skillSvc, sysRepo, store)
+	require.NoError(t, svc.RefreshAssets())
+
+	items, err := svc.SearchAssets("ferox", "")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	sisi.AssertExpectations(t)
+	tq.AssertNotCalled(t, "ResolveUniverseNames", mock.Anything)
+}
@@ -0,0 +1,140 @@
+package eve
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.CalendarService = (*calendarService)(nil)
+
+const (
+	calendarProductID = "-//CanIFly//Skill Calendar//EN"
+	calendarUIDDomain = "canifly"
+	icsTimeFormat     = "20060102T150405Z"
+	// icsLineLimit is the longest content line RFC 5545 allows before folding, in octets
+	icsLineLimit = 75
+)
+
+type calendarService struct {
+	logger       interfaces.Logger
+	skillService interfaces.SkillService
+}
+
+func NewCalendarService(logger interfaces.Logger, skillSvc interfaces.SkillService) interfaces.CalendarService {
+	return &calendarService{logger: logger, skillService: skillSvc}
+}
+
+// calendarEvent is a single VEVENT, UID stays the same for the same skill level or plan so
+// subscribed calendars move the event when the date changes instead of adding another one.
+type calendarEvent struct {
+	uid         string
+	start       time.Time
+	summary     string
+	description string
+}
+
+func (c *calendarService) BuildCalendar(accounts []model.Account, characterIDs []int64) string {
+	include := make(map[int64]bool, len(characterIDs))
+	for _, id := range characterIDs {
+		include[id] = true
+	}
+
+	events := make([]calendarEvent, 0)
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+			if len(include) > 0 && !include[char.CharacterID] {
+				continue
+			}
+
+			for _, q := range char.SkillQueue {
+				if q.FinishDate == nil {
+					continue
+				}
+				skillName := c.skillService.GetSkillName(q.SkillID)
+				if skillName == "" {
+					skillName = fmt.Sprintf("Skill %d", q.SkillID)
+				}
+				events = append(events, calendarEvent{
+					uid:         fmt.Sprintf("skill-%d-%d-%d@%s", char.CharacterID, q.SkillID, q.FinishedLevel, calendarUIDDomain),
+					start:       *q.FinishDate,
+					summary:     fmt.Sprintf("%s: %s %d complete", char.CharacterName, skillName, q.FinishedLevel),
+					description: fmt.Sprintf("%s (%s) finishes training %s to level %d.", char.CharacterName, account.Name, skillName, q.FinishedLevel),
+				})
+			}
+
+			for planName, finish := range char.PendingFinishDates {
+				if finish == nil || !char.PendingPlans[planName] {
+					continue
+				}
+				events = append(events, calendarEvent{
+					uid:         fmt.Sprintf("plan-%d-%s@%s", char.CharacterID, url.PathEscape(planName), calendarUIDDomain),
+					start:       *finish,
+					summary:     fmt.Sprintf("%s ready for %s", char.CharacterName, planName),
+					description: fmt.Sprintf("%s (%s) finishes the last queued skill for %s.", char.CharacterName, account.Name, planName),
+				})
+			}
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].start.Equal(events[j].start) {
+			return events[i].start.Before(events[j].start)
+		}
+		return events[i].uid < events[j].uid
+	})
+
+	stamp := time.Now().UTC().Format(icsTimeFormat)
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:"+calendarProductID)
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:EVE Skill Training")
+	for _, event := range events {
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+event.uid)
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART:"+event.start.UTC().Format(icsTimeFormat))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(event.summary))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(event.description))
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// writeICSLine writes a CRLF terminated content line, folding it at the octet limit without
+// splitting a UTF-8 sequence.
+func writeICSLine(b *strings.Builder, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space that counts towards the limit
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
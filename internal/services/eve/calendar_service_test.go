@@ -0,0 +1,82 @@
+package eve_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guarzo/canifly/internal/model"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func calendarAccounts(skillFinish, planFinish time.Time) []model.Account {
+	return []model.Account{{
+		Name: "Main",
+		Characters: []model.CharacterIdentity{
+			{Character: model.Character{
+				UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+				SkillQueue:       []model.SkillQueue{{SkillID: 3300, FinishedLevel: 4, FinishDate: &skillFinish}},
+				PendingPlans:     map[string]bool{"Ferox, Fleet": true},
+				PendingFinishDates: map[string]*time.Time{
+					"Ferox, Fleet": &planFinish,
+					"Stale Plan":   &planFinish,
+				},
+			}},
+			{Character: model.Character{
+				UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Alt"},
+				SkillQueue:       []model.SkillQueue{{SkillID: 3300, FinishedLevel: 1, FinishDate: &skillFinish}},
+			}},
+		},
+	}}
+}
+
+func TestCalendarService_BuildCalendar(t *testing.T) {
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillName", int32(3300)).Return("Gunnery")
+	svc := eveSvc.NewCalendarService(&testutil.MockLogger{}, skillSvc)
+
+	skillFinish := time.Date(2026, 11, 2, 14, 30, 0, 0, time.UTC)
+	planFinish := time.Date(2026, 11, 5, 8, 0, 0, 0, time.UTC)
+	feed := svc.BuildCalendar(calendarAccounts(skillFinish, planFinish), nil)
+
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 3, strings.Count(feed, "BEGIN:VEVENT"))
+	assert.Contains(t, feed, "UID:skill-1-3300-4@canifly\r\nDTSTAMP:")
+	assert.Contains(t, feed, "DTSTART:20261102T143000Z\r\nSUMMARY:Pilot: Gunnery 4 complete\r\n")
+	assert.Contains(t, feed, "UID:skill-2-3300-1@canifly")
+	assert.Contains(t, feed, "UID:plan-1-Ferox%2C%20Fleet@canifly")
+	assert.Contains(t, feed, `SUMMARY:Pilot ready for Ferox\, Fleet`)
+	assert.NotContains(t, feed, "Stale Plan")
+
+	for _, line := range strings.Split(feed, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+}
+
+func TestCalendarService_UIDsStableWhenDatesMove(t *testing.T) {
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillName", int32(3300)).Return("Gunnery")
+	svc := eveSvc.NewCalendarService(&testutil.MockLogger{}, skillSvc)
+
+	uids := func(feed string) []string {
+		var out []string
+		for _, line := range strings.Split(feed, "\r\n") {
+			if strings.HasPrefix(line, "UID:") {
+				out = append(out, line)
+			}
+		}
+		return out
+	}
+
+	base := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	before := svc.BuildCalendar(calendarAccounts(base, base.Add(48*time.Hour)), []int64{1})
+	after := svc.BuildCalendar(calendarAccounts(base.Add(6*time.Hour), base.Add(72*time.Hour)), []int64{1})
+
+	assert.Equal(t, uids(before), uids(after))
+	assert.Len(t, uids(before), 2)
+	assert.NotContains(t, before, "Alt")
+}
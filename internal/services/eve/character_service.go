@@ -2,10 +2,12 @@
 package eve
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
@@ -36,51 +38,72 @@ func NewCharacterService(esi interfaces.ESIService,
 	}
 }
 
-func (c *characterService) ProcessIdentity(charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error) {
+func (c *characterService) ProcessIdentity(charIdentity *model.CharacterIdentity, server string) (*model.CharacterIdentity, error) {
 	c.logger.Debugf("Processing identity for character ID: %d", charIdentity.Character.CharacterID)
+	esi, err := c.esi.ForServer(server)
+	if err != nil {
+		return nil, err
+	}
 
-	user, err := c.esi.GetUserInfo(&charIdentity.Token)
+	user, err := esi.GetUserInfo(&charIdentity.Token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %v", err)
+		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 	c.logger.Debugf("Fetched user info for character %s (ID: %d)", user.CharacterName, user.CharacterID)
 
-	characterResponse, err := c.esi.GetCharacter(strconv.FormatInt(charIdentity.Character.CharacterID, 10))
+	characterResponse, err := esi.GetCharacter(strconv.FormatInt(charIdentity.Character.CharacterID, 10))
 	if err != nil {
 		c.logger.Warnf("Failed to get character %s: %v", charIdentity.Character.CharacterName, err)
 	}
 
-	skills, err := c.esi.GetCharacterSkills(charIdentity.Character.CharacterID, &charIdentity.Token)
+	skills, err := esi.GetCharacterSkills(charIdentity.Character.CharacterID, &charIdentity.Token)
+	if errors.Is(err, flyErrors.ErrTokenRevoked) {
+		return nil, fmt.Errorf("failed to get skills: %w", err)
+	}
 	if err != nil {
 		c.logger.Warnf("Failed to get skills for character %d: %v", charIdentity.Character.CharacterID, err)
 		skills = &model.CharacterSkillsResponse{Skills: []model.SkillResponse{}}
 	}
 	c.logger.Debugf("Fetched %d skills for character %d", len(skills.Skills), charIdentity.Character.CharacterID)
 
-	skillQueue, err := c.esi.GetCharacterSkillQueue(charIdentity.Character.CharacterID, &charIdentity.Token)
+	skillQueue, err := esi.GetCharacterSkillQueue(charIdentity.Character.CharacterID, &charIdentity.Token)
+	if errors.Is(err, flyErrors.ErrTokenRevoked) {
+		return nil, fmt.Errorf("failed to get skill queue: %w", err)
+	}
 	if err != nil {
 		c.logger.Warnf("Failed to get eve queue for character %d: %v", charIdentity.Character.CharacterID, err)
 		skillQueue = &[]model.SkillQueue{}
 	}
 	c.logger.Debugf("Fetched %d eve queue entries for character %d", len(*skillQueue), charIdentity.Character.CharacterID)
 
-	characterLocation, err := c.esi.GetCharacterLocation(charIdentity.Character.CharacterID, &charIdentity.Token)
+	characterLocation, err := esi.GetCharacterLocation(charIdentity.Character.CharacterID, &charIdentity.Token)
+	if errors.Is(err, flyErrors.ErrTokenRevoked) {
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
 	if err != nil {
 		c.logger.Warnf("Failed to get location for character %d: %v", charIdentity.Character.CharacterID, err)
 		characterLocation = 0
 	}
 
+	var walletBalance *float64
+	if balance, err := esi.GetCharacterWallet(charIdentity.Character.CharacterID, &charIdentity.Token); err != nil {
+		// Characters added before the wallet scope existed need to log in again
+		c.logger.Warnf("Failed to get wallet for character %d: %v", charIdentity.Character.CharacterID, err)
+	} else {
+		walletBalance = &balance
+	}
+
 	corporationName := ""
 	allianceName := ""
 	if characterResponse != nil {
-		characterCorporation, err := c.esi.GetCorporation(int64(characterResponse.CorporationID), &charIdentity.Token)
+		characterCorporation, err := esi.GetCorporation(int64(characterResponse.CorporationID), &charIdentity.Token)
 		if err != nil {
 			c.logger.Warnf("Failed to get corporation for corporation %d: %v", characterResponse.CorporationID, err)
 		} else {
 			corporationName = characterCorporation.Name
 		}
 		if characterCorporation != nil && characterCorporation.AllianceID != 0 {
-			characterAlliance, err := c.esi.GetAlliance(int64(characterCorporation.AllianceID), &charIdentity.Token)
+			characterAlliance, err := esi.GetAlliance(int64(characterCorporation.AllianceID), &charIdentity.Token)
 			if err != nil {
 				c.logger.Warnf("Failed to get alliance for character %s: %v", characterCorporation.AllianceID, err)
 			} else {
@@ -98,6 +121,9 @@ func (c *characterService) ProcessIdentity(charIdentity *model.CharacterIdentity
 	charIdentity.Character.SkillQueue = *skillQueue
 	charIdentity.Character.Location = characterLocation
 	charIdentity.Character.LocationName = c.sysRepo.GetSystemName(charIdentity.Character.Location)
+	if walletBalance != nil {
+		charIdentity.Character.WalletBalance = walletBalance
+	}
 	charIdentity.MCT = c.isCharacterTraining(*skillQueue)
 	if charIdentity.MCT {
 		charIdentity.Training = c.skillService.GetSkillName(charIdentity.Character.SkillQueue[0].SkillID)
@@ -119,7 +145,7 @@ func (c *characterService) ProcessIdentity(charIdentity *model.CharacterIdentity
 		charIdentity.Character.MissingSkills = make(map[string]map[string]int32)
 	}
 
-	err = c.esi.SaveEsiCache()
+	err = esi.SaveEsiCache()
 	if err != nil {
 		c.logger.WithError(err).Infof("failed to save esi cache after processing identity")
 	}
@@ -2,14 +2,17 @@ package eve_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/eve"
 	"github.com/guarzo/canifly/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 )
 
@@ -67,6 +70,7 @@ func TestProcessIdentity_Success(t *testing.T) {
 	esi.On("GetCharacterSkillQueue", charId, &charIdentity.Token).Return(queue, nil).Once()
 
 	esi.On("GetCharacterLocation", charId, &charIdentity.Token).Return(int64(1000), nil).Once()
+	esi.On("GetCharacterWallet", charId, &charIdentity.Token).Return(1250000.5, nil).Once()
 
 	sys.On("GetSystemName", int64(1000)).Return("Jita").Once()
 
@@ -75,12 +79,14 @@ func TestProcessIdentity_Success(t *testing.T) {
 
 	esi.On("SaveEsiCache").Return(nil).Once()
 
-	updated, err := charSvc.ProcessIdentity(charIdentity)
+	updated, err := charSvc.ProcessIdentity(charIdentity, "")
 	assert.NoError(t, err)
 	assert.Equal(t, "TestChar", updated.Character.CharacterName)
 	assert.Len(t, updated.Character.Skills, 1)
 	assert.Len(t, updated.Character.SkillQueue, 1)
 	assert.Equal(t, "Jita", updated.Character.LocationName)
+	require.NotNil(t, updated.Character.WalletBalance)
+	assert.Equal(t, 1250000.5, *updated.Character.WalletBalance)
 	assert.True(t, updated.MCT)
 	assert.Equal(t, "Some Skill", updated.Training)
 	assert.Equal(t, "TestCorp", updated.CorporationName)
@@ -104,7 +110,7 @@ func TestProcessIdentity_UserInfoError(t *testing.T) {
 	charIdentity := &model.CharacterIdentity{Token: oauth2.Token{}}
 	esi.On("GetUserInfo", &charIdentity.Token).Return((*model.UserInfoResponse)(nil), errors.New("user info error")).Once()
 
-	_, err := charSvc.ProcessIdentity(charIdentity)
+	_, err := charSvc.ProcessIdentity(charIdentity, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get user info")
 
@@ -354,3 +360,25 @@ func TestRemoveCharacter_SaveError(t *testing.T) {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestProcessIdentity_StopsOnRevokedToken(t *testing.T) {
+	esi := &testutil.MockESIService{}
+	charSvc := eve.NewCharacterService(esi, &testutil.MockLogger{}, &testutil.MockSystemRepository{}, &testutil.MockSkillService{},
+		&testutil.MockAccountService{}, &testutil.MockConfigService{})
+
+	charId := int64(12345)
+	charIdentity := &model.CharacterIdentity{
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: charId, CharacterName: "TestChar"},
+			CharacterSkillsResponse: model.CharacterSkillsResponse{TotalSP: 1000}},
+	}
+	esi.On("GetUserInfo", &charIdentity.Token).Return(&model.UserInfoResponse{CharacterID: charId}, nil).Once()
+	esi.On("GetCharacter", "12345").Return(&model.CharacterResponse{Name: "TestChar"}, nil).Once()
+	esi.On("GetCharacterSkills", charId, &charIdentity.Token).
+		Return((*model.CharacterSkillsResponse)(nil), fmt.Errorf("failed to refresh token: %w", flyErrors.ErrTokenRevoked)).Once()
+
+	_, err := charSvc.ProcessIdentity(charIdentity, "")
+	require.ErrorIs(t, err, flyErrors.ErrTokenRevoked)
+	// the skills from the last refresh are kept
+	assert.Equal(t, int64(1000), charIdentity.Character.TotalSP)
+	esi.AssertExpectations(t)
+}
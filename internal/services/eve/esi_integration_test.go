@@ -0,0 +1,221 @@
+package eve_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	flyHttp "github.com/guarzo/canifly/internal/http"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/persist/eve"
+	accountSvc "github.com/guarzo/canifly/internal/services/account"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+	"github.com/guarzo/canifly/internal/testutil"
+	"github.com/guarzo/canifly/internal/testutil/fakeesi"
+)
+
+type fakePipeline struct {
+	fake      *fakeesi.Server
+	sisi      *fakeesi.Server
+	esi       interfaces.ESIService
+	character interfaces.CharacterService
+	deleted   *eve.DeletedStore
+}
+
+// newFakePipeline wires the real ESI client, auth client, ESI service and character service to a
+// fake Tranquility and a fake Singularity.
+func newFakePipeline(t *testing.T) fakePipeline {
+	fake := fakeesi.New()
+	t.Cleanup(fake.Close)
+	sisi := fakeesi.NewForServer(model.ServerSingularity)
+	t.Cleanup(sisi.Close)
+
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	auth := accountSvc.NewAuthClient(logger, "client", "secret", "http://localhost/callback", fake.URL)
+	cache := eveSvc.NewCacheService(logger, eve.NewCacheStore(logger, fs, basePath))
+	deleted := eve.NewDeletedStore(logger, fs, basePath)
+	client := flyHttp.NewEsiHttpClient(fake.URL, logger, auth, cache, nil)
+	sisiAuth := accountSvc.NewAuthClient(logger, "sisi-client", "secret", "http://localhost/callback", sisi.URL)
+	sisiClient := flyHttp.NewEsiHttpClient(sisi.URL, logger, sisiAuth, cache, nil)
+	esi := eveSvc.NewESIService(logger, cache, deleted, map[string]eveSvc.EsiServer{
+		model.ServerTranquility: {APIClient: client, SSOBaseURL: fake.URL},
+		model.ServerSingularity: {APIClient: sisiClient, SSOBaseURL: sisi.URL},
+	})
+
+	sysRepo := &testutil.MockSystemRepository{}
+	sysRepo.On("GetSystemName", fakeesi.JitaSystemID).Return("Jita")
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillName", int32(3300)).Return("Gunnery")
+	character := eveSvc.NewCharacterService(esi, logger, sysRepo, skillSvc, &testutil.MockAccountService{}, &testutil.MockConfigService{})
+
+	return fakePipeline{fake: fake, sisi: sisi, esi: esi, character: character, deleted: deleted}
+}
+
+func characterPath(id int64, resource string) string {
+	return fmt.Sprintf("/latest/characters/%d/%s", id, resource)
+}
+
+func TestFakeESI_ProcessIdentityRefreshesExpiredToken(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.fake.Login(fakeesi.PilotID)
+	expired := token.AccessToken
+	p.fake.ExpireAccessTokens()
+
+	identity := &model.CharacterIdentity{
+		Token:     *token,
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: fakeesi.PilotID}},
+	}
+	updated, err := p.character.ProcessIdentity(identity, "")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, expired, updated.Token.AccessToken)
+	assert.Equal(t, "Fixture Pilot", updated.Character.CharacterName)
+	assert.Len(t, updated.Character.Skills, 2)
+	assert.Equal(t, int64(5000000), updated.Character.TotalSP)
+	require.Len(t, updated.Character.SkillQueue, 1)
+	assert.Equal(t, "Jita", updated.Character.LocationName)
+	require.NotNil(t, updated.Character.WalletBalance)
+	assert.Equal(t, 1500000000.0, *updated.Character.WalletBalance)
+	assert.Equal(t, "Fixture Corporation", updated.CorporationName)
+	assert.Equal(t, "Fixture Alliance", updated.AllianceName)
+	assert.True(t, updated.MCT)
+	assert.Equal(t, "Gunnery", updated.Training)
+}
+
+func TestFakeESI_RevokedRefreshToken(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.fake.Login(fakeesi.AltID)
+	p.fake.ExpireAccessTokens()
+	p.fake.RevokeRefreshTokens()
+
+	identity := &model.CharacterIdentity{
+		Token:     *token,
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: fakeesi.AltID}},
+	}
+	_, err := p.character.ProcessIdentity(identity, "")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, flyErrors.ErrTokenRevoked))
+}
+
+func TestFakeESI_TokenOfAnotherCharacter(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.fake.Login(fakeesi.AltID)
+
+	_, err := p.esi.GetCharacterSkills(fakeesi.PilotID, token)
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusForbidden, customErr.StatusCode)
+	// refreshed once, not forever
+	assert.Equal(t, 2, p.fake.Hits(characterPath(fakeesi.PilotID, "skills/")))
+}
+
+func TestFakeESI_DeletedCharacterIsRemembered(t *testing.T) {
+	p := newFakePipeline(t)
+	p.fake.RemoveCharacter(fakeesi.AltID)
+
+	pilot, alt := strconv.FormatInt(fakeesi.PilotID, 10), strconv.FormatInt(fakeesi.AltID, 10)
+	names, err := p.esi.ResolveCharacterNames([]string{pilot, alt})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{pilot: "Fixture Pilot"}, names)
+
+	deleted, err := p.deleted.FetchDeletedCharacters()
+	require.NoError(t, err)
+	assert.Equal(t, []string{alt}, deleted)
+}
+
+func TestFakeESI_ErrorLimitIsNotRetried(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.fake.Login(fakeesi.PilotID)
+	path := characterPath(fakeesi.PilotID, "skills/")
+	p.fake.FailNext(path, 420, 1)
+
+	_, err := p.esi.GetCharacterSkills(fakeesi.PilotID, token)
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, 420, customErr.StatusCode)
+	assert.Equal(t, 1, p.fake.Hits(path))
+}
+
+func TestFakeESI_ServerErrorIsRetried(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.fake.Login(fakeesi.PilotID)
+	path := characterPath(fakeesi.PilotID, "skillqueue/")
+	p.fake.FailNext(path, http.StatusServiceUnavailable, 1)
+
+	queue, err := p.esi.GetCharacterSkillQueue(fakeesi.PilotID, token)
+	require.NoError(t, err)
+	assert.Len(t, *queue, 1)
+	assert.Equal(t, 2, p.fake.Hits(path))
+}
+
+func TestFakeESI_SingularityAccountUsesItsServer(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.sisi.Login(fakeesi.PilotID)
+	p.sisi.ExpireAccessTokens()
+
+	identity := &model.CharacterIdentity{
+		Token:     *token,
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: fakeesi.PilotID}},
+	}
+	updated, err := p.character.ProcessIdentity(identity, model.ServerSingularity)
+	require.NoError(t, err)
+	assert.Equal(t, "Fixture Pilot", updated.Character.CharacterName)
+	assert.Len(t, updated.Character.Skills, 2)
+	assert.Equal(t, 0, p.fake.Hits(characterPath(fakeesi.PilotID, "skills/")))
+
+	// Tranquility does not know Singularity tokens
+	_, err = p.character.ProcessIdentity(&model.CharacterIdentity{Token: updated.Token, Character: identity.Character}, "")
+	assert.Error(t, err)
+}
+
+func TestFakeESI_UnconfiguredServerIsNotReached(t *testing.T) {
+	fake := fakeesi.New()
+	t.Cleanup(fake.Close)
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	cache := eveSvc.NewCacheService(logger, eve.NewCacheStore(logger, persist.OSFileSystem{}, basePath))
+	auth := accountSvc.NewAuthClient(logger, "client", "secret", "http://localhost/callback", fake.URL)
+	esi := eveSvc.NewESIService(logger, cache, eve.NewDeletedStore(logger, persist.OSFileSystem{}, basePath), map[string]eveSvc.EsiServer{
+		model.ServerTranquility: {APIClient: flyHttp.NewEsiHttpClient(fake.URL, logger, auth, cache, nil), SSOBaseURL: fake.URL},
+	})
+
+	_, err := esi.ForServer(model.ServerSingularity)
+	assert.True(t, errors.Is(err, flyErrors.ErrServerNotConfigured))
+
+	character := eveSvc.NewCharacterService(esi, logger, &testutil.MockSystemRepository{}, &testutil.MockSkillService{}, &testutil.MockAccountService{}, &testutil.MockConfigService{})
+	_, err = character.ProcessIdentity(&model.CharacterIdentity{
+		Token:     oauth2.Token{AccessToken: "sisi-access", RefreshToken: "sisi-refresh"},
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: fakeesi.PilotID}},
+	}, model.ServerSingularity)
+	assert.True(t, errors.Is(err, flyErrors.ErrServerNotConfigured))
+	assert.False(t, errors.Is(err, flyErrors.ErrTokenRevoked))
+}
+
+func TestFakeESI_RejectedClientDoesNotRevokeTokens(t *testing.T) {
+	p := newFakePipeline(t)
+	token := p.fake.Login(fakeesi.AltID)
+	p.fake.ExpireAccessTokens()
+	// SSO rejects the client credentials, the refresh token itself is fine
+	p.fake.FailNext("/v2/oauth/token", http.StatusUnauthorized, 1)
+
+	identity := &model.CharacterIdentity{
+		Token:     *token,
+		Character: model.Character{UserInfoResponse: model.UserInfoResponse{CharacterID: fakeesi.AltID}},
+	}
+	_, err := p.character.ProcessIdentity(identity, "")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, flyErrors.ErrTokenRefreshFailed))
+	assert.False(t, errors.Is(err, flyErrors.ErrTokenRevoked))
+}
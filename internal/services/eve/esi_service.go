@@ -16,28 +16,60 @@ import (
 
 var _ interfaces.ESIService = (*esiService)(nil)
 
+const (
+	assetPageSize      = 1000
+	maxAssetPages      = 100
+	maxNamesPerRequest = 1000
+)
+
+// EsiServer is how one EVE server is reached. ESI serves every server from the same host and
+// picks one by datasource, but tokens are issued and refreshed by each server's own SSO.
+type EsiServer struct {
+	APIClient  interfaces.EsiHttpClient
+	SSOBaseURL string
+}
+
 type esiService struct {
 	apiClient    interfaces.EsiHttpClient
-	auth         interfaces.AuthClient
 	logger       interfaces.Logger
 	deleted      interfaces.DeletedCharactersRepository
 	cacheService interfaces.CacheService
+	ssoBaseURL   string
+	datasource   string
+	servers      map[string]*esiService
 }
 
+// NewESIService returns the service for Tranquility, servers must have an entry for
+// model.ServerTranquility. The other servers are reached through ForServer.
 func NewESIService(
-	apiClient interfaces.EsiHttpClient,
-	auth interfaces.AuthClient,
 	logger interfaces.Logger,
 	cache interfaces.CacheService,
-	deleted interfaces.DeletedCharactersRepository) interfaces.ESIService {
+	deleted interfaces.DeletedCharactersRepository,
+	servers map[string]EsiServer) interfaces.ESIService {
 
-	return &esiService{
-		apiClient:    apiClient,
-		auth:         auth,
-		logger:       logger,
-		cacheService: cache,
-		deleted:      deleted,
+	bound := make(map[string]*esiService, len(servers))
+	for name, server := range servers {
+		bound[name] = &esiService{
+			apiClient:    server.APIClient,
+			logger:       logger,
+			cacheService: cache,
+			deleted:      deleted,
+			ssoBaseURL:   server.SSOBaseURL,
+			datasource:   name,
+			servers:      bound,
+		}
 	}
+	return bound[model.ServerTranquility]
+}
+
+// ForServer returns the service bound to server. A server without an SSO client has none, sending its
+// tokens to another server would only get them rejected there.
+func (s *esiService) ForServer(server string) (interfaces.ESIService, error) {
+	name, _ := model.NormalizeServer(server)
+	if bound, ok := s.servers[name]; ok {
+		return bound, nil
+	}
+	return nil, fmt.Errorf("%w: %s", flyErrors.ErrServerNotConfigured, name)
 }
 
 func (s *esiService) SaveEsiCache() error {
@@ -86,7 +118,7 @@ func (s *esiService) GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse,
 	}
 
 	var user model.UserInfoResponse
-	if err := s.apiClient.GetJSONFromURL("https://login.eveonline.com/oauth/verify", token, false, &user); err != nil {
+	if err := s.apiClient.GetJSONFromURL(s.ssoBaseURL+"/oauth/verify", token, false, &user); err != nil {
 		return nil, fmt.Errorf("failed to decode user info: %w", err)
 	}
 
@@ -95,7 +127,7 @@ func (s *esiService) GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse,
 
 func (s *esiService) GetCharacter(id string) (*model.CharacterResponse, error) {
 	var character model.CharacterResponse
-	endpoint := fmt.Sprintf("/latest/characters/%s/?datasource=tranquility", id)
+	endpoint := fmt.Sprintf("/latest/characters/%s/?datasource=%s", id, s.datasource)
 	if err := s.apiClient.GetJSON(endpoint, nil, true, &character); err != nil {
 		return nil, fmt.Errorf("failed to decode character response: %w", err)
 	}
@@ -104,7 +136,7 @@ func (s *esiService) GetCharacter(id string) (*model.CharacterResponse, error) {
 
 func (s *esiService) GetCharacterSkills(characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error) {
 	var skills model.CharacterSkillsResponse
-	endpoint := fmt.Sprintf("/latest/characters/%d/skills/?datasource=tranquility", characterID)
+	endpoint := fmt.Sprintf("/latest/characters/%d/skills/?datasource=%s", characterID, s.datasource)
 	if err := s.apiClient.GetJSON(endpoint, token, true, &skills); err != nil {
 		return nil, fmt.Errorf("failed to decode character skills: %w", err)
 	}
@@ -113,7 +145,7 @@ func (s *esiService) GetCharacterSkills(characterID int64, token *oauth2.Token)
 
 func (s *esiService) GetCharacterSkillQueue(characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error) {
 	var queue []model.SkillQueue
-	endpoint := fmt.Sprintf("/latest/characters/%d/skillqueue/?datasource=tranquility", characterID)
+	endpoint := fmt.Sprintf("/latest/characters/%d/skillqueue/?datasource=%s", characterID, s.datasource)
 	if err := s.apiClient.GetJSON(endpoint, token, true, &queue); err != nil {
 		return nil, fmt.Errorf("failed to decode eve queue: %w", err)
 	}
@@ -122,7 +154,7 @@ func (s *esiService) GetCharacterSkillQueue(characterID int64, token *oauth2.Tok
 
 func (s *esiService) GetCharacterLocation(characterID int64, token *oauth2.Token) (int64, error) {
 	var location model.CharacterLocation
-	endpoint := fmt.Sprintf("/latest/characters/%d/location/?datasource=tranquility", characterID)
+	endpoint := fmt.Sprintf("/latest/characters/%d/location/?datasource=%s", characterID, s.datasource)
 	s.logger.Debugf("Getting character location for %d", characterID)
 
 	if err := s.apiClient.GetJSON(endpoint, token, true, &location); err != nil {
@@ -132,9 +164,125 @@ func (s *esiService) GetCharacterLocation(characterID int64, token *oauth2.Token
 	return location.SolarSystemID, nil
 }
 
+func (s *esiService) GetCharacterWallet(characterID int64, token *oauth2.Token) (float64, error) {
+	var balance float64
+	endpoint := fmt.Sprintf("/latest/characters/%d/wallet/?datasource=%s", characterID, s.datasource)
+	if err := s.apiClient.GetJSON(endpoint, token, true, &balance); err != nil {
+		return 0, fmt.Errorf("failed to decode wallet balance: %w", err)
+	}
+	return balance, nil
+}
+
+// GetCharacterWalletJournal returns the first page of the journal, the most recent entries.
+func (s *esiService) GetCharacterWalletJournal(characterID int64, token *oauth2.Token) ([]model.WalletJournalEntry, error) {
+	var journal []model.WalletJournalEntry
+	endpoint := fmt.Sprintf("/latest/characters/%d/wallet/journal/?datasource=%s", characterID, s.datasource)
+	if err := s.apiClient.GetJSON(endpoint, token, true, &journal); err != nil {
+		return nil, fmt.Errorf("failed to decode wallet journal: %w", err)
+	}
+	return journal, nil
+}
+
+// GetCharacterAssets fetches every page of the character's assets. ESI serves full pages of
+// assetPageSize items, so a shorter page is the last one.
+func (s *esiService) GetCharacterAssets(characterID int64, token *oauth2.Token) ([]model.EsiAsset, error) {
+	assets := make([]model.EsiAsset, 0)
+	for page := 1; page <= maxAssetPages; page++ {
+		var pageAssets []model.EsiAsset
+		endpoint := fmt.Sprintf("/latest/characters/%d/assets/?datasource=%s&page=%d", characterID, s.datasource, page)
+		if err := s.apiClient.GetJSON(endpoint, token, true, &pageAssets); err != nil {
+			var customErr *flyErrors.CustomError
+			// A character with exactly a multiple of assetPageSize items ends on an empty page that does not exist
+			if page > 1 && errors.As(err, &customErr) && customErr.StatusCode == http.StatusNotFound {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode assets page %d: %w", page, err)
+		}
+		assets = append(assets, pageAssets...)
+		if len(pageAssets) < assetPageSize {
+			break
+		}
+	}
+	return assets, nil
+}
+
+// GetCharacterAssetNames returns the names players gave to assembled ships and containers.
+func (s *esiService) GetCharacterAssetNames(characterID int64, token *oauth2.Token, itemIDs []int64) (map[int64]string, error) {
+	names := make(map[int64]string)
+	endpoint := fmt.Sprintf("/latest/characters/%d/assets/names/?datasource=%s", characterID, s.datasource)
+	for start := 0; start < len(itemIDs); start += maxNamesPerRequest {
+		end := min(start+maxNamesPerRequest, len(itemIDs))
+		var resolved []model.UniverseName
+		if err := s.apiClient.PostJSON(endpoint, token, itemIDs[start:end], &resolved); err != nil {
+			return nil, fmt.Errorf("failed to decode asset names: %w", err)
+		}
+		for _, n := range resolved {
+			// ESI answers "None" for items that were never named
+			if n.Name != "" && n.Name != "None" {
+				names[n.ItemID] = n.Name
+			}
+		}
+	}
+	return names, nil
+}
+
+func (s *esiService) GetStation(id int64) (*model.Station, error) {
+	var station model.Station
+	endpoint := fmt.Sprintf("/latest/universe/stations/%d/?datasource=%s", id, s.datasource)
+	if err := s.apiClient.GetJSON(endpoint, nil, true, &station); err != nil {
+		return nil, fmt.Errorf("failed to decode station: %w", err)
+	}
+	return &station, nil
+}
+
+// GetStructure needs a token of a character with docking access to the structure.
+func (s *esiService) GetStructure(id int64, token *oauth2.Token) (*model.Structure, error) {
+	var structure model.Structure
+	endpoint := fmt.Sprintf("/latest/universe/structures/%d/?datasource=%s", id, s.datasource)
+	if err := s.apiClient.GetJSON(endpoint, token, true, &structure); err != nil {
+		return nil, fmt.Errorf("failed to decode structure: %w", err)
+	}
+	return &structure, nil
+}
+
+// ResolveUniverseNames resolves type, station and other universe IDs to names in bulk.
+func (s *esiService) ResolveUniverseNames(ids []int64) (map[int64]string, error) {
+	names := make(map[int64]string)
+	for start := 0; start < len(ids); start += maxNamesPerRequest {
+		end := min(start+maxNamesPerRequest, len(ids))
+		var resolved []model.UniverseName
+		if err := s.apiClient.PostJSON("/latest/universe/names/?datasource="+s.datasource, nil, ids[start:end], &resolved); err != nil {
+			return nil, fmt.Errorf("failed to decode universe names: %w", err)
+		}
+		for _, n := range resolved {
+			names[n.ID] = n.Name
+		}
+	}
+	return names, nil
+}
+
+func (s *esiService) GetCharacterAttributes(characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error) {
+	var attributes model.CharacterAttributes
+	endpoint := fmt.Sprintf("/latest/characters/%d/attributes/?datasource=%s", characterID, s.datasource)
+	if err := s.apiClient.GetJSON(endpoint, token, true, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to decode character attributes: %w", err)
+	}
+	return &attributes, nil
+}
+
+// GetType returns a type with its dogma attributes, for skills these hold the rank and training attributes.
+func (s *esiService) GetType(typeID int64) (*model.EsiType, error) {
+	var esiType model.EsiType
+	endpoint := fmt.Sprintf("/latest/universe/types/%d/?datasource=%s", typeID, s.datasource)
+	if err := s.apiClient.GetJSON(endpoint, nil, true, &esiType); err != nil {
+		return nil, fmt.Errorf("failed to decode type %d: %w", typeID, err)
+	}
+	return &esiType, nil
+}
+
 func (s *esiService) GetCorporation(corporationID int64, token *oauth2.Token) (*model.Corporation, error) {
 	var corporation model.Corporation
-	endpoint := fmt.Sprintf("/latest/corporations/%d/?datasource=tranquility", corporationID)
+	endpoint := fmt.Sprintf("/latest/corporations/%d/?datasource=%s", corporationID, s.datasource)
 
 	if err := s.apiClient.GetJSON(endpoint, token, true, &corporation); err != nil {
 		return nil, fmt.Errorf("failed to decode corporation: %w", err)
@@ -144,7 +292,7 @@ func (s *esiService) GetCorporation(corporationID int64, token *oauth2.Token) (*
 
 func (s *esiService) GetAlliance(allianceID int64, token *oauth2.Token) (*model.Alliance, error) {
 	var alliance model.Alliance
-	endpoint := fmt.Sprintf("/latest/alliances/%d/?datasource=tranquility", allianceID)
+	endpoint := fmt.Sprintf("/latest/alliances/%d/?datasource=%s", allianceID, s.datasource)
 
 	if err := s.apiClient.GetJSON(endpoint, token, true, &alliance); err != nil {
 		return nil, fmt.Errorf("failed to decode alliance: %w", err)
@@ -31,62 +31,68 @@ func NewEveProfileservice(
 	}
 }
 
+// LoadCharacterSettings scans the profiles of every settings root. The settings directory must be
+// readable, other roots that are not are skipped.
 func (e *eveProfileService) LoadCharacterSettings() ([]model.EveProfile, error) {
-	settingsDir, err := e.configService.GetSettingsDir()
+	roots, err := e.configService.GetSettingsRoots()
 	if err != nil {
 		return nil, err
 	}
 
-	subDirs, err := e.eveRepo.GetSubDirectories(settingsDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get subdirectories: %w", err)
-	}
-
 	var settingsData []model.EveProfile
 	allCharIDs := make(map[string]struct{})
 
-	for _, sd := range subDirs {
-		rawFiles, err := e.eveRepo.ListSettingsFiles(sd, settingsDir)
+	for _, root := range roots {
+		subDirs, err := e.eveRepo.GetSubDirectories(root.Path)
 		if err != nil {
-			e.logger.Warnf("Error fetching settings files for subDir %s: %v", sd, err)
+			if root.Name == model.PrimarySettingsRoot {
+				return nil, fmt.Errorf("failed to get subdirectories: %w", err)
+			}
+			e.logger.Warnf("Skipping settings root %s: %v", root.Name, err)
 			continue
 		}
 
-		var charFiles []model.CharFile
-		var userFiles []model.UserFile
-
-		for _, rf := range rawFiles {
-			if rf.IsChar {
-				// Just record charId for later ESI resolution
-				allCharIDs[rf.CharOrUserID] = struct{}{}
-				charFiles = append(charFiles, model.CharFile{
-					File:   rf.FileName,
-					CharId: rf.CharOrUserID,
-					Name:   "CharID:" + rf.CharOrUserID, // Temporary name, will update after ESI lookup
-					Mtime:  rf.Mtime,
-				})
-			} else {
-				//
-				friendlyName := rf.CharOrUserID
-				if savedName, ok := e.accountService.GetAccountNameByID(rf.CharOrUserID); ok {
-					friendlyName = savedName
-				}
-				userFiles = append(userFiles, model.UserFile{
-					File:   rf.FileName,
-					UserId: rf.CharOrUserID,
-					Name:   friendlyName,
-					Mtime:  rf.Mtime,
-				})
+		for _, sd := range subDirs {
+			profile, err := e.loadProfile(root, sd, allCharIDs)
+			if err != nil {
+				e.logger.Warnf("Error fetching settings files for subDir %s in %s: %v", sd, root.Name, err)
+				continue
 			}
+			settingsData = append(settingsData, profile)
 		}
+	}
 
-		settingsData = append(settingsData, model.EveProfile{
-			Profile:            sd,
-			AvailableCharFiles: charFiles,
-			AvailableUserFiles: userFiles,
-		})
+	if err := e.resolveCharFileNames(settingsData, allCharIDs); err != nil {
+		return nil, err
+	}
+	return settingsData, nil
+}
+
+// LoadProfile loads a single profile, an empty root is the settings directory.
+func (e *eveProfileService) LoadProfile(rootName, subDir string) (*model.EveProfile, error) {
+	if rootName == "" {
+		rootName = model.PrimarySettingsRoot
+	}
+	path, err := e.settingsRootPath(rootName)
+	if err != nil {
+		return nil, err
 	}
 
+	charIDs := make(map[string]struct{})
+	profile, err := e.loadProfile(model.SettingsRoot{Name: rootName, Path: path}, subDir, charIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile %s: %w", subDir, err)
+	}
+
+	profiles := []model.EveProfile{profile}
+	if err := e.resolveCharFileNames(profiles, charIDs); err != nil {
+		return nil, err
+	}
+	return &profiles[0], nil
+}
+
+// resolveCharFileNames names char files through ESI and drops those of characters that do not resolve.
+func (e *eveProfileService) resolveCharFileNames(settingsData []model.EveProfile, allCharIDs map[string]struct{}) error {
 	// Resolve character names via ESI
 	var charIdList []string
 	for id := range allCharIDs {
@@ -94,7 +100,7 @@ func (e *eveProfileService) LoadCharacterSettings() ([]model.EveProfile, error)
 	}
 	charIdToName, err := e.esiService.ResolveCharacterNames(charIdList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve character names: %w", err)
+		return fmt.Errorf("failed to resolve character names: %w", err)
 	}
 
 	// Update character files with resolved names
@@ -108,12 +114,54 @@ func (e *eveProfileService) LoadCharacterSettings() ([]model.EveProfile, error)
 		}
 		settingsData[si].AvailableCharFiles = filteredChars
 	}
+	return nil
+}
 
-	return settingsData, nil
+// loadProfile lists the char and user files of a profile, recording char IDs in charIDs for name resolution.
+func (e *eveProfileService) loadProfile(root model.SettingsRoot, subDir string, charIDs map[string]struct{}) (model.EveProfile, error) {
+	rawFiles, err := e.eveRepo.ListSettingsFiles(subDir, root.Path)
+	if err != nil {
+		return model.EveProfile{}, err
+	}
+
+	var charFiles []model.CharFile
+	var userFiles []model.UserFile
+
+	for _, rf := range rawFiles {
+		if rf.IsChar {
+			// Just record charId for later ESI resolution
+			charIDs[rf.CharOrUserID] = struct{}{}
+			charFiles = append(charFiles, model.CharFile{
+				File:   rf.FileName,
+				CharId: rf.CharOrUserID,
+				Name:   "CharID:" + rf.CharOrUserID, // Temporary name, will update after ESI lookup
+				Mtime:  rf.Mtime,
+			})
+		} else {
+			//
+			friendlyName := rf.CharOrUserID
+			if savedName, ok := e.accountService.GetAccountNameByID(rf.CharOrUserID); ok {
+				friendlyName = savedName
+			}
+			userFiles = append(userFiles, model.UserFile{
+				File:   rf.FileName,
+				UserId: rf.CharOrUserID,
+				Name:   friendlyName,
+				Mtime:  rf.Mtime,
+			})
+		}
+	}
+
+	return model.EveProfile{
+		Root:               root.Name,
+		Profile:            subDir,
+		AvailableCharFiles: charFiles,
+		AvailableUserFiles: userFiles,
+	}, nil
 }
 
-func (e *eveProfileService) SyncDir(subDir, charId, userId string) (int, int, error) {
-	settingsDir, err := e.configService.GetSettingsDir()
+func (e *eveProfileService) SyncDir(root, subDir, charId, userId string) (int, int, error) {
+	settingsDir, err := e.settingsRootPath(root)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -121,18 +169,49 @@ func (e *eveProfileService) SyncDir(subDir, charId, userId string) (int, int, er
 	return e.eveRepo.SyncSubdirectory(subDir, userId, charId, settingsDir)
 }
 
-func (e *eveProfileService) SyncAllDir(baseSubDir, charId, userId string) (int, int, error) {
-	settingsDir, err := e.configService.GetSettingsDir()
+func (e *eveProfileService) SyncAllDir(root, baseSubDir, charId, userId string) (int, int, error) {
+	settingsDir, err := e.settingsRootPath(root)
 	if err != nil {
 		return 0, 0, err
 	}
-	if settingsDir == "" {
-		return 0, 0, fmt.Errorf("SettingsDir not set")
-	}
 
 	return e.eveRepo.SyncAllSubdirectories(baseSubDir, userId, charId, settingsDir)
 }
 
+func (e *eveProfileService) SyncToRoot(fromRoot, baseSubDir, toRoot, charId, userId string) (int, int, error) {
+	settingsDir, err := e.settingsRootPath(fromRoot)
+	if err != nil {
+		return 0, 0, err
+	}
+	targetDir, err := e.settingsRootPath(toRoot)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return e.eveRepo.SyncToSettingsDir(baseSubDir, userId, charId, settingsDir, targetDir)
+}
+
+// settingsRootPath returns the directory of a settings root, an empty name is the settings directory.
+func (e *eveProfileService) settingsRootPath(name string) (string, error) {
+	if name == "" {
+		name = model.PrimarySettingsRoot
+	}
+	roots, err := e.configService.GetSettingsRoots()
+	if err != nil {
+		return "", err
+	}
+	for _, root := range roots {
+		if root.Name != name {
+			continue
+		}
+		if root.Path == "" {
+			return "", fmt.Errorf("SettingsDir not set")
+		}
+		return root.Path, nil
+	}
+	return "", fmt.Errorf("settings root %s not found", name)
+}
+
 // BackupDir backs up EVE “settings_” directories and then
 // also calls configService to zip up any .json files in its basePath.
 func (e *eveProfileService) BackupDir(targetDir, backupDir string) error {
@@ -10,7 +10,11 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// TestLoadCharacterSettings_ConfigError tests if an error from GetSettingsDir is returned directly.
+func primaryRoot(path string) []model.SettingsRoot {
+	return []model.SettingsRoot{{Name: model.PrimarySettingsRoot, Path: path}}
+}
+
+// TestLoadCharacterSettings_ConfigError tests if an error from GetSettingsRoots is returned directly.
 func TestLoadCharacterSettings_ConfigError(t *testing.T) {
 	logger := &testutil.MockLogger{}
 	eveRepo := &testutil.MockEveProfilesRepository{}
@@ -19,7 +23,7 @@ func TestLoadCharacterSettings_ConfigError(t *testing.T) {
 	acctSvc := &testutil.MockAccountService{}
 
 	configErr := errors.New("config error")
-	configSvc.On("GetSettingsDir").Return("", configErr).Once()
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot(nil), configErr).Once()
 
 	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
 	_, err := svc.LoadCharacterSettings()
@@ -37,7 +41,7 @@ func TestLoadCharacterSettings_SubDirError(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("/settingsdir", nil).Once()
+	configSvc.On("GetSettingsRoots").Return(primaryRoot("/settingsdir"), nil).Once()
 	subDirErr := errors.New("subdir error")
 	// Return empty slice for directories to avoid panic
 	eveRepo.On("GetSubDirectories", "/settingsdir").Return([]string{}, subDirErr).Once()
@@ -59,7 +63,7 @@ func TestLoadCharacterSettings_Success(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("/settingsdir", nil).Once()
+	configSvc.On("GetSettingsRoots").Return(primaryRoot("/settingsdir"), nil).Once()
 	eveRepo.On("GetSubDirectories", "/settingsdir").Return([]string{"profile1"}, nil).Once()
 
 	rawFiles := []model.RawFileInfo{
@@ -78,6 +82,7 @@ func TestLoadCharacterSettings_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, data, 1)
 	assert.Equal(t, "profile1", data[0].Profile)
+	assert.Equal(t, model.PrimarySettingsRoot, data[0].Root)
 	assert.Len(t, data[0].AvailableCharFiles, 1)
 	assert.Equal(t, "Pilot", data[0].AvailableCharFiles[0].Name)
 	assert.Len(t, data[0].AvailableUserFiles, 1)
@@ -97,7 +102,7 @@ func TestLoadCharacterSettings_ResolveError(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("/settingsdir", nil).Once()
+	configSvc.On("GetSettingsRoots").Return(primaryRoot("/settingsdir"), nil).Once()
 	eveRepo.On("GetSubDirectories", "/settingsdir").Return([]string{"profile1"}, nil).Once()
 
 	rawFiles := []model.RawFileInfo{
@@ -128,10 +133,10 @@ func TestSyncDir_ConfigError(t *testing.T) {
 	acctSvc := &testutil.MockAccountService{}
 
 	configErr := errors.New("config err")
-	configSvc.On("GetSettingsDir").Return("", configErr).Once()
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot(nil), configErr).Once()
 
 	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
-	_, _, err := svc.SyncDir("subdir", "charId", "userId")
+	_, _, err := svc.SyncDir("", "subdir", "charId", "userId")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "config err")
 
@@ -146,11 +151,11 @@ func TestSyncDir_Success(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("/settingsdir", nil).Once()
+	configSvc.On("GetSettingsRoots").Return(primaryRoot("/settingsdir"), nil).Once()
 	eveRepo.On("SyncSubdirectory", "subdir", "userId", "charId", "/settingsdir").Return(2, 3, nil).Once()
 
 	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
-	uCopied, cCopied, err := svc.SyncDir("subdir", "charId", "userId")
+	uCopied, cCopied, err := svc.SyncDir("", "subdir", "charId", "userId")
 	assert.NoError(t, err)
 	assert.Equal(t, 2, uCopied)
 	assert.Equal(t, 3, cCopied)
@@ -159,7 +164,7 @@ func TestSyncDir_Success(t *testing.T) {
 	eveRepo.AssertExpectations(t)
 }
 
-// TestSyncAllDir_ConfigError tests GetSettingsDir error in SyncAllDir
+// TestSyncAllDir_ConfigError tests GetSettingsRoots error in SyncAllDir
 func TestSyncAllDir_ConfigError(t *testing.T) {
 	logger := &testutil.MockLogger{}
 	eveRepo := &testutil.MockEveProfilesRepository{}
@@ -167,10 +172,10 @@ func TestSyncAllDir_ConfigError(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("", errors.New("config err")).Once()
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot(nil), errors.New("config err")).Once()
 
 	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
-	_, _, err := svc.SyncAllDir("baseSubDir", "charId", "userId")
+	_, _, err := svc.SyncAllDir("", "baseSubDir", "charId", "userId")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "config err")
 
@@ -185,10 +190,10 @@ func TestSyncAllDir_EmptySettingsDir(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("", nil).Once()
+	configSvc.On("GetSettingsRoots").Return(primaryRoot(""), nil).Once()
 
 	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
-	_, _, err := svc.SyncAllDir("baseSubDir", "charId", "userId")
+	_, _, err := svc.SyncAllDir("", "baseSubDir", "charId", "userId")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "SettingsDir not set")
 
@@ -203,11 +208,11 @@ func TestSyncAllDir_Success(t *testing.T) {
 	esiSvc := &testutil.MockESIService{}
 	acctSvc := &testutil.MockAccountService{}
 
-	configSvc.On("GetSettingsDir").Return("/settingsdir", nil).Once()
+	configSvc.On("GetSettingsRoots").Return(primaryRoot("/settingsdir"), nil).Once()
 	eveRepo.On("SyncAllSubdirectories", "baseSubDir", "userId", "charId", "/settingsdir").Return(10, 20, nil).Once()
 
 	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
-	uCopied, cCopied, err := svc.SyncAllDir("baseSubDir", "charId", "userId")
+	uCopied, cCopied, err := svc.SyncAllDir("", "baseSubDir", "charId", "userId")
 	assert.NoError(t, err)
 	assert.Equal(t, 10, uCopied)
 	assert.Equal(t, 20, cCopied)
@@ -216,6 +221,67 @@ func TestSyncAllDir_Success(t *testing.T) {
 	eveRepo.AssertExpectations(t)
 }
 
+// TestLoadCharacterSettings_MultipleRoots tests profiles are reported per root and unreadable extra roots are skipped
+func TestLoadCharacterSettings_MultipleRoots(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	eveRepo := &testutil.MockEveProfilesRepository{}
+	configSvc := &testutil.MockConfigService{}
+	esiSvc := &testutil.MockESIService{}
+	acctSvc := &testutil.MockAccountService{}
+
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot{
+		{Name: model.PrimarySettingsRoot, Path: "/tq"},
+		{Name: "sisi", Path: "/sisi"},
+		{Name: "wine", Path: "/wine"},
+	}, nil).Once()
+	eveRepo.On("GetSubDirectories", "/tq").Return([]string{"settings_Default"}, nil).Once()
+	eveRepo.On("GetSubDirectories", "/sisi").Return([]string{"settings_Default"}, nil).Once()
+	eveRepo.On("GetSubDirectories", "/wine").Return([]string{}, errors.New("gone")).Once()
+	eveRepo.On("ListSettingsFiles", "settings_Default", "/tq").Return([]model.RawFileInfo{
+		{FileName: "core_char_123.dat", CharOrUserID: "123", IsChar: true},
+	}, nil).Once()
+	eveRepo.On("ListSettingsFiles", "settings_Default", "/sisi").Return([]model.RawFileInfo{
+		{FileName: "core_char_123.dat", CharOrUserID: "123", IsChar: true},
+	}, nil).Once()
+	esiSvc.On("ResolveCharacterNames", []string{"123"}).Return(map[string]string{"123": "Pilot"}, nil).Once()
+
+	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
+	data, err := svc.LoadCharacterSettings()
+	assert.NoError(t, err)
+	assert.Len(t, data, 2)
+	assert.Equal(t, model.PrimarySettingsRoot, data[0].Root)
+	assert.Equal(t, "sisi", data[1].Root)
+	assert.Equal(t, "Pilot", data[1].AvailableCharFiles[0].Name)
+
+	eveRepo.AssertExpectations(t)
+}
+
+// TestSyncToRoot tests both roots are resolved and an unknown root is an error
+func TestSyncToRoot(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	eveRepo := &testutil.MockEveProfilesRepository{}
+	configSvc := &testutil.MockConfigService{}
+	esiSvc := &testutil.MockESIService{}
+	acctSvc := &testutil.MockAccountService{}
+
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot{
+		{Name: model.PrimarySettingsRoot, Path: "/tq"},
+		{Name: "sisi", Path: "/sisi"},
+	}, nil)
+	eveRepo.On("SyncToSettingsDir", "settings_Default", "userId", "charId", "/tq", "/sisi").Return(1, 2, nil).Once()
+
+	svc := eve.NewEveProfileservice(logger, eveRepo, acctSvc, esiSvc, configSvc)
+	uCopied, cCopied, err := svc.SyncToRoot("", "settings_Default", "sisi", "charId", "userId")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, uCopied)
+	assert.Equal(t, 2, cCopied)
+
+	_, _, err = svc.SyncToRoot("", "settings_Default", "duality", "charId", "userId")
+	assert.Error(t, err)
+
+	eveRepo.AssertExpectations(t)
+}
+
 // TestBackupDir_BackupError tests error from BackupDirectory
 func TestBackupDir_BackupError(t *testing.T) {
 	logger := &testutil.MockLogger{}
@@ -0,0 +1,192 @@
+package eve
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+const (
+	// defaultSPPerHour is used when a character has nothing training, 30 SP/min is an
+	// unremapped character without implants
+	defaultSPPerHour = 1800
+
+	// characters can not be extracted below this many SP
+	extractionFloorSP = 5000000
+	extractorSP       = 500000
+)
+
+// spPerRankAtLevel is the SP needed to reach each level for a rank 1 skill
+var spPerRankAtLevel = [6]int64{0, 250, 1415, 8000, 45255, 256000}
+
+// injectorBracket is the SP a Large Skill Injector gives below a total SP ceiling
+type injectorBracket struct {
+	below int64
+	sp    int64
+}
+
+var largeInjectorBrackets = []injectorBracket{
+	{below: 5000000, sp: 500000},
+	{below: 50000000, sp: 400000},
+	{below: 80000000, sp: 300000},
+	{below: math.MaxInt64, sp: 150000},
+}
+
+// PlanInjectors works out how many Large Skill Injectors finish planName for a character right
+// away. Skill ranks come from the SP characters already have in a skill or its queue entries.
+func (s *skillService) PlanInjectors(accounts []model.Account, planName string, characterID int64) (*model.InjectorPlan, error) {
+	plan, ok := s.skillRepo.GetSkillPlans()[planName]
+	if !ok {
+		return nil, fmt.Errorf("plan %s not found", planName)
+	}
+	char, ok := findCharacter(accounts, characterID)
+	if !ok {
+		return nil, fmt.Errorf("character %d not found", characterID)
+	}
+
+	ranks := inferSkillRanks(accounts)
+	skillTypes := s.skillRepo.GetSkillTypes()
+	trained := make(map[int32]int64, len(char.Skills))
+	for _, skill := range char.Skills {
+		trained[skill.SkillID] = skill.SkillpointsInSkill
+	}
+
+	result := &model.InjectorPlan{
+		PlanName:        planName,
+		CharacterID:     char.CharacterID,
+		CharacterName:   char.CharacterName,
+		TotalSP:         char.TotalSP,
+		UnallocatedSP:   int64(char.UnallocatedSP),
+		SPPerHour:       trainingRate(char),
+		EstimatedSkills: make([]string, 0),
+	}
+
+	for skillName, skill := range plan.Skills {
+		skillType, exists := skillTypes[skillName]
+		if !exists {
+			return nil, fmt.Errorf("skill %s in plan %s does not exist in eve types", skillName, planName)
+		}
+		id, err := strconv.ParseInt(skillType.TypeID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type ID %s for skill %s: %w", skillType.TypeID, skillName, err)
+		}
+
+		rank, known := ranks[int32(id)]
+		if !known {
+			rank = 1
+			result.EstimatedSkills = append(result.EstimatedSkills, skillName)
+		}
+		if missing := skillPointsForLevel(rank, skill.Level) - trained[int32(id)]; missing > 0 {
+			result.RequiredSP += missing
+		}
+	}
+	sort.Strings(result.EstimatedSkills)
+
+	result.TrainingHours = float64(result.RequiredSP) / result.SPPerHour
+
+	// the bracket depends on every SP the character owns, unallocated included
+	owned := char.TotalSP + int64(char.UnallocatedSP)
+	for needed := result.RequiredSP - int64(char.UnallocatedSP); needed > 0; {
+		sp := largeInjectorSP(owned)
+		result.Injectors++
+		result.InjectedSP += sp
+		owned += sp
+		needed -= sp
+	}
+	return result, nil
+}
+
+// PlanExtraction works out how many Skill Extractors a character can use, 500,000 SP each
+// without dropping below 5,000,000 SP. Unallocated SP can not be extracted.
+func (s *skillService) PlanExtraction(accounts []model.Account, characterID int64) (*model.ExtractionPlan, error) {
+	char, ok := findCharacter(accounts, characterID)
+	if !ok {
+		return nil, fmt.Errorf("character %d not found", characterID)
+	}
+
+	result := &model.ExtractionPlan{
+		CharacterID:   char.CharacterID,
+		CharacterName: char.CharacterName,
+		TotalSP:       char.TotalSP,
+		RemainingSP:   char.TotalSP,
+	}
+	if char.TotalSP > extractionFloorSP {
+		result.Extractors = int((char.TotalSP - extractionFloorSP) / extractorSP)
+		result.ExtractableSP = int64(result.Extractors) * extractorSP
+		result.RemainingSP -= result.ExtractableSP
+	}
+	return result, nil
+}
+
+func findCharacter(accounts []model.Account, characterID int64) (model.Character, bool) {
+	charIdentity, ok := findCharacterIdentity(accounts, characterID)
+	return charIdentity.Character, ok
+}
+
+// inferSkillRanks derives skill ranks from the SP every character has in a skill at its trained
+// level and from the end SP of queued levels.
+func inferSkillRanks(accounts []model.Account) map[int32]int64 {
+	ranks := make(map[int32]int64)
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+			for _, skill := range char.Skills {
+				if rank := rankFromSP(int(skill.TrainedSkillLevel), skill.SkillpointsInSkill); rank > 0 {
+					ranks[skill.SkillID] = rank
+				}
+			}
+			for _, q := range char.SkillQueue {
+				if rank := rankFromSP(int(q.FinishedLevel), int64(q.LevelEndSP)); rank > 0 {
+					ranks[q.SkillID] = rank
+				}
+			}
+		}
+	}
+	return ranks
+}
+
+// rankFromSP returns the rank of a skill with sp at level, 0 when it can not be told.
+func rankFromSP(level int, sp int64) int64 {
+	if level < 1 || level > 5 || sp <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(sp) / float64(spPerRankAtLevel[level])))
+}
+
+func skillPointsForLevel(rank int64, level int) int64 {
+	if level < 0 {
+		level = 0
+	}
+	if level > 5 {
+		level = 5
+	}
+	return rank * spPerRankAtLevel[level]
+}
+
+func largeInjectorSP(ownedSP int64) int64 {
+	for _, bracket := range largeInjectorBrackets {
+		if ownedSP < bracket.below {
+			return bracket.sp
+		}
+	}
+	return largeInjectorBrackets[len(largeInjectorBrackets)-1].sp
+}
+
+// trainingRate measures SP per hour from the entry currently training, falling back to
+// defaultSPPerHour for an empty or paused queue.
+func trainingRate(char model.Character) float64 {
+	for _, q := range char.SkillQueue {
+		if q.StartDate == nil || q.FinishDate == nil {
+			continue
+		}
+		hours := q.FinishDate.Sub(*q.StartDate).Hours()
+		sp := q.LevelEndSP - q.TrainingStartSP
+		if hours > 0 && sp > 0 {
+			return float64(sp) / hours
+		}
+	}
+	return defaultSPPerHour
+}
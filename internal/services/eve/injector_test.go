@@ -0,0 +1,100 @@
+package eve_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func injectorAccounts() []model.Account {
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	finish := start.Add(10 * time.Hour)
+	return []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{
+		{Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Newbie"},
+			CharacterSkillsResponse: model.CharacterSkillsResponse{
+				TotalSP: 4800000,
+				Skills:  []model.SkillResponse{{SkillID: 3300, TrainedSkillLevel: 3, SkillpointsInSkill: 8000}},
+			},
+			// 27000 SP in 10 hours
+			SkillQueue: []model.SkillQueue{{SkillID: 3301, FinishedLevel: 4, StartDate: &start, FinishDate: &finish, TrainingStartSP: 18255, LevelEndSP: 45255}},
+		}},
+		{Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Farm"},
+			CharacterSkillsResponse: model.CharacterSkillsResponse{
+				TotalSP:       12300000,
+				UnallocatedSP: 100000,
+				Skills: []model.SkillResponse{
+					{SkillID: 24311, TrainedSkillLevel: 1, SkillpointsInSkill: 3500},  // rank 14
+					{SkillID: 22761, TrainedSkillLevel: 2, SkillpointsInSkill: 11320}, // rank 8
+				},
+			},
+		}},
+	}}}
+}
+
+func injectorSkillService() interfaces.SkillService {
+	repo := &testutil.MockSkillRepository{}
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"Capitals": {Name: "Capitals", Skills: map[string]model.Skill{
+			"Gunnery":     {Name: "Gunnery", Level: 5},
+			"Carrier":     {Name: "Carrier", Level: 1},
+			"Recon Ships": {Name: "Recon Ships", Level: 5},
+			"Drones":      {Name: "Drones", Level: 1},
+		}},
+	})
+	repo.On("GetSkillTypes").Return(map[string]model.SkillType{
+		"Gunnery":     {TypeID: "3300", TypeName: "Gunnery"},
+		"Carrier":     {TypeID: "24311", TypeName: "Carrier"},
+		"Recon Ships": {TypeID: "22761", TypeName: "Recon Ships"},
+		"Drones":      {TypeID: "3436", TypeName: "Drones"},
+	})
+	return eveSvc.NewSkillService(&testutil.MockLogger{}, repo, &testutil.MockSkillPlanHistoryRepository{})
+}
+
+func TestPlanInjectors_AppliesDiminishingReturns(t *testing.T) {
+	svc := injectorSkillService()
+
+	plan, err := svc.PlanInjectors(injectorAccounts(), "Capitals", 1)
+	require.NoError(t, err)
+
+	// Gunnery 248000 + Carrier 3500 + Recon Ships 8*256000 + Drones 250
+	assert.Equal(t, int64(2299750), plan.RequiredSP)
+	// one injector at 500k below 5M total SP, then five at 400k
+	assert.Equal(t, 6, plan.Injectors)
+	assert.Equal(t, int64(2500000), plan.InjectedSP)
+	assert.InDelta(t, 2700, plan.SPPerHour, 0.001)
+	assert.InDelta(t, 2299750.0/2700, plan.TrainingHours, 0.001)
+	assert.Equal(t, []string{"Drones"}, plan.EstimatedSkills)
+}
+
+func TestPlanInjectors_UnknownPlanOrCharacter(t *testing.T) {
+	svc := injectorSkillService()
+
+	_, err := svc.PlanInjectors(injectorAccounts(), "Missing", 1)
+	assert.Error(t, err)
+	_, err = svc.PlanInjectors(injectorAccounts(), "Capitals", 99)
+	assert.Error(t, err)
+}
+
+func TestPlanExtraction(t *testing.T) {
+	svc := injectorSkillService()
+
+	farm, err := svc.PlanExtraction(injectorAccounts(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 14, farm.Extractors)
+	assert.Equal(t, int64(7000000), farm.ExtractableSP)
+	assert.Equal(t, int64(5300000), farm.RemainingSP)
+
+	newbie, err := svc.PlanExtraction(injectorAccounts(), 1)
+	require.NoError(t, err)
+	assert.Zero(t, newbie.Extractors)
+	assert.Equal(t, int64(4800000), newbie.RemainingSP)
+}
@@ -0,0 +1,233 @@
+package eve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.PlanSubscriptionService = (*planSubscriptionService)(nil)
+
+const (
+	subscriptionRequestTimeout = 30 * time.Second
+	maxRemotePlanSize          = 1 << 20
+)
+
+type planSubscriptionService struct {
+	logger    interfaces.Logger
+	subRepo   interfaces.PlanSubscriptionRepository
+	skillRepo interfaces.SkillRepository
+	client    *http.Client
+}
+
+func NewPlanSubscriptionService(logger interfaces.Logger, subRepo interfaces.PlanSubscriptionRepository, skillRepo interfaces.SkillRepository) interfaces.PlanSubscriptionService {
+	return &planSubscriptionService{
+		logger:    logger,
+		subRepo:   subRepo,
+		skillRepo: skillRepo,
+		client:    &http.Client{Timeout: subscriptionRequestTimeout},
+	}
+}
+
+func (p *planSubscriptionService) GetSubscriptions() ([]model.PlanSubscription, error) {
+	return p.subRepo.GetSubscriptions()
+}
+
+// AddSubscription registers a remote plan index and pulls its plans. Nothing is saved if the first fetch fails.
+func (p *planSubscriptionService) AddSubscription(ctx context.Context, name, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid subscription url %q", rawURL)
+	}
+
+	subs, err := p.subRepo.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if sub.Name == name {
+			return fmt.Errorf("subscription %s already exists", name)
+		}
+	}
+
+	sub := model.PlanSubscription{Name: name, URL: rawURL, ETags: make(map[string]string)}
+	if err := p.syncSubscription(ctx, &sub); err != nil {
+		return fmt.Errorf("failed to fetch subscription %s: %w", name, err)
+	}
+	sub.LastChecked = time.Now().UTC()
+
+	if err := p.subRepo.SaveSubscription(sub); err != nil {
+		if rmErr := p.skillRepo.RemoveRemotePlans(name); rmErr != nil {
+			p.logger.Warnf("failed to remove plans of unsaved subscription %s: %v", name, rmErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *planSubscriptionService) RemoveSubscription(name string) error {
+	if err := p.subRepo.DeleteSubscription(name); err != nil {
+		return err
+	}
+	return p.skillRepo.RemoveRemotePlans(name)
+}
+
+func (p *planSubscriptionService) RefreshSubscription(ctx context.Context, name string) error {
+	subs, err := p.subRepo.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+	for i := range subs {
+		if subs[i].Name == name {
+			return p.refreshAndSave(ctx, &subs[i])
+		}
+	}
+	return fmt.Errorf("subscription %s does not exist", name)
+}
+
+// RefreshAll refreshes every subscription, continuing past failures so one unreachable host does not block the rest.
+// It stops once ctx is done.
+func (p *planSubscriptionService) RefreshAll(ctx context.Context) error {
+	subs, err := p.subRepo.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range subs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := p.refreshAndSave(ctx, &subs[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *planSubscriptionService) refreshAndSave(ctx context.Context, sub *model.PlanSubscription) error {
+	syncErr := p.syncSubscription(ctx, sub)
+	sub.LastChecked = time.Now().UTC()
+	sub.LastError = ""
+	if syncErr != nil {
+		p.logger.Warnf("failed to refresh subscription %s: %v", sub.Name, syncErr)
+		sub.LastError = syncErr.Error()
+	}
+
+	if err := p.subRepo.SaveSubscription(*sub); err != nil {
+		return err
+	}
+	if syncErr != nil {
+		return fmt.Errorf("failed to refresh subscription %s: %w", sub.Name, syncErr)
+	}
+	return nil
+}
+
+// syncSubscription fetches the index and every plan it lists, sending the stored ETags so unchanged
+// resources are not downloaded again. The plans are only rewritten when something changed.
+func (p *planSubscriptionService) syncSubscription(ctx context.Context, sub *model.PlanSubscription) error {
+	indexURL, err := url.Parse(sub.URL)
+	if err != nil {
+		return fmt.Errorf("invalid subscription url %q: %w", sub.URL, err)
+	}
+
+	etags := make(map[string]string)
+	body, etag, notModified, err := p.fetch(ctx, sub.URL, sub.ETags[sub.URL])
+	if err != nil {
+		return err
+	}
+	etags[sub.URL] = etag
+
+	planFiles := sub.Plans
+	changed := !notModified
+	if !notModified {
+		var index model.RemotePlanIndex
+		if err := json.Unmarshal(body, &index); err != nil {
+			return fmt.Errorf("failed to parse plan index: %w", err)
+		}
+		planFiles = index.Plans
+	}
+
+	files := make(map[string][]byte, len(planFiles))
+	for _, file := range planFiles {
+		planName := strings.TrimSuffix(path.Base(file), ".txt")
+		if _, dup := files[planName]; dup {
+			return fmt.Errorf("plan index lists %s more than once", planName)
+		}
+
+		ref, err := url.Parse(file)
+		if err != nil {
+			return fmt.Errorf("invalid plan path %q: %w", file, err)
+		}
+		planURL := indexURL.ResolveReference(ref).String()
+
+		data, etag, notModified, err := p.fetch(ctx, planURL, sub.ETags[planURL])
+		if err != nil {
+			return err
+		}
+		etags[planURL] = etag
+		if notModified {
+			// A nil file keeps the stored copy
+			files[planName] = nil
+			continue
+		}
+		files[planName] = data
+		changed = true
+	}
+
+	if changed {
+		if err := p.skillRepo.SaveRemotePlans(sub.Name, files); err != nil {
+			return err
+		}
+	}
+
+	sub.ETags = etags
+	sub.Plans = planFiles
+	return nil
+}
+
+// fetch performs a conditional GET. When the server answers 304 the previous ETag is returned unchanged.
+func (p *planSubscriptionService) fetch(ctx context.Context, resourceURL, etag string) ([]byte, string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create request for %s: %w", resourceURL, err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to fetch %s: %w", resourceURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, etag, true, nil
+	case http.StatusOK:
+	default:
+		return nil, "", false, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, resourceURL)
+	}
+
+	// one byte past the limit tells a plan that is too large from one that just fits
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemotePlanSize+1))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to read %s: %w", resourceURL, err)
+	}
+	if len(data) > maxRemotePlanSize {
+		return nil, "", false, fmt.Errorf("%s is larger than %d bytes", resourceURL, maxRemotePlanSize)
+	}
+	return data, resp.Header.Get("ETag"), false, nil
+}
@@ -0,0 +1,199 @@
+package eve_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/persist"
+	"github.com/guarzo/canifly/internal/persist/eve"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+// remotePlanHost serves an index and plan files, honouring If-None-Match and counting full downloads
+type remotePlanHost struct {
+	mu        sync.Mutex
+	files     map[string]string
+	etags     map[string]string
+	downloads map[string]int
+}
+
+func (h *remotePlanHost) set(path, body, etag string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.files[path] = body
+	h.etags[path] = etag
+}
+
+func (h *remotePlanHost) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	body, ok := h.files[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if etag := h.etags[r.URL.Path]; r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.downloads[r.URL.Path]++
+	w.Header().Set("ETag", h.etags[r.URL.Path])
+	_, _ = w.Write([]byte(body))
+}
+
+func newSubscriptionFixture(t *testing.T) (*remotePlanHost, *httptest.Server, *eve.SkillStore, *eve.PlanSubscriptionStore) {
+	t.Helper()
+	host := &remotePlanHost{files: map[string]string{}, etags: map[string]string{}, downloads: map[string]int{}}
+	host.set("/corp/index.json", `{"plans": ["logi.txt", "dps/ferox.txt"]}`, `"idx-1"`)
+	host.set("/corp/logi.txt", "Logistics Cruisers 4\nShield Emission Systems 4\n", `"logi-1"`)
+	host.set("/corp/dps/ferox.txt", "Medium Hybrid Turret 4\n", `"ferox-1"`)
+
+	server := httptest.NewServer(host)
+	t.Cleanup(server.Close)
+
+	logger := &testutil.MockLogger{}
+	basePath := t.TempDir()
+	skillStore := eve.NewSkillStore(logger, persist.OSFileSystem{}, basePath)
+	require.NoError(t, skillStore.LoadSkillPlans())
+	subStore := eve.NewPlanSubscriptionStore(logger, persist.OSFileSystem{}, basePath)
+	return host, server, skillStore, subStore
+}
+
+func TestPlanSubscription_AddStoresReadOnlyPlansWithOrigin(t *testing.T) {
+	_, server, skillStore, subStore := newSubscriptionFixture(t)
+	svc := eveSvc.NewPlanSubscriptionService(&testutil.MockLogger{}, subStore, skillStore)
+
+	require.NoError(t, svc.AddSubscription(context.Background(), "corp", server.URL+"/corp/index.json"))
+
+	plans := skillStore.GetSkillPlans()
+	logi, ok := plans["corp/logi"]
+	require.True(t, ok)
+	assert.Equal(t, "corp", logi.Origin)
+	assert.True(t, logi.ReadOnly)
+	assert.Equal(t, 4, logi.Skills["Logistics Cruisers"].Level)
+	assert.Contains(t, plans, "corp/ferox")
+
+	// Subscribed plans cannot be edited, renamed or deleted locally
+	assert.Error(t, skillStore.UpdateSkillPlan("corp/logi", map[string]model.Skill{"Drones": {Name: "Drones", Level: 1}}, ""))
+	assert.Error(t, skillStore.RenameSkillPlan("corp/logi", "mine"))
+	assert.Error(t, skillStore.DeleteSkillPlan("corp/logi"))
+
+	data, err := skillStore.GetSkillPlanFile("corp/logi")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Logistics Cruisers 4")
+
+	// Plans survive a reload alongside the local ones
+	require.NoError(t, skillStore.LoadSkillPlans())
+	assert.True(t, skillStore.GetSkillPlans()["corp/ferox"].ReadOnly)
+
+	require.NoError(t, svc.RemoveSubscription("corp"))
+	assert.NotContains(t, skillStore.GetSkillPlans(), "corp/logi")
+	subs, err := svc.GetSubscriptions()
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+}
+
+func TestPlanSubscription_RefreshAllStopsWhenCanceled(t *testing.T) {
+	host, server, skillStore, subStore := newSubscriptionFixture(t)
+	svc := eveSvc.NewPlanSubscriptionService(&testutil.MockLogger{}, subStore, skillStore)
+	require.NoError(t, svc.AddSubscription(context.Background(), "corp", server.URL+"/corp/index.json"))
+	host.set("/corp/logi.txt", "Logistics Cruisers 5\n", `"logi-2"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, svc.RefreshAll(ctx), context.Canceled)
+	assert.Equal(t, 1, host.downloads["/corp/logi.txt"])
+	assert.Equal(t, 4, skillStore.GetSkillPlans()["corp/logi"].Skills["Logistics Cruisers"].Level)
+}
+
+func TestPlanSubscription_RefreshUsesETags(t *testing.T) {
+	host, server, skillStore, subStore := newSubscriptionFixture(t)
+	svc := eveSvc.NewPlanSubscriptionService(&testutil.MockLogger{}, subStore, skillStore)
+	require.NoError(t, svc.AddSubscription(context.Background(), "corp", server.URL+"/corp/index.json"))
+
+	// Nothing changed upstream, so nothing is downloaded again
+	require.NoError(t, svc.RefreshAll(context.Background()))
+	assert.Equal(t, 1, host.downloads["/corp/index.json"])
+	assert.Equal(t, 1, host.downloads["/corp/logi.txt"])
+
+	// A plan edited without touching the index is still picked up
+	host.set("/corp/logi.txt", "Logistics Cruisers 5\n", `"logi-2"`)
+	require.NoError(t, svc.RefreshSubscription(context.Background(), "corp"))
+	assert.Equal(t, 1, host.downloads["/corp/index.json"])
+	assert.Equal(t, 2, host.downloads["/corp/logi.txt"])
+	assert.Equal(t, 1, host.downloads["/corp/dps/ferox.txt"])
+	assert.Equal(t, 5, skillStore.GetSkillPlans()["corp/logi"].Skills["Logistics Cruisers"].Level)
+	assert.Contains(t, skillStore.GetSkillPlans(), "corp/ferox")
+
+	// the replaced copy is kept in the history, unchanged plans get no new revision
+	revisions, err := skillStore.History().GetRevisions("corp/logi")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 4, revisions[0].Skills["Logistics Cruisers"].Level)
+	assert.Equal(t, "Refreshed from subscription corp", revisions[1].Note)
+	revisions, err = skillStore.History().GetRevisions("corp/ferox")
+	require.NoError(t, err)
+	assert.Len(t, revisions, 1)
+
+	// Plans dropped from the index are removed
+	host.set("/corp/index.json", `{"plans": ["logi.txt"]}`, `"idx-2"`)
+	require.NoError(t, svc.RefreshAll(context.Background()))
+	assert.NotContains(t, skillStore.GetSkillPlans(), "corp/ferox")
+
+	subs, err := svc.GetSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, []string{"logi.txt"}, subs[0].Plans)
+	assert.Empty(t, subs[0].LastError)
+}
+
+func TestPlanSubscription_FailedRefreshKeepsPlans(t *testing.T) {
+	host, server, skillStore, subStore := newSubscriptionFixture(t)
+	svc := eveSvc.NewPlanSubscriptionService(&testutil.MockLogger{}, subStore, skillStore)
+	require.NoError(t, svc.AddSubscription(context.Background(), "corp", server.URL+"/corp/index.json"))
+
+	host.set("/corp/index.json", `not json`, `"idx-broken"`)
+	assert.Error(t, svc.RefreshAll(context.Background()))
+	assert.Contains(t, skillStore.GetSkillPlans(), "corp/logi")
+
+	subs, err := svc.GetSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.NotEmpty(t, subs[0].LastError)
+}
+
+func TestPlanSubscription_AddRejectsUnreachableFeed(t *testing.T) {
+	_, server, skillStore, subStore := newSubscriptionFixture(t)
+	svc := eveSvc.NewPlanSubscriptionService(&testutil.MockLogger{}, subStore, skillStore)
+
+	assert.Error(t, svc.AddSubscription(context.Background(), "corp", server.URL+"/missing.json"))
+	assert.Error(t, svc.AddSubscription(context.Background(), "corp", "ftp://example.com/index.json"))
+
+	subs, err := svc.GetSubscriptions()
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+}
+
+func TestPlanSubscription_RejectsOversizedPlan(t *testing.T) {
+	host, server, skillStore, subStore := newSubscriptionFixture(t)
+	svc := eveSvc.NewPlanSubscriptionService(&testutil.MockLogger{}, subStore, skillStore)
+	require.NoError(t, svc.AddSubscription(context.Background(), "corp", server.URL+"/corp/index.json"))
+
+	// a truncated plan would still parse, so it must be refused rather than cut short
+	host.set("/corp/logi.txt", strings.Repeat("Logistics Cruisers 4\n", 60000), `"logi-huge"`)
+	assert.Error(t, svc.RefreshAll(context.Background()))
+
+	plan, ok := skillStore.GetSkillPlans()["corp/logi"]
+	require.True(t, ok)
+	assert.Len(t, plan.Skills, 2, "the previous plan is kept")
+}
@@ -0,0 +1,311 @@
+package eve
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.RemapService = (*remapService)(nil)
+
+const (
+	// a remap puts every attribute between 17 and 27 with 14 points spread on top of the minimum
+	minRemapAttribute = 17
+	maxRemapAttribute = 27
+	remapPoints       = 14
+)
+
+type remapService struct {
+	logger       interfaces.Logger
+	esi          interfaces.ESIService
+	skillService interfaces.SkillService
+
+	// skills caches the training attributes of every skill looked up so far
+	mut    sync.Mutex
+	skills map[int32]skillTraining
+}
+
+// skillTraining is what decides how fast a skill trains, attributes are dogma attribute IDs
+type skillTraining struct {
+	rank      int64
+	primary   int64
+	secondary int64
+}
+
+// trainingItem is SP still to train in a single skill
+type trainingItem struct {
+	skill skillTraining
+	sp    int64
+}
+
+func NewRemapService(logger interfaces.Logger, esi interfaces.ESIService, skillSvc interfaces.SkillService) interfaces.RemapService {
+	return &remapService{
+		logger:       logger,
+		esi:          esi,
+		skillService: skillSvc,
+		skills:       make(map[int32]skillTraining),
+	}
+}
+
+// OptimizeRemap tries every remap against the SP left to train. When no remap is available yet,
+// training up to the date one becomes available is counted at the current attributes and only
+// what is left after it is optimized.
+func (r *remapService) OptimizeRemap(accounts []model.Account, characterID int64, planName string, implantBonus int) (*model.RemapPlan, error) {
+	if implantBonus < 0 || implantBonus > 5 {
+		return nil, fmt.Errorf("implant bonus must be between 0 and 5")
+	}
+	server, charIdentity, ok := findCharacterWithServer(accounts, characterID)
+	if !ok {
+		return nil, fmt.Errorf("character %d not found", characterID)
+	}
+	char := charIdentity.Character
+
+	token := charIdentity.Token
+	esi, err := r.esi.ForServer(server)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := esi.GetCharacterAttributes(characterID, &token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var items []trainingItem
+	if planName == "" {
+		items, err = r.queueItems(char, now)
+	} else {
+		items, err = r.planItems(char, planName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current := model.AttributeSet{
+		Charisma:     attributes.Charisma,
+		Intelligence: attributes.Intelligence,
+		Memory:       attributes.Memory,
+		Perception:   attributes.Perception,
+		Willpower:    attributes.Willpower,
+	}
+	result := &model.RemapPlan{
+		CharacterID:    char.CharacterID,
+		CharacterName:  char.CharacterName,
+		PlanName:       planName,
+		ImplantBonus:   implantBonus,
+		Current:        current,
+		RemapAvailable: attributes.BonusRemaps > 0 || attributes.AccruedRemapCooldownDate == nil || !attributes.AccruedRemapCooldownDate.After(now),
+		BonusRemaps:    attributes.BonusRemaps,
+	}
+	for _, item := range items {
+		result.TrainingSP += item.sp
+	}
+
+	currentMinutes := trainingMinutes(items, current, implantBonus)
+	remaining, waitedMinutes := items, 0.0
+	if !result.RemapAvailable {
+		result.NextRemapDate = attributes.AccruedRemapCooldownDate
+		remaining, waitedMinutes = trainUntil(items, current, implantBonus, result.NextRemapDate.Sub(now).Minutes())
+	}
+
+	result.Optimal = optimalRemap(remaining, current, implantBonus)
+	optimalMinutes := waitedMinutes + trainingMinutes(remaining, result.Optimal, implantBonus)
+
+	result.CurrentHours = currentMinutes / 60
+	result.OptimalHours = optimalMinutes / 60
+	result.SavedHours = result.CurrentHours - result.OptimalHours
+	return result, nil
+}
+
+// queueItems returns the SP left in each queue entry, the entry in training only counts the
+// part still to go.
+func (r *remapService) queueItems(char model.Character, now time.Time) ([]trainingItem, error) {
+	items := make([]trainingItem, 0, len(char.SkillQueue))
+	for _, q := range char.SkillQueue {
+		if q.FinishDate != nil && !q.FinishDate.After(now) {
+			continue
+		}
+		sp := float64(q.LevelEndSP - q.TrainingStartSP)
+		if q.StartDate != nil && q.FinishDate != nil && q.StartDate.Before(now) {
+			sp *= q.FinishDate.Sub(now).Seconds() / q.FinishDate.Sub(*q.StartDate).Seconds()
+		}
+		if sp <= 0 {
+			continue
+		}
+
+		skill, err := r.skillTraining(q.SkillID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, trainingItem{skill: skill, sp: int64(sp)})
+	}
+	return items, nil
+}
+
+// planItems returns the SP the character is missing for each skill of the plan, in skill name order.
+func (r *remapService) planItems(char model.Character, planName string) ([]trainingItem, error) {
+	plan, ok := r.skillService.GetSkillPlans()[planName]
+	if !ok {
+		return nil, fmt.Errorf("plan %s not found", planName)
+	}
+	skillTypes := r.skillService.GetSkillTypes()
+	trained := make(map[int32]int64, len(char.Skills))
+	for _, skill := range char.Skills {
+		trained[skill.SkillID] = skill.SkillpointsInSkill
+	}
+
+	names := make([]string, 0, len(plan.Skills))
+	for name := range plan.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]trainingItem, 0, len(names))
+	for _, name := range names {
+		skillType, exists := skillTypes[name]
+		if !exists {
+			return nil, fmt.Errorf("skill %s in plan %s does not exist in eve types", name, planName)
+		}
+		id, err := strconv.ParseInt(skillType.TypeID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type ID %s for skill %s: %w", skillType.TypeID, name, err)
+		}
+
+		skill, err := r.skillTraining(int32(id))
+		if err != nil {
+			return nil, err
+		}
+		if sp := skillPointsForLevel(skill.rank, plan.Skills[name].Level) - trained[int32(id)]; sp > 0 {
+			items = append(items, trainingItem{skill: skill, sp: sp})
+		}
+	}
+	return items, nil
+}
+
+func (r *remapService) skillTraining(skillID int32) (skillTraining, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if skill, ok := r.skills[skillID]; ok {
+		return skill, nil
+	}
+
+	esiType, err := r.esi.GetType(int64(skillID))
+	if err != nil {
+		return skillTraining{}, err
+	}
+	var skill skillTraining
+	for _, attr := range esiType.DogmaAttributes {
+		switch attr.AttributeID {
+		case model.DogmaSkillTimeConstant:
+			skill.rank = int64(attr.Value)
+		case model.DogmaPrimaryAttribute:
+			skill.primary = int64(attr.Value)
+		case model.DogmaSecondaryAttribute:
+			skill.secondary = int64(attr.Value)
+		}
+	}
+	if skill.rank == 0 || skill.primary == 0 || skill.secondary == 0 {
+		return skillTraining{}, fmt.Errorf("type %d is missing skill attributes", skillID)
+	}
+
+	r.skills[skillID] = skill
+	return skill, nil
+}
+
+func findCharacterIdentity(accounts []model.Account, characterID int64) (model.CharacterIdentity, bool) {
+	_, charIdentity, ok := findCharacterWithServer(accounts, characterID)
+	return charIdentity, ok
+}
+
+// findCharacterWithServer also returns the server of the character's account.
+func findCharacterWithServer(accounts []model.Account, characterID int64) (string, model.CharacterIdentity, bool) {
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			if charIdentity.Character.CharacterID == characterID {
+				return account.Server, charIdentity, true
+			}
+		}
+	}
+	return "", model.CharacterIdentity{}, false
+}
+
+// optimalRemap tries every remap and keeps the fastest, the current attributes win ties.
+func optimalRemap(items []trainingItem, current model.AttributeSet, implantBonus int) model.AttributeSet {
+	best, bestMinutes := current, trainingMinutes(items, current, implantBonus)
+	extra := maxRemapAttribute - minRemapAttribute
+	for cha := 0; cha <= extra; cha++ {
+		for intel := 0; intel <= extra && cha+intel <= remapPoints; intel++ {
+			for mem := 0; mem <= extra && cha+intel+mem <= remapPoints; mem++ {
+				for per := 0; per <= extra && cha+intel+mem+per <= remapPoints; per++ {
+					wil := remapPoints - cha - intel - mem - per
+					if wil > extra {
+						continue
+					}
+					candidate := model.AttributeSet{
+						Charisma:     minRemapAttribute + cha,
+						Intelligence: minRemapAttribute + intel,
+						Memory:       minRemapAttribute + mem,
+						Perception:   minRemapAttribute + per,
+						Willpower:    minRemapAttribute + wil,
+					}
+					if minutes := trainingMinutes(items, candidate, implantBonus); minutes < bestMinutes {
+						best, bestMinutes = candidate, minutes
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// trainUntil trains items in order for budget minutes and returns what is left and the minutes used.
+func trainUntil(items []trainingItem, attributes model.AttributeSet, implantBonus int, budget float64) ([]trainingItem, float64) {
+	used := 0.0
+	for i, item := range items {
+		rate := spPerMinute(item.skill, attributes, implantBonus)
+		minutes := float64(item.sp) / rate
+		if used+minutes > budget {
+			trainedSP := int64((budget - used) * rate)
+			rest := append([]trainingItem{{skill: item.skill, sp: item.sp - trainedSP}}, items[i+1:]...)
+			return rest, budget
+		}
+		used += minutes
+	}
+	return nil, used
+}
+
+func trainingMinutes(items []trainingItem, attributes model.AttributeSet, implantBonus int) float64 {
+	minutes := 0.0
+	for _, item := range items {
+		minutes += float64(item.sp) / spPerMinute(item.skill, attributes, implantBonus)
+	}
+	return minutes
+}
+
+func spPerMinute(skill skillTraining, attributes model.AttributeSet, implantBonus int) float64 {
+	primary := float64(attributeValue(attributes, skill.primary) + implantBonus)
+	secondary := float64(attributeValue(attributes, skill.secondary) + implantBonus)
+	return primary + secondary/2
+}
+
+func attributeValue(attributes model.AttributeSet, dogmaID int64) int {
+	switch dogmaID {
+	case model.DogmaCharisma:
+		return attributes.Charisma
+	case model.DogmaIntelligence:
+		return attributes.Intelligence
+	case model.DogmaMemory:
+		return attributes.Memory
+	case model.DogmaPerception:
+		return attributes.Perception
+	case model.DogmaWillpower:
+		return attributes.Willpower
+	}
+	return 0
+}
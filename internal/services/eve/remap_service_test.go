@@ -0,0 +1,83 @@
+package eve_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func remapFixtures(attributes *model.CharacterAttributes) (*testutil.MockESIService, *testutil.MockSkillService) {
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacterAttributes", int64(1), mock.Anything).Return(attributes, nil)
+	// Gunnery, rank 1, perception and willpower
+	esi.On("GetType", int64(3300)).Return(&model.EsiType{TypeID: 3300, DogmaAttributes: []model.DogmaAttribute{
+		{AttributeID: model.DogmaSkillTimeConstant, Value: 1},
+		{AttributeID: model.DogmaPrimaryAttribute, Value: model.DogmaPerception},
+		{AttributeID: model.DogmaSecondaryAttribute, Value: model.DogmaWillpower},
+	}}, nil).Once()
+
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"Guns": {Name: "Guns", Skills: map[string]model.Skill{"Gunnery": {Name: "Gunnery", Level: 5}}},
+	})
+	skillSvc.On("GetSkillTypes").Return(map[string]model.SkillType{"Gunnery": {TypeID: "3300", TypeName: "Gunnery"}})
+	return esi, skillSvc
+}
+
+func remapAccounts() []model.Account {
+	return []model.Account{{Name: "Main", Characters: []model.CharacterIdentity{{Character: model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+	}}}}}
+}
+
+func balancedAttributes() model.CharacterAttributes {
+	return model.CharacterAttributes{Charisma: 17, Intelligence: 20, Memory: 20, Perception: 20, Willpower: 22}
+}
+
+func TestRemapService_FindsFastestRemap(t *testing.T) {
+	attributes := balancedAttributes()
+	esi, skillSvc := remapFixtures(&attributes)
+	svc := eveSvc.NewRemapService(&testutil.MockLogger{}, esi, skillSvc)
+
+	plan, err := svc.OptimizeRemap(remapAccounts(), 1, "Guns", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.AttributeSet{Charisma: 17, Intelligence: 17, Memory: 17, Perception: 27, Willpower: 21}, plan.Optimal)
+	assert.True(t, plan.RemapAvailable)
+	assert.Equal(t, int64(256000), plan.TrainingSP)
+	// 20 + 22/2 SP per minute now, 27 + 21/2 after the remap
+	assert.InDelta(t, 256000.0/31/60, plan.CurrentHours, 0.001)
+	assert.InDelta(t, 256000.0/37.5/60, plan.OptimalHours, 0.001)
+	assert.InDelta(t, plan.CurrentHours-plan.OptimalHours, plan.SavedHours, 0.001)
+
+	// skill attributes are cached
+	_, err = svc.OptimizeRemap(remapAccounts(), 1, "Guns", 5)
+	require.NoError(t, err)
+	esi.AssertExpectations(t)
+}
+
+func TestRemapService_WaitsForRemapCooldown(t *testing.T) {
+	attributes := balancedAttributes()
+	cooldown := time.Now().Add(100 * time.Hour)
+	attributes.AccruedRemapCooldownDate = &cooldown
+	esi, skillSvc := remapFixtures(&attributes)
+	svc := eveSvc.NewRemapService(&testutil.MockLogger{}, esi, skillSvc)
+
+	plan, err := svc.OptimizeRemap(remapAccounts(), 1, "Guns", 0)
+	require.NoError(t, err)
+
+	assert.False(t, plan.RemapAvailable)
+	require.NotNil(t, plan.NextRemapDate)
+	// 186000 SP train at 31 SP/min before the remap, the other 70000 at 37.5
+	assert.InDelta(t, 100+70000.0/37.5/60, plan.OptimalHours, 0.01)
+
+	_, err = svc.OptimizeRemap(remapAccounts(), 1, "Guns", 6)
+	assert.Error(t, err)
+}
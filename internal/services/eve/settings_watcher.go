@@ -0,0 +1,288 @@
+package eve
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.SettingsWatcher = (*settingsWatcher)(nil)
+
+const (
+	DefaultSettingsPollInterval = 2 * time.Second
+	// DefaultSettingsDebounce lets the client finish writing, it saves every char and user file on logout
+	DefaultSettingsDebounce = 3 * time.Second
+
+	subscriberBuffer = 16
+)
+
+// profileKey identifies a profile across settings roots
+type profileKey struct {
+	root    string
+	profile string
+}
+
+// settingsSnapshot is the mtime of every char and user file by profile
+type settingsSnapshot map[profileKey]map[string]time.Time
+
+// settingsWatcher polls the settings roots, no file system notifications reach into Wine prefixes
+// and network shares reliably.
+type settingsWatcher struct {
+	logger        interfaces.Logger
+	configService interfaces.ConfigService
+	eveProfileSvc interfaces.EveProfilesService
+	stateService  interfaces.AppStateService
+	interval      time.Duration
+	debounce      time.Duration
+
+	mu          sync.Mutex
+	subscribers map[chan model.SettingsChangeEvent]struct{}
+}
+
+func NewSettingsWatcher(
+	logger interfaces.Logger,
+	configService interfaces.ConfigService,
+	eveProfileSvc interfaces.EveProfilesService,
+	stateService interfaces.AppStateService,
+	interval, debounce time.Duration,
+) interfaces.SettingsWatcher {
+	return &settingsWatcher{
+		logger:        logger,
+		configService: configService,
+		eveProfileSvc: eveProfileSvc,
+		stateService:  stateService,
+		interval:      interval,
+		debounce:      debounce,
+		subscribers:   make(map[chan model.SettingsChangeEvent]struct{}),
+	}
+}
+
+func (w *settingsWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	previous := w.snapshot()
+	pending := make(map[profileKey]*model.SettingsChangeEvent)
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			current := w.snapshot()
+			if changes := diffSnapshots(previous, current); len(changes) > 0 {
+				mergeChanges(pending, changes, now)
+				lastChange = now
+			}
+			previous = current
+
+			if len(pending) > 0 && now.Sub(lastChange) >= w.debounce {
+				w.flush(pending)
+				pending = make(map[profileKey]*model.SettingsChangeEvent)
+			}
+		}
+	}
+}
+
+func (w *settingsWatcher) Subscribe() (<-chan model.SettingsChangeEvent, func()) {
+	ch := make(chan model.SettingsChangeEvent, subscriberBuffer)
+	w.mu.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			delete(w.subscribers, ch)
+			w.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// snapshot reads the char and user files of every profile, roots that cannot be read are left out.
+func (w *settingsWatcher) snapshot() settingsSnapshot {
+	snap := make(settingsSnapshot)
+	roots, err := w.configService.GetSettingsRoots()
+	if err != nil {
+		w.logger.Warnf("settings watcher failed to get settings roots: %v", err)
+		return snap
+	}
+
+	for _, root := range roots {
+		if root.Path == "" {
+			continue
+		}
+		entries, err := os.ReadDir(root.Path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "settings_") {
+				continue
+			}
+			files, err := os.ReadDir(filepath.Join(root.Path, entry.Name()))
+			if err != nil {
+				continue
+			}
+			mtimes := make(map[string]time.Time)
+			for _, file := range files {
+				name := file.Name()
+				if file.IsDir() || (!strings.HasPrefix(name, "core_char_") && !strings.HasPrefix(name, "core_user_")) {
+					continue
+				}
+				info, err := file.Info()
+				if err != nil {
+					continue
+				}
+				mtimes[name] = info.ModTime()
+			}
+			snap[profileKey{root: root.Name, profile: entry.Name()}] = mtimes
+		}
+	}
+	return snap
+}
+
+// diffSnapshots returns the profiles whose files changed, with removed set for profiles that are gone.
+func diffSnapshots(previous, current settingsSnapshot) map[profileKey]*model.SettingsChangeEvent {
+	changes := make(map[profileKey]*model.SettingsChangeEvent)
+	for key, files := range current {
+		before, existed := previous[key]
+		var changed []string
+		for name, mtime := range files {
+			if old, ok := before[name]; !ok || !old.Equal(mtime) {
+				changed = append(changed, name)
+			}
+		}
+		for name := range before {
+			if _, ok := files[name]; !ok {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) > 0 || !existed {
+			changes[key] = &model.SettingsChangeEvent{Root: key.root, Profile: key.profile, Files: changed}
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changes[key] = &model.SettingsChangeEvent{Root: key.root, Profile: key.profile, Removed: true}
+		}
+	}
+	return changes
+}
+
+func mergeChanges(pending, changes map[profileKey]*model.SettingsChangeEvent, now time.Time) {
+	for key, change := range changes {
+		event, ok := pending[key]
+		if !ok {
+			event = &model.SettingsChangeEvent{Root: key.root, Profile: key.profile}
+			pending[key] = event
+		}
+		for _, file := range change.Files {
+			if !slices.Contains(event.Files, file) {
+				event.Files = append(event.Files, file)
+			}
+		}
+		event.Removed = change.Removed
+		event.Time = now
+	}
+}
+
+// flush reloads the changed profiles into the app state and tells the subscribers.
+func (w *settingsWatcher) flush(pending map[profileKey]*model.SettingsChangeEvent) {
+	events := make([]model.SettingsChangeEvent, 0, len(pending))
+	for _, event := range pending {
+		sort.Strings(event.Files)
+		events = append(events, *event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Root != events[j].Root {
+			return events[i].Root < events[j].Root
+		}
+		return events[i].Profile < events[j].Profile
+	})
+
+	if w.stateService.GetAppState().LoggedIn {
+		// names are resolved over ESI, so load the profiles before the state is locked for the update
+		loaded := make(map[profileKey]*model.EveProfile)
+		for _, event := range events {
+			if event.Removed {
+				continue
+			}
+			profile, err := w.eveProfileSvc.LoadProfile(event.Root, event.Profile)
+			if err != nil {
+				w.logger.Warnf("failed to reload profile %s/%s: %v", event.Root, event.Profile, err)
+				continue
+			}
+			loaded[profileKey{root: event.Root, profile: event.Profile}] = profile
+		}
+
+		err := w.stateService.UpdateEveProfiles(func(profiles []model.EveProfile) []model.EveProfile {
+			for _, event := range events {
+				profiles = applyChange(profiles, event, loaded)
+			}
+			return profiles
+		})
+		if err != nil {
+			w.logger.Errorf("failed to save app state after settings change: %v", err)
+		}
+	}
+
+	for _, event := range events {
+		w.logger.Debugf("settings changed in %s/%s: %v", event.Root, event.Profile, event.Files)
+		w.publish(event)
+	}
+}
+
+// applyChange removes a removed profile or puts its reloaded version in place, a profile that could not
+// be reloaded is left as it was.
+func applyChange(profiles []model.EveProfile, event model.SettingsChangeEvent, loaded map[profileKey]*model.EveProfile) []model.EveProfile {
+	index := -1
+	for i, p := range profiles {
+		if p.Root == event.Root && p.Profile == event.Profile {
+			index = i
+			break
+		}
+	}
+
+	if event.Removed {
+		if index >= 0 {
+			profiles = slices.Delete(profiles, index, index+1)
+		}
+		return profiles
+	}
+
+	profile, ok := loaded[profileKey{root: event.Root, profile: event.Profile}]
+	if !ok {
+		return profiles
+	}
+	if index >= 0 {
+		profiles[index] = *profile
+	} else {
+		profiles = append(profiles, *profile)
+	}
+	return profiles
+}
+
+// publish never blocks, a subscriber that does not keep up misses events.
+func (w *settingsWatcher) publish(event model.SettingsChangeEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+			w.logger.Warnf("dropping settings change event for a slow subscriber")
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package eve_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+// memoryState keeps the app state the watcher saves
+type memoryState struct {
+	testutil.MockAppStateService
+	mu    sync.Mutex
+	state model.AppState
+}
+
+func (m *memoryState) GetAppState() model.AppState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+func (m *memoryState) UpdateEveProfiles(update func([]model.EveProfile) []model.EveProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state.LoggedIn {
+		m.state.EveData.EveProfiles = update(slices.Clone(m.state.EveData.EveProfiles))
+	}
+	return nil
+}
+
+func (m *memoryState) profiles() []model.EveProfile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.EveData.EveProfiles
+}
+
+func writeSettingsFile(t *testing.T, dir, name string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("data"), 0644))
+}
+
+func nextSettingsEvent(t *testing.T, events <-chan model.SettingsChangeEvent) model.SettingsChangeEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no settings change event")
+		return model.SettingsChangeEvent{}
+	}
+}
+
+func TestSettingsWatcher_UpdatesChangedProfiles(t *testing.T) {
+	root := t.TempDir()
+	writeSettingsFile(t, filepath.Join(root, "settings_Default"), "core_char_1.dat")
+	writeSettingsFile(t, filepath.Join(root, "settings_Old"), "core_char_1.dat")
+
+	configSvc := &testutil.MockConfigService{}
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot{{Name: model.PrimarySettingsRoot, Path: root}}, nil)
+	reloaded := &model.EveProfile{Root: model.PrimarySettingsRoot, Profile: "settings_Default",
+		AvailableUserFiles: []model.UserFile{{File: "core_user_5.dat", UserId: "5"}}}
+	profileSvc := &testutil.MockEveProfilesService{}
+	profileSvc.On("LoadProfile", model.PrimarySettingsRoot, "settings_Default").Return(reloaded, nil).Once()
+
+	state := &memoryState{state: model.AppState{LoggedIn: true, EveData: model.EveData{EveProfiles: []model.EveProfile{
+		{Root: model.PrimarySettingsRoot, Profile: "settings_Default"},
+		{Root: model.PrimarySettingsRoot, Profile: "settings_Old"},
+	}}}}
+
+	watcher := eveSvc.NewSettingsWatcher(&testutil.MockLogger{}, configSvc, profileSvc, state, 10*time.Millisecond, 50*time.Millisecond)
+	events, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Start(ctx)
+	// let the first poll take its baseline
+	time.Sleep(30 * time.Millisecond)
+
+	writeSettingsFile(t, filepath.Join(root, "settings_Default"), "core_user_5.dat")
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "settings_Old")))
+
+	first, second := nextSettingsEvent(t, events), nextSettingsEvent(t, events)
+	assert.Equal(t, "settings_Default", first.Profile)
+	assert.Equal(t, []string{"core_user_5.dat"}, first.Files)
+	assert.False(t, first.Removed)
+	assert.Equal(t, "settings_Old", second.Profile)
+	assert.True(t, second.Removed)
+
+	assert.Equal(t, []model.EveProfile{*reloaded}, state.profiles())
+	profileSvc.AssertExpectations(t)
+}
+
+func TestSettingsWatcher_DebouncesWrites(t *testing.T) {
+	root := t.TempDir()
+	profile := filepath.Join(root, "settings_Default")
+	writeSettingsFile(t, profile, "core_char_1.dat")
+
+	configSvc := &testutil.MockConfigService{}
+	configSvc.On("GetSettingsRoots").Return([]model.SettingsRoot{{Name: model.PrimarySettingsRoot, Path: root}}, nil)
+	// logged out, so nothing is reloaded
+	state := &memoryState{}
+
+	watcher := eveSvc.NewSettingsWatcher(&testutil.MockLogger{}, configSvc, &testutil.MockEveProfilesService{}, state, 10*time.Millisecond, 200*time.Millisecond)
+	events, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Start(ctx)
+	time.Sleep(30 * time.Millisecond)
+
+	// the client writes its files one after the other
+	writeSettingsFile(t, profile, "core_user_5.dat")
+	time.Sleep(50 * time.Millisecond)
+	writeSettingsFile(t, profile, "core_char_2.dat")
+
+	event := nextSettingsEvent(t, events)
+	assert.Equal(t, []string{"core_char_2.dat", "core_user_5.dat"}, event.Files)
+	select {
+	case extra := <-events:
+		t.Fatalf("unexpected second event %+v", extra)
+	case <-time.After(300 * time.Millisecond):
+	}
+	assert.Empty(t, state.profiles())
+}
@@ -2,8 +2,10 @@ package eve
 
 import (
 	"bufio"
+	"fmt"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,13 +14,14 @@ import (
 var _ interfaces.SkillService = (*skillService)(nil)
 
 type skillService struct {
-	logger    interfaces.Logger
-	skillRepo interfaces.SkillRepository
+	logger      interfaces.Logger
+	skillRepo   interfaces.SkillRepository
+	historyRepo interfaces.SkillPlanHistoryRepository
 }
 
 // NewSkillService  returns a new SettingsService with a config
-func NewSkillService(logger interfaces.Logger, skillRepo interfaces.SkillRepository) interfaces.SkillService {
-	return &skillService{logger: logger, skillRepo: skillRepo}
+func NewSkillService(logger interfaces.Logger, skillRepo interfaces.SkillRepository, historyRepo interfaces.SkillPlanHistoryRepository) interfaces.SkillService {
+	return &skillService{logger: logger, skillRepo: skillRepo, historyRepo: historyRepo}
 }
 
 var romanToInt = map[string]int{
@@ -30,7 +33,13 @@ func (s *skillService) GetSkillPlanFile(planName string) ([]byte, error) {
 }
 
 func (s *skillService) DeleteSkillPlan(name string) error {
-	return s.skillRepo.DeleteSkillPlan(name)
+	if err := s.skillRepo.DeleteSkillPlan(name); err != nil {
+		return err
+	}
+	if err := s.historyRepo.DeleteHistory(name); err != nil {
+		s.logger.Warnf("failed to delete history for plan %s: %v", name, err)
+	}
+	return nil
 }
 
 func (s *skillService) ParseAndSaveSkillPlan(contents, name string) error {
@@ -38,6 +47,113 @@ func (s *skillService) ParseAndSaveSkillPlan(contents, name string) error {
 	return s.skillRepo.SaveSkillPlan(name, skills)
 }
 
+func (s *skillService) ParseAndUpdateSkillPlan(contents, name, note string) error {
+	skills := s.parseSkillPlanContents(contents)
+	return s.skillRepo.UpdateSkillPlan(name, skills, note)
+}
+
+func (s *skillService) RenameSkillPlan(oldName, newName string) error {
+	if s.CheckIfDuplicatePlan(newName) {
+		return fmt.Errorf("%s is already used as a plan name", newName)
+	}
+	if err := s.skillRepo.RenameSkillPlan(oldName, newName); err != nil {
+		return err
+	}
+	if err := s.historyRepo.RenameHistory(oldName, newName); err != nil {
+		s.logger.Warnf("failed to move history from %s to %s: %v", oldName, newName, err)
+	}
+	return nil
+}
+
+func (s *skillService) GetEmbeddedPlanConflicts() []model.EmbeddedPlanConflict {
+	return s.skillRepo.GetEmbeddedPlanConflicts()
+}
+
+// ResolveEmbeddedPlanConflict applies the chosen side of an embedded plan upgrade, the store records it in
+// the plan history.
+func (s *skillService) ResolveEmbeddedPlanConflict(planName, resolution string) error {
+	return s.skillRepo.ResolveEmbeddedPlanConflict(planName, resolution)
+}
+
+func (s *skillService) GetSkillPlanRevisions(name string) ([]model.SkillPlanRevision, error) {
+	return s.historyRepo.GetRevisions(name)
+}
+
+// DiffSkillPlanRevisions reports the skills added, removed and changed between two revisions of a plan.
+func (s *skillService) DiffSkillPlanRevisions(name string, from, to int) (*model.SkillPlanDiff, error) {
+	revisions, err := s.historyRepo.GetRevisions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRev, ok := findRevision(revisions, from)
+	if !ok {
+		return nil, fmt.Errorf("revision %d of plan %s not found", from, name)
+	}
+	toRev, ok := findRevision(revisions, to)
+	if !ok {
+		return nil, fmt.Errorf("revision %d of plan %s not found", to, name)
+	}
+
+	diff := diffSkills(fromRev.Skills, toRev.Skills)
+	diff.PlanName = name
+	diff.From = from
+	diff.To = to
+	return diff, nil
+}
+
+// RollbackSkillPlan restores the skills of an earlier revision, recording the rollback as a new revision.
+func (s *skillService) RollbackSkillPlan(name string, revision int) error {
+	revisions, err := s.historyRepo.GetRevisions(name)
+	if err != nil {
+		return err
+	}
+
+	target, ok := findRevision(revisions, revision)
+	if !ok {
+		return fmt.Errorf("revision %d of plan %s not found", revision, name)
+	}
+
+	return s.skillRepo.UpdateSkillPlan(name, target.Skills, fmt.Sprintf("Rolled back to revision %d", revision))
+}
+
+func findRevision(revisions []model.SkillPlanRevision, revision int) (model.SkillPlanRevision, bool) {
+	for _, r := range revisions {
+		if r.Revision == revision {
+			return r, true
+		}
+	}
+	return model.SkillPlanRevision{}, false
+}
+
+func diffSkills(from, to map[string]model.Skill) *model.SkillPlanDiff {
+	diff := &model.SkillPlanDiff{
+		Added:   []model.Skill{},
+		Removed: []model.Skill{},
+		Changed: []model.SkillLevelChange{},
+	}
+
+	for name, skill := range to {
+		previous, existed := from[name]
+		switch {
+		case !existed:
+			diff.Added = append(diff.Added, skill)
+		case previous.Level != skill.Level:
+			diff.Changed = append(diff.Changed, model.SkillLevelChange{Name: name, FromLevel: previous.Level, ToLevel: skill.Level})
+		}
+	}
+	for name, skill := range from {
+		if _, exists := to[name]; !exists {
+			diff.Removed = append(diff.Removed, skill)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Name < diff.Added[j].Name })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Name < diff.Removed[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
+
 func (s *skillService) CheckIfDuplicatePlan(name string) bool {
 	plans := s.skillRepo.GetSkillPlans()
 	for _, plan := range plans {
@@ -119,6 +235,8 @@ func (s *skillService) initializeUpdatedPlans(skillPlans map[string]model.SkillP
 		updated[planName] = model.SkillPlanWithStatus{
 			Name:                plan.Name,
 			Skills:              plan.Skills,
+			Origin:              plan.Origin,
+			ReadOnly:            plan.ReadOnly,
 			QualifiedCharacters: []string{},
 			PendingCharacters:   []string{},
 			MissingSkills:       make(map[string]map[string]int32),
@@ -343,6 +461,108 @@ func getStatus(qualifies bool, pending bool) string {
 	return "Not Qualified"
 }
 
+func (s *skillService) GetDoctrines() map[string]model.Doctrine {
+	return s.skillRepo.GetDoctrines()
+}
+
+func (s *skillService) SaveDoctrine(doctrine model.Doctrine) error {
+	return s.skillRepo.SaveDoctrine(doctrine)
+}
+
+func (s *skillService) DeleteDoctrine(name string) error {
+	return s.skillRepo.DeleteDoctrine(name)
+}
+
+// GetDoctrinesWithStatus evaluates every doctrine plan for every character and reports the tier each one meets.
+func (s *skillService) GetDoctrinesWithStatus(
+	accounts []model.Account,
+	doctrines map[string]model.Doctrine,
+	skillTypes map[string]model.SkillType,
+) map[string]model.DoctrineWithStatus {
+	result := make(map[string]model.DoctrineWithStatus, len(doctrines))
+	for name, doctrine := range doctrines {
+		withStatus := model.DoctrineWithStatus{Name: doctrine.Name}
+		for _, plan := range doctrine.Plans {
+			withStatus.Plans = append(withStatus.Plans, model.DoctrinePlanWithStatus{
+				DoctrinePlan:          plan,
+				MinimumCharacters:     []string{},
+				RecommendedCharacters: []string{},
+				Characters:            []model.CharacterSkillPlanStatus{},
+			})
+		}
+		result[name] = withStatus
+	}
+
+	var typeIds []int32
+	for _, account := range accounts {
+		for _, chData := range account.Characters {
+			character := chData.Character
+			characterSkills := s.mapCharacterSkills(character, &typeIds)
+			skillQueueLevels := s.mapSkillQueueLevels(character)
+
+			for name, doctrine := range result {
+				for i := range doctrine.Plans {
+					s.updateDoctrinePlanStatus(&doctrine.Plans[i], character.CharacterName, skillTypes, characterSkills, skillQueueLevels)
+				}
+				result[name] = doctrine
+			}
+		}
+	}
+
+	return result
+}
+
+func (s *skillService) updateDoctrinePlanStatus(
+	plan *model.DoctrinePlanWithStatus,
+	characterName string,
+	skillTypes map[string]model.SkillType,
+	characterSkills map[int32]int32,
+	skillQueueLevels map[int32]struct {
+		level      int32
+		finishDate *time.Time
+	},
+) {
+	minimum := model.SkillPlan{Name: plan.Name, Skills: plan.Minimum}
+	recommended := model.SkillPlan{Name: plan.Name, Skills: mergeTierSkills(plan.Minimum, plan.Recommended)}
+
+	minResult := s.evaluatePlanForCharacter(minimum, skillTypes, characterSkills, skillQueueLevels)
+	recResult := s.evaluatePlanForCharacter(recommended, skillTypes, characterSkills, skillQueueLevels)
+
+	status := model.CharacterSkillPlanStatus{
+		CharacterName:     characterName,
+		Status:            getStatus(minResult.Qualifies, minResult.Pending),
+		MissingSkills:     minResult.MissingSkills,
+		PendingFinishDate: minResult.LatestFinishDate,
+	}
+
+	switch {
+	case recResult.Qualifies && !recResult.Pending:
+		status.Tier = model.TierRecommended
+		plan.RecommendedCharacters = append(plan.RecommendedCharacters, characterName)
+	case minResult.Qualifies && !minResult.Pending:
+		// Report what is still needed to reach the next tier
+		status.Tier = model.TierMinimum
+		status.MissingSkills = recResult.MissingSkills
+		plan.MinimumCharacters = append(plan.MinimumCharacters, characterName)
+	}
+
+	plan.Characters = append(plan.Characters, status)
+}
+
+// mergeTierSkills returns the minimum skills raised to the recommended levels.
+func mergeTierSkills(minimum, recommended map[string]model.Skill) map[string]model.Skill {
+	merged := make(map[string]model.Skill, len(minimum)+len(recommended))
+	for name, skill := range minimum {
+		merged[name] = skill
+	}
+	for name, skill := range recommended {
+		if current, exists := merged[name]; !exists || skill.Level > current.Level {
+			merged[name] = skill
+		}
+	}
+	return merged
+}
+
 func (s *skillService) GetSkillPlans() map[string]model.SkillPlan {
 	return s.skillRepo.GetSkillPlans()
 }
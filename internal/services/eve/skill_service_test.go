@@ -8,6 +8,7 @@ import (
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/persist"
 	"github.com/guarzo/canifly/internal/persist/eve"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
 	"github.com/guarzo/canifly/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -183,3 +184,119 @@ func TestSkillStore_GetSkillTypeByID(t *testing.T) {
 	_, found := store.GetSkillTypeByID("999999")
 	assert.False(t, found, "ID 999999 should not be found in skill types")
 }
+
+func TestSkillService_GetDoctrinesWithStatus(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	repo := &testutil.MockSkillRepository{}
+	history := &testutil.MockSkillPlanHistoryRepository{}
+	svc := eveSvc.NewSkillService(logger, repo, history)
+
+	skillTypes := map[string]model.SkillType{
+		"Drones":           {TypeID: "3436", TypeName: "Drones"},
+		"Drone Navigation": {TypeID: "12305", TypeName: "Drone Navigation"},
+	}
+
+	doctrines := map[string]model.Doctrine{
+		"Shield Kikis": {
+			Name: "Shield Kikis",
+			Plans: []model.DoctrinePlan{{
+				Name:        "Kiki DPS",
+				Role:        "DPS",
+				Minimum:     map[string]model.Skill{"Drones": {Name: "Drones", Level: 4}},
+				Recommended: map[string]model.Skill{"Drone Navigation": {Name: "Drone Navigation", Level: 4}},
+			}},
+		},
+	}
+
+	newCharacter := func(name string, skills ...model.SkillResponse) model.CharacterIdentity {
+		return model.CharacterIdentity{Character: model.Character{
+			UserInfoResponse:        model.UserInfoResponse{CharacterName: name},
+			CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: skills},
+		}}
+	}
+
+	accounts := []model.Account{{
+		Name: "Main",
+		Characters: []model.CharacterIdentity{
+			newCharacter("Recommended",
+				model.SkillResponse{SkillID: 3436, TrainedSkillLevel: 5},
+				model.SkillResponse{SkillID: 12305, TrainedSkillLevel: 4}),
+			newCharacter("Minimum", model.SkillResponse{SkillID: 3436, TrainedSkillLevel: 4}),
+			newCharacter("Neither", model.SkillResponse{SkillID: 3436, TrainedSkillLevel: 2}),
+		},
+	}}
+
+	result := svc.GetDoctrinesWithStatus(accounts, doctrines, skillTypes)
+	require.Contains(t, result, "Shield Kikis")
+	plan := result["Shield Kikis"].Plans[0]
+
+	assert.Equal(t, []string{"Recommended"}, plan.RecommendedCharacters)
+	assert.Equal(t, []string{"Minimum"}, plan.MinimumCharacters)
+	require.Len(t, plan.Characters, 3)
+
+	tiers := make(map[string]model.CharacterSkillPlanStatus)
+	for _, c := range plan.Characters {
+		tiers[c.CharacterName] = c
+	}
+	assert.Equal(t, model.TierRecommended, tiers["Recommended"].Tier)
+	assert.Equal(t, model.TierMinimum, tiers["Minimum"].Tier)
+	assert.Equal(t, int32(4), tiers["Minimum"].MissingSkills["Drone Navigation"])
+	assert.Empty(t, tiers["Neither"].Tier)
+	assert.Equal(t, int32(4), tiers["Neither"].MissingSkills["Drones"])
+}
+
+func TestSkillService_PlanHistoryDiffAndRollback(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, os.MkdirAll(filepath.Join(basePath, "plans"), 0755))
+	svc := eveSvc.NewSkillService(logger, store, store.History())
+
+	require.NoError(t, svc.ParseAndSaveSkillPlan("Gunnery 3\nDrones 2\n", "fleet"))
+	require.NoError(t, svc.ParseAndUpdateSkillPlan("Gunnery 5\nNavigation IV\n", "fleet", "doctrine update"))
+
+	revisions, err := svc.GetSkillPlanRevisions("fleet")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "doctrine update", revisions[1].Note)
+
+	diff, err := svc.DiffSkillPlanRevisions("fleet", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Skill{{Name: "Navigation", Level: 4}}, diff.Added)
+	assert.Equal(t, []model.Skill{{Name: "Drones", Level: 2}}, diff.Removed)
+	assert.Equal(t, []model.SkillLevelChange{{Name: "Gunnery", FromLevel: 3, ToLevel: 5}}, diff.Changed)
+
+	_, err = svc.DiffSkillPlanRevisions("fleet", 1, 9)
+	assert.Error(t, err)
+
+	require.NoError(t, svc.RollbackSkillPlan("fleet", 1))
+	assert.Equal(t, 3, store.GetSkillPlans()["fleet"].Skills["Gunnery"].Level)
+
+	revisions, err = svc.GetSkillPlanRevisions("fleet")
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, "Rolled back to revision 1", revisions[2].Note)
+}
+
+func TestSkillService_UpdateRecordsBaselineForPlanWithoutHistory(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, os.MkdirAll(filepath.Join(basePath, "plans"), 0755))
+	svc := eveSvc.NewSkillService(logger, store, store.History())
+
+	// Written before plans had a history
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "plans", "legacy.txt"), []byte("Drones 1\n"), 0644))
+	require.NoError(t, store.LoadSkillPlans())
+	require.NoError(t, svc.ParseAndUpdateSkillPlan("Drones 5", "legacy", ""))
+
+	revisions, err := svc.GetSkillPlanRevisions("legacy")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 1, revisions[0].Skills["Drones"].Level)
+	assert.Equal(t, 5, revisions[1].Skills["Drones"].Level)
+}
@@ -0,0 +1,135 @@
+package eve
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.TimelineService = (*timelineService)(nil)
+
+const timelineDateFormat = "2006-01-02"
+
+type timelineService struct {
+	logger       interfaces.Logger
+	skillService interfaces.SkillService
+}
+
+func NewTimelineService(logger interfaces.Logger, skillSvc interfaces.SkillService) interfaces.TimelineService {
+	return &timelineService{logger: logger, skillService: skillSvc}
+}
+
+// BuildTimeline lists the queue entries finishing within the range, the queues ending within it
+// and the SP gained per UTC day. SP is spread evenly over the time an entry trains, so a level
+// that trains across midnight counts towards both days. Paused queues have no dates and are skipped.
+func (t *timelineService) BuildTimeline(accounts []model.Account, from, to *time.Time) model.TrainingTimeline {
+	inRange := func(at time.Time) bool {
+		return (from == nil || !at.Before(*from)) && (to == nil || !at.After(*to))
+	}
+
+	timeline := model.TrainingTimeline{
+		From:      from,
+		To:        to,
+		Entries:   make([]model.TimelineEntry, 0),
+		QueueEnds: make([]model.QueueEnd, 0),
+		DailySP:   make([]model.DailySP, 0),
+	}
+	daily := make(map[string]float64)
+
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			char := charIdentity.Character
+
+			if end := queueFinish(char.SkillQueue); end != nil && inRange(*end) {
+				timeline.QueueEnds = append(timeline.QueueEnds, model.QueueEnd{
+					AccountName:   account.Name,
+					CharacterID:   char.CharacterID,
+					CharacterName: char.CharacterName,
+					Ends:          *end,
+				})
+			}
+
+			for _, q := range char.SkillQueue {
+				if q.FinishDate == nil {
+					continue
+				}
+				sp := int64(q.LevelEndSP - q.TrainingStartSP)
+				if q.StartDate != nil && sp > 0 {
+					spreadSkillPoints(daily, *q.StartDate, *q.FinishDate, sp, from, to)
+				}
+				if !inRange(*q.FinishDate) {
+					continue
+				}
+				timeline.Entries = append(timeline.Entries, model.TimelineEntry{
+					AccountName:   account.Name,
+					CharacterID:   char.CharacterID,
+					CharacterName: char.CharacterName,
+					SkillID:       q.SkillID,
+					SkillName:     t.skillService.GetSkillName(q.SkillID),
+					Level:         q.FinishedLevel,
+					StartDate:     q.StartDate,
+					FinishDate:    *q.FinishDate,
+					SkillPoints:   sp,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		a, b := timeline.Entries[i], timeline.Entries[j]
+		if !a.FinishDate.Equal(b.FinishDate) {
+			return a.FinishDate.Before(b.FinishDate)
+		}
+		return a.CharacterName < b.CharacterName
+	})
+	sort.SliceStable(timeline.QueueEnds, func(i, j int) bool {
+		return timeline.QueueEnds[i].Ends.Before(timeline.QueueEnds[j].Ends)
+	})
+
+	for date, sp := range daily {
+		timeline.DailySP = append(timeline.DailySP, model.DailySP{Date: date, SkillPoints: int64(math.Round(sp))})
+	}
+	sort.Slice(timeline.DailySP, func(i, j int) bool { return timeline.DailySP[i].Date < timeline.DailySP[j].Date })
+
+	return timeline
+}
+
+// spreadSkillPoints adds the SP of an entry training between start and finish to each UTC day
+// in proportion to the time trained that day, leaving out time outside the range.
+func spreadSkillPoints(daily map[string]float64, start, finish time.Time, sp int64, from, to *time.Time) {
+	total := finish.Sub(start)
+	if total <= 0 {
+		return
+	}
+	rate := float64(sp) / float64(total)
+
+	if from != nil && start.Before(*from) {
+		start = *from
+	}
+	if to != nil && finish.After(*to) {
+		finish = *to
+	}
+
+	for cursor := start.UTC(); cursor.Before(finish); {
+		dayEnd := time.Date(cursor.Year(), cursor.Month(), cursor.Day()+1, 0, 0, 0, 0, time.UTC)
+		if dayEnd.After(finish) {
+			dayEnd = finish
+		}
+		daily[cursor.Format(timelineDateFormat)] += rate * float64(dayEnd.Sub(cursor))
+		cursor = dayEnd
+	}
+}
+
+// queueFinish returns the latest finish date in the queue.
+func queueFinish(queue []model.SkillQueue) *time.Time {
+	var end *time.Time
+	for _, q := range queue {
+		if q.FinishDate != nil && (end == nil || q.FinishDate.After(*end)) {
+			end = q.FinishDate
+		}
+	}
+	return end
+}
@@ -0,0 +1,83 @@
+package eve_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func at(day, hour int) *time.Time {
+	t := time.Date(2026, 11, day, hour, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func timelineAccounts() []model.Account {
+	return []model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{{Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+			SkillQueue: []model.SkillQueue{
+				// 24 hours across midnight, 1000 SP per hour
+				{SkillID: 3300, FinishedLevel: 4, StartDate: at(1, 12), FinishDate: at(2, 12), TrainingStartSP: 16000, LevelEndSP: 40000},
+				{SkillID: 3301, FinishedLevel: 2, StartDate: at(2, 12), FinishDate: at(2, 18), TrainingStartSP: 1000, LevelEndSP: 7000},
+			},
+		}}}},
+		{Name: "Alt", Characters: []model.CharacterIdentity{
+			{Character: model.Character{
+				UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Trader"},
+				SkillQueue: []model.SkillQueue{
+					{SkillID: 3300, FinishedLevel: 1, StartDate: at(1, 0), FinishDate: at(1, 6), TrainingStartSP: 0, LevelEndSP: 600},
+				},
+			}},
+			{Character: model.Character{
+				UserInfoResponse: model.UserInfoResponse{CharacterID: 3, CharacterName: "Paused"},
+				SkillQueue:       []model.SkillQueue{{SkillID: 3300, FinishedLevel: 5}},
+			}},
+		}},
+	}
+}
+
+func timelineSkillService() *testutil.MockSkillService {
+	skillSvc := &testutil.MockSkillService{}
+	skillSvc.On("GetSkillName", int32(3300)).Return("Gunnery")
+	skillSvc.On("GetSkillName", int32(3301)).Return("Small Hybrid Turret")
+	return skillSvc
+}
+
+func TestTimelineService_MergesQueues(t *testing.T) {
+	svc := eveSvc.NewTimelineService(&testutil.MockLogger{}, timelineSkillService())
+
+	timeline := svc.BuildTimeline(timelineAccounts(), nil, nil)
+
+	require.Len(t, timeline.Entries, 3)
+	assert.Equal(t, "Trader", timeline.Entries[0].CharacterName)
+	assert.Equal(t, "Gunnery", timeline.Entries[1].SkillName)
+	assert.Equal(t, int64(24000), timeline.Entries[1].SkillPoints)
+	assert.Equal(t, "Small Hybrid Turret", timeline.Entries[2].SkillName)
+
+	require.Len(t, timeline.QueueEnds, 2)
+	assert.Equal(t, "Trader", timeline.QueueEnds[0].CharacterName)
+	assert.Equal(t, *at(2, 18), timeline.QueueEnds[1].Ends)
+
+	assert.Equal(t, []model.DailySP{
+		{Date: "2026-11-01", SkillPoints: 600 + 12000},
+		{Date: "2026-11-02", SkillPoints: 12000 + 6000},
+	}, timeline.DailySP)
+}
+
+func TestTimelineService_DateRange(t *testing.T) {
+	svc := eveSvc.NewTimelineService(&testutil.MockLogger{}, timelineSkillService())
+
+	timeline := svc.BuildTimeline(timelineAccounts(), at(2, 0), at(2, 15))
+
+	require.Len(t, timeline.Entries, 1)
+	assert.Equal(t, int32(3300), timeline.Entries[0].SkillID)
+	assert.Empty(t, timeline.QueueEnds)
+	// 12 hours of the first skill and 3 of the second fall within the range
+	assert.Equal(t, []model.DailySP{{Date: "2026-11-02", SkillPoints: 12000 + 3000}}, timeline.DailySP)
+}
@@ -13,7 +13,9 @@ import (
 )
 
 type AccountService interface {
-	FindOrCreateAccount(state string, char *model.UserInfoResponse, token *oauth2.Token) error
+	// FindOrCreateAccount adds a character to the named account on server, creating the account
+	// when it does not exist. An existing account must be on the same server.
+	FindOrCreateAccount(accountName, server string, char *model.UserInfoResponse, token *oauth2.Token) error
 	UpdateAccountName(accountID int64, accountName string) error
 	ToggleAccountStatus(accountID int64) error
 	ToggleAccountVisibility(accountID int64) error
@@ -23,7 +25,34 @@ type AccountService interface {
 	FetchAccounts() ([]model.Account, error)
 	SaveAccounts(accounts []model.Account) error
 	GetAccountNameByID(id string) (string, bool)
+	SetAccountExpiry(accountID int64, expiry *time.Time) error
+	SetCharacterMCTExpiry(characterID int64, expiry *time.Time) error
+	GetExpiryWarnings(accounts []model.Account) []model.ExpiryWarning
+	// MoveCharacter moves a character and its token to another account, removing the account it leaves empty.
+	MoveCharacter(characterID int64, toAccountName string) error
+	// MergeAccounts moves every character of one account into another and removes it.
+	MergeAccounts(fromAccountName, intoAccountName string) error
+	// GetTokenWarnings returns the characters that have to log in again.
+	GetTokenWarnings(accounts []model.Account) []model.TokenWarning
+	// ReauthorizeCharacter replaces the token of an existing character on server.
+	ReauthorizeCharacter(characterID int64, server string, token *oauth2.Token) error
 }
+type WalletRepository interface {
+	FetchWalletData() (model.WalletData, error)
+	SaveWalletData(data model.WalletData) error
+}
+
+type WalletService interface {
+	// RefreshWallets records the balances fetched by the last refresh that changed and returns the ISK
+	// held per account.
+	RefreshWallets(accounts []model.Account) model.WalletSummary
+	// SyncJournals fetches the wallet journals from ESI and merges them into the stored ones. A sync
+	// started while another one runs is skipped.
+	SyncJournals(accounts []model.Account)
+	GetBalanceHistory(characterID int64) ([]model.BalanceSnapshot, error)
+	GetJournal(characterID int64) ([]model.WalletJournalEntry, error)
+}
+
 type AccountDataRepository interface {
 	// FetchAccountData retrieves the entire account domain data (Accounts, UserAccount map, and Associations).
 	FetchAccountData() (model.AccountData, error)
@@ -45,10 +74,28 @@ type AccountDataRepository interface {
 	DeleteAccounts() error
 }
 
+// TokenRepository keeps the tokens SSO hands out when a character's access token is refreshed.
+type TokenRepository interface {
+	// SaveRefreshedToken replaces the token of the character that was logged in with refreshToken.
+	SaveRefreshedToken(refreshToken string, token oauth2.Token) error
+}
+
 type AssociationService interface {
 	UpdateAssociationsAfterNewCharacter(account *model.Account, charID int64) error
 	AssociateCharacter(userId, charId string) error
 	UnassociateCharacter(userId, charId string) error
+	// SuggestAssociations infers user files for unassociated characters from when their files were written.
+	SuggestAssociations(profiles []model.EveProfile) ([]model.AssociationSuggestion, error)
+	// AcceptSuggestions associates the given characters in bulk, returning the accepted and rejected ones.
+	AcceptSuggestions(suggestions []model.Association) ([]model.Association, []model.AssociationRejection, error)
+	GetAssociationRules() (model.AssociationRules, error)
+	UpdateAssociationRules(rules model.AssociationRules) error
+	// CheckAssociations reports inconsistent associations and accounts, orphans only when profiles are given.
+	CheckAssociations(profiles []model.EveProfile) ([]model.AssociationIssue, error)
+	// RepairAssociations fixes the repairable issues and returns them, orphans are not repairable.
+	RepairAssociations() ([]model.AssociationIssue, error)
+	// RemoveOrphanedAssociations removes the given associations whose user file is in none of the profiles.
+	RemoveOrphanedAssociations(profiles []model.EveProfile, orphans []model.Association) ([]model.Association, error)
 }
 
 type CacheService interface {
@@ -66,7 +113,8 @@ type CacheRepository interface {
 }
 
 type CharacterService interface {
-	ProcessIdentity(charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error)
+	// ProcessIdentity refreshes a character from ESI on server, the Server of its account.
+	ProcessIdentity(charIdentity *model.CharacterIdentity, server string) (*model.CharacterIdentity, error)
 	DoesCharacterExist(characterID int64) (bool, *model.CharacterIdentity, error)
 	UpdateCharacterFields(characterID int64, updates map[string]interface{}) error
 	RemoveCharacter(characterID int64) error
@@ -82,6 +130,7 @@ type AuthClient interface {
 type EsiHttpClient interface {
 	GetJSON(endpoint string, token *oauth2.Token, useCache bool, target interface{}) error
 	GetJSONFromURL(url string, token *oauth2.Token, useCache bool, target interface{}) error
+	PostJSON(endpoint string, token *oauth2.Token, body interface{}, target interface{}) error
 }
 
 type DeletedCharactersRepository interface {
@@ -91,7 +140,13 @@ type DeletedCharactersRepository interface {
 
 type LoginService interface {
 	ResolveAccountAndStatusByState(state string) (string, bool, bool)
-	GenerateAndStoreInitialState(value string) (string, error)
+	// ResolveServerByState returns the server a login was started for.
+	ResolveServerByState(state string) (string, bool)
+	GenerateAndStoreInitialState(accountName, server string) (string, error)
+	// GenerateReauthState starts a login that replaces the token of an existing character.
+	GenerateReauthState(characterID int64, accountName, server string) (string, error)
+	// ResolveReauthCharacterByState returns the character a login replaces the token of, 0 for other logins.
+	ResolveReauthCharacterByState(state string) int64
 	UpdateStateStatusAfterCallBack(state string) error
 	ClearState(state string)
 }
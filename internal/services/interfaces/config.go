@@ -41,15 +41,42 @@ type AppStateRepository interface {
 
 	// SaveAppStateSnapshot writes the current AppState to disk.
 	SaveAppStateSnapshot(appState model.AppState) error
+
+	// UpdateEveProfiles replaces the profiles with what update returns from a copy of them and persists
+	// the change in one step, so concurrent updates of other fields are kept. It does nothing while
+	// logged out.
+	UpdateEveProfiles(update func(profiles []model.EveProfile) []model.EveProfile) error
 }
 
 type AppStateService interface {
 	GetAppState() model.AppState
 	SetAppStateLogin(isLoggedIn bool) error
 	UpdateAndSaveAppState(data model.AppState) error
+	UpdateEveProfiles(update func(profiles []model.EveProfile) []model.EveProfile) error
 	ClearAppState()
 }
 
+type NotificationRepository interface {
+	FetchNotificationState() (model.NotificationState, error)
+	SaveNotificationState(state model.NotificationState) error
+}
+
+type NotificationService interface {
+	// EvaluateAlerts applies the alert rules to freshly refreshed data and returns the alerts raised by this evaluation.
+	EvaluateAlerts(accounts []model.Account, skillPlans map[string]model.SkillPlanWithStatus, doctrines map[string]model.DoctrineWithStatus) ([]model.Alert, error)
+	GetAlerts() ([]model.Alert, error)
+	AcknowledgeAlert(id string) error
+	GetSettings() (model.NotificationSettings, error)
+	UpdateSettings(settings model.NotificationSettings) error
+}
+
+// Notifier delivers alerts to an outbound channel
+type Notifier interface {
+	// Notify posts the alerts raised for a single rule, split over several messages when they do not fit
+	// in one, mention is prefixed to each when set.
+	Notify(url, rule, mention string, alerts []model.Alert) error
+}
+
 type ConfigRepository interface {
 	// FetchConfigData loads the entire ConfigData structure.
 	FetchConfigData() (*model.ConfigData, error)
@@ -70,12 +97,21 @@ type ConfigRepository interface {
 	SaveRoles(roles []string) error
 
 	GetDefaultSettingsDir() (string, error)
+	// DiscoverSettingsDirs finds every settings directory of the user, most recently used first.
+	DiscoverSettingsDirs() ([]model.SettingsDirCandidate, error)
 	BackupJSONFiles(backupDir string) error
 }
 
 type ConfigService interface {
 	UpdateSettingsDir(dir string) error
 	GetSettingsDir() (string, error)
+	// GetSettingsRoots returns every settings directory, SettingsDir first.
+	GetSettingsRoots() ([]model.SettingsRoot, error)
+	AddSettingsRoot(root model.SettingsRoot) error
+	RemoveSettingsRoot(name string) error
+	// DiscoverSettingsDirs lists settings directories for the user to pick from, including
+	// Steam Proton and Wine prefixes on Linux.
+	DiscoverSettingsDirs() ([]model.SettingsDirCandidate, error)
 	EnsureSettingsDir() error
 	SaveUserSelections(model.DropDownSelections) error
 	FetchUserSelections() (model.DropDownSelections, error)
@@ -85,4 +121,7 @@ type ConfigService interface {
 	BackupJSONFiles(backupDir string) error
 	FetchConfigData() (*model.ConfigData, error)
 	SaveRoles(roles []string) error
+	// GetCalendarToken returns the calendar feed token, creating one on first use.
+	GetCalendarToken() (string, error)
+	ResetCalendarToken() (string, error)
 }
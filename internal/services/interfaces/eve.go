@@ -2,6 +2,9 @@
 package interfaces
 
 import (
+	"context"
+	"time"
+
 	"github.com/guarzo/canifly/internal/model"
 	"golang.org/x/oauth2"
 )
@@ -12,10 +15,57 @@ type SkillService interface {
 	GetSkillTypes() map[string]model.SkillType
 	CheckIfDuplicatePlan(name string) bool
 	ParseAndSaveSkillPlan(contents, name string) error
+	ParseAndUpdateSkillPlan(contents, name, note string) error
+	RenameSkillPlan(oldName, newName string) error
+	GetSkillPlanRevisions(name string) ([]model.SkillPlanRevision, error)
+	DiffSkillPlanRevisions(name string, from, to int) (*model.SkillPlanDiff, error)
+	RollbackSkillPlan(name string, revision int) error
 	GetSkillPlanFile(name string) ([]byte, error)
 	DeleteSkillPlan(name string) error
 	GetSkillTypeByID(id string) (model.SkillType, bool)
 	GetPlanAndConversionData(accounts []model.Account, skillPlans map[string]model.SkillPlan, skillTypes map[string]model.SkillType) (map[string]model.SkillPlanWithStatus, map[string]string)
+	GetDoctrines() map[string]model.Doctrine
+	SaveDoctrine(doctrine model.Doctrine) error
+	DeleteDoctrine(name string) error
+	GetDoctrinesWithStatus(accounts []model.Account, doctrines map[string]model.Doctrine, skillTypes map[string]model.SkillType) map[string]model.DoctrineWithStatus
+	GetEmbeddedPlanConflicts() []model.EmbeddedPlanConflict
+	ResolveEmbeddedPlanConflict(planName, resolution string) error
+	// PlanInjectors compares injecting the SP a character is missing for a plan to training it.
+	PlanInjectors(accounts []model.Account, planName string, characterID int64) (*model.InjectorPlan, error)
+	// PlanExtraction reports how much SP a character can extract.
+	PlanExtraction(accounts []model.Account, characterID int64) (*model.ExtractionPlan, error)
+}
+
+type CalendarService interface {
+	// BuildCalendar renders skill completions and plan ready dates as an iCalendar feed, an empty
+	// characterIDs includes every character.
+	BuildCalendar(accounts []model.Account, characterIDs []int64) string
+}
+
+type TimelineService interface {
+	// BuildTimeline merges the skill queues of every character, from and to are optional bounds.
+	BuildTimeline(accounts []model.Account, from, to *time.Time) model.TrainingTimeline
+}
+
+type RemapService interface {
+	// OptimizeRemap finds the remap that trains planName, or the skill queue when planName is
+	// empty, the fastest. implantBonus is added to every attribute.
+	OptimizeRemap(accounts []model.Account, characterID int64, planName string, implantBonus int) (*model.RemapPlan, error)
+}
+
+type AssetRepository interface {
+	FetchAssetIndex() (model.AssetIndex, error)
+	SaveAssetIndex(index model.AssetIndex) error
+}
+
+type AssetService interface {
+	// RefreshAssets fetches and indexes the assets of every character.
+	RefreshAssets() error
+	// SearchAssets matches every word of query against item type and given names, and location
+	// against location and system names. Either may be empty but not both.
+	SearchAssets(query, location string) ([]model.AssetItem, error)
+	// GetAssetStatus lists when each character's assets were indexed, without the items.
+	GetAssetStatus() ([]model.CharacterAssets, error)
 }
 
 type SkillRepository interface {
@@ -23,16 +73,59 @@ type SkillRepository interface {
 	GetSkillPlanFile(name string) ([]byte, error)
 	GetSkillTypes() map[string]model.SkillType
 	SaveSkillPlan(planName string, skills map[string]model.Skill) error
+	UpdateSkillPlan(planName string, skills map[string]model.Skill, note string) error
+	RenameSkillPlan(oldName, newName string) error
 	DeleteSkillPlan(planName string) error
 	GetSkillTypeByID(id string) (model.SkillType, bool)
+	GetDoctrines() map[string]model.Doctrine
+	SaveDoctrine(doctrine model.Doctrine) error
+	DeleteDoctrine(name string) error
+	GetEmbeddedPlanConflicts() []model.EmbeddedPlanConflict
+	ResolveEmbeddedPlanConflict(planName, resolution string) error
+	SaveRemotePlans(source string, files map[string][]byte) error
+	RemoveRemotePlans(source string) error
+}
+
+type SkillPlanHistoryRepository interface {
+	AddRevision(planName string, skills map[string]model.Skill, note string) (model.SkillPlanRevision, error)
+	GetRevisions(planName string) ([]model.SkillPlanRevision, error)
+	RenameHistory(oldName, newName string) error
+	DeleteHistory(planName string) error
+}
+
+type PlanSubscriptionService interface {
+	GetSubscriptions() ([]model.PlanSubscription, error)
+	AddSubscription(ctx context.Context, name, url string) error
+	RemoveSubscription(name string) error
+	RefreshSubscription(ctx context.Context, name string) error
+	RefreshAll(ctx context.Context) error
+}
+
+type PlanSubscriptionRepository interface {
+	GetSubscriptions() ([]model.PlanSubscription, error)
+	SaveSubscription(sub model.PlanSubscription) error
+	DeleteSubscription(name string) error
 }
 
 type EveProfilesService interface {
 	LoadCharacterSettings() ([]model.EveProfile, error)
+	// LoadProfile loads one profile of a settings root, an empty root is the settings directory.
+	LoadProfile(root, subDir string) (*model.EveProfile, error)
 	BackupDir(targetDir, backupDir string) error
 
-	SyncDir(subDir, charId, userId string) (int, int, error)
-	SyncAllDir(baseSubDir, charId, userId string) (int, int, error)
+	// SyncDir and SyncAllDir work inside one settings root, an empty root is the settings directory.
+	SyncDir(root, subDir, charId, userId string) (int, int, error)
+	SyncAllDir(root, baseSubDir, charId, userId string) (int, int, error)
+	// SyncToRoot copies the user and char files of a profile in fromRoot to every profile of toRoot.
+	SyncToRoot(fromRoot, baseSubDir, toRoot, charId, userId string) (int, int, error)
+}
+
+type SettingsWatcher interface {
+	// Start polls the settings roots for changed char and user files until ctx is done, updating
+	// the profiles in the app state as they change.
+	Start(ctx context.Context)
+	// Subscribe returns change events and a function that ends the subscription.
+	Subscribe() (<-chan model.SettingsChangeEvent, func())
 }
 
 type EveProfilesRepository interface {
@@ -50,6 +143,10 @@ type EveProfilesRepository interface {
 
 	// SyncAllSubdirectories applies SyncSubdirectory logic to all subdirectories of settingsDir, using baseSubDir as the source.
 	SyncAllSubdirectories(baseSubDir, userId, charId, settingsDir string) (int, int, error)
+
+	// SyncToSettingsDir applies the user and char files of baseSubDir in settingsDir to every subdirectory of targetSettingsDir,
+	// files with the same names included.
+	SyncToSettingsDir(baseSubDir, userId, charId, settingsDir, targetSettingsDir string) (int, int, error)
 }
 
 type SystemRepository interface {
@@ -67,4 +164,16 @@ type ESIService interface {
 	SaveEsiCache() error
 	GetCorporation(id int64, token *oauth2.Token) (*model.Corporation, error)
 	GetAlliance(id int64, token *oauth2.Token) (*model.Alliance, error)
+	GetCharacterWallet(characterID int64, token *oauth2.Token) (float64, error)
+	GetCharacterWalletJournal(characterID int64, token *oauth2.Token) ([]model.WalletJournalEntry, error)
+	GetCharacterAssets(characterID int64, token *oauth2.Token) ([]model.EsiAsset, error)
+	GetCharacterAssetNames(characterID int64, token *oauth2.Token, itemIDs []int64) (map[int64]string, error)
+	GetStation(id int64) (*model.Station, error)
+	GetStructure(id int64, token *oauth2.Token) (*model.Structure, error)
+	ResolveUniverseNames(ids []int64) (map[int64]string, error)
+	GetCharacterAttributes(characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error)
+	GetType(typeID int64) (*model.EsiType, error)
+	// ForServer returns the service for an account's server, see model.NormalizeServer. It fails with
+	// errors.ErrServerNotConfigured for a server without an SSO client.
+	ForServer(server string) (ESIService, error)
 }
@@ -0,0 +1,513 @@
+// Package fakeesi serves a small in-memory copy of ESI and EVE SSO for tests. Point the ESI
+// client and the auth client at Server.URL and the refresh pipeline runs without the network.
+package fakeesi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/oauth2"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// Fixture IDs served by New
+const (
+	PilotID       = int64(90000001)
+	AltID         = int64(90000002)
+	CorporationID = int64(98000001)
+	NPCCorpID     = int64(1000169)
+	AllianceID    = int64(99000001)
+	JitaSystemID  = int64(30000142)
+)
+
+// AccessTokenLifetime matches EVE SSO
+const AccessTokenLifetime = 20 * time.Minute
+
+// Character is a fixture character and everything the fake serves about it
+type Character struct {
+	ID            int64
+	Name          string
+	CorporationID int64
+	Skills        model.CharacterSkillsResponse
+	Queue         []model.SkillQueue
+	LocationID    int64 // solar system
+	Wallet        float64
+	Attributes    model.CharacterAttributes
+}
+
+type accessToken struct {
+	characterID int64
+	expires     time.Time
+}
+
+type failure struct {
+	status int
+	times  int
+}
+
+// Server is a fake ESI and SSO. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	datasource     string
+	mut            sync.Mutex
+	characters     map[int64]Character
+	corporations   map[int64]model.Corporation
+	alliances      map[int64]model.Alliance
+	accessTokens   map[string]accessToken
+	refreshTokens  map[string]int64 // refresh token to character ID
+	codes          map[string]int64 // authorization code to character ID
+	loginCharacter int64
+	failures       map[string]*failure
+	hits           map[string]int
+}
+
+// New starts a fake Tranquility with two fixture characters, close it with Close.
+func New() *Server {
+	return NewForServer(model.ServerTranquility)
+}
+
+// NewForServer starts a fake of server, ESI requests for any other datasource are refused.
+func NewForServer(server string) *Server {
+	s := &Server{
+		datasource:     server,
+		characters:     make(map[int64]Character),
+		corporations:   make(map[int64]model.Corporation),
+		alliances:      make(map[int64]model.Alliance),
+		accessTokens:   make(map[string]accessToken),
+		refreshTokens:  make(map[string]int64),
+		codes:          make(map[string]int64),
+		loginCharacter: PilotID,
+		failures:       make(map[string]*failure),
+		hits:           make(map[string]int),
+	}
+	s.addFixtures()
+	s.Server = httptest.NewServer(s.router())
+	return s
+}
+
+func (s *Server) addFixtures() {
+	start := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	finish := start.Add(10 * time.Hour)
+	s.AddCharacter(Character{
+		ID:            PilotID,
+		Name:          "Fixture Pilot",
+		CorporationID: CorporationID,
+		Skills: model.CharacterSkillsResponse{
+			TotalSP: 5000000,
+			Skills: []model.SkillResponse{
+				{SkillID: 3300, ActiveSkillLevel: 4, TrainedSkillLevel: 4, SkillpointsInSkill: 45255},
+				{SkillID: 3327, ActiveSkillLevel: 3, TrainedSkillLevel: 3, SkillpointsInSkill: 8000},
+			},
+		},
+		Queue: []model.SkillQueue{
+			{SkillID: 3300, FinishedLevel: 5, QueuePosition: 0, StartDate: &start, FinishDate: &finish, LevelStartSP: 45255, LevelEndSP: 256000, TrainingStartSP: 45255},
+		},
+		LocationID: JitaSystemID,
+		Wallet:     1500000000,
+		Attributes: model.CharacterAttributes{Charisma: 17, Intelligence: 20, Memory: 20, Perception: 20, Willpower: 22},
+	})
+	s.AddCharacter(Character{
+		ID:            AltID,
+		Name:          "Fixture Alt",
+		CorporationID: NPCCorpID,
+		Skills:        model.CharacterSkillsResponse{TotalSP: 400000, Skills: []model.SkillResponse{}},
+		Queue:         []model.SkillQueue{},
+		LocationID:    JitaSystemID,
+		Attributes:    model.CharacterAttributes{Charisma: 19, Intelligence: 20, Memory: 20, Perception: 20, Willpower: 20},
+	})
+	s.AddCorporation(CorporationID, model.Corporation{Name: "Fixture Corporation", Ticker: "FIXT", AllianceID: int(AllianceID), MemberCount: 1})
+	s.AddCorporation(NPCCorpID, model.Corporation{Name: "Center for Advanced Studies", Ticker: "CAS", MemberCount: 1})
+	s.AddAlliance(AllianceID, model.Alliance{Name: "Fixture Alliance", Ticker: "FIXA"})
+}
+
+func (s *Server) AddCharacter(c Character) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.characters[c.ID] = c
+}
+
+// UpdateCharacter changes a character in place, for example to move its queue along.
+func (s *Server) UpdateCharacter(id int64, update func(c *Character)) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if c, ok := s.characters[id]; ok {
+		update(&c)
+		s.characters[id] = c
+	}
+}
+
+// RemoveCharacter makes ESI answer 404 for the character, as it does for biomassed characters.
+func (s *Server) RemoveCharacter(id int64) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	delete(s.characters, id)
+}
+
+func (s *Server) AddCorporation(id int64, corp model.Corporation) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.corporations[id] = corp
+}
+
+func (s *Server) AddAlliance(id int64, alliance model.Alliance) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.alliances[id] = alliance
+}
+
+// Login issues a token for the character as if it had been through the SSO flow.
+func (s *Server) Login(characterID int64) *oauth2.Token {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.issueToken(characterID)
+}
+
+// SetLoginCharacter picks the character the authorize endpoint logs in, PilotID by default.
+func (s *Server) SetLoginCharacter(characterID int64) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.loginCharacter = characterID
+}
+
+// ExpireAccessTokens makes every access token issued so far expired, refresh tokens keep working.
+func (s *Server) ExpireAccessTokens() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for key, token := range s.accessTokens {
+		token.expires = time.Now().Add(-time.Second)
+		s.accessTokens[key] = token
+	}
+}
+
+// RevokeRefreshTokens makes SSO reject every refresh token issued so far with invalid_grant.
+func (s *Server) RevokeRefreshTokens() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.refreshTokens = make(map[string]int64)
+}
+
+// FailNext answers the next times requests for path with status, path is matched exactly
+// against the request path, e.g. /latest/characters/90000001/skills/.
+func (s *Server) FailNext(path string, status, times int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.failures[path] = &failure{status: status, times: times}
+}
+
+// Hits returns how many requests were made for path, including failed ones.
+func (s *Server) Hits(path string) int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.hits[path]
+}
+
+func (s *Server) router() http.Handler {
+	r := mux.NewRouter()
+
+	// SSO
+	r.HandleFunc("/v2/oauth/authorize", s.authorize).Methods(http.MethodGet)
+	r.HandleFunc("/v2/oauth/token", s.token).Methods(http.MethodPost)
+	r.HandleFunc("/oauth/verify", s.authenticated(s.verify)).Methods(http.MethodGet)
+
+	// public ESI
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/", s.character).Methods(http.MethodGet)
+	r.HandleFunc("/latest/corporations/{id:[0-9]+}/", s.corporation).Methods(http.MethodGet)
+	r.HandleFunc("/latest/alliances/{id:[0-9]+}/", s.alliance).Methods(http.MethodGet)
+	r.HandleFunc("/latest/universe/names/", s.universeNames).Methods(http.MethodPost)
+
+	// authenticated ESI
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/skills/", s.characterData(func(c Character) interface{} { return c.Skills })).Methods(http.MethodGet)
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/skillqueue/", s.characterData(func(c Character) interface{} { return c.Queue })).Methods(http.MethodGet)
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/location/", s.characterData(func(c Character) interface{} {
+		return model.CharacterLocation{SolarSystemID: c.LocationID}
+	})).Methods(http.MethodGet)
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/wallet/", s.characterData(func(c Character) interface{} { return c.Wallet })).Methods(http.MethodGet)
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/wallet/journal/", s.characterData(func(c Character) interface{} {
+		return []model.WalletJournalEntry{}
+	})).Methods(http.MethodGet)
+	r.HandleFunc("/latest/characters/{id:[0-9]+}/attributes/", s.characterData(func(c Character) interface{} { return c.Attributes })).Methods(http.MethodGet)
+
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Requested page does not exist!")
+	})
+	return s.countAndFail(r)
+}
+
+// countAndFail records the request and answers with a queued failure when there is one.
+func (s *Server) countAndFail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mut.Lock()
+		s.hits[r.URL.Path]++
+		f := s.failures[r.URL.Path]
+		status := 0
+		if f != nil && f.times > 0 {
+			f.times--
+			status = f.status
+		}
+		s.mut.Unlock()
+
+		if status != 0 {
+			writeError(w, status, errorMessage(status))
+			return
+		}
+		// like ESI, a request without a datasource is for Tranquility
+		datasource := r.URL.Query().Get("datasource")
+		if datasource == "" {
+			datasource = model.ServerTranquility
+		}
+		if strings.HasPrefix(r.URL.Path, "/latest/") && datasource != s.datasource {
+			writeError(w, http.StatusBadRequest, "Invalid datasource "+datasource)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func errorMessage(status int) string {
+	switch status {
+	case 420:
+		return "This software has exceeded the error limit for ESI. If you are a user, please contact the maintainer of this software."
+	case http.StatusNotFound:
+		return "Not found"
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusBadGateway:
+		return "The datasource tranquility is temporarily unavailable"
+	default:
+		return http.StatusText(status)
+	}
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	redirect, err := url.Parse(r.URL.Query().Get("redirect_uri"))
+	if err != nil || redirect.String() == "" {
+		writeError(w, http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+
+	s.mut.Lock()
+	code := randomString()
+	s.codes[code] = s.loginCharacter
+	s.mut.Unlock()
+
+	query := redirect.Query()
+	query.Set("code", code)
+	query.Set("state", r.URL.Query().Get("state"))
+	redirect.RawQuery = query.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeSSOError(w, "invalid_request", "malformed form")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var characterID int64
+	var ok bool
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code := r.PostForm.Get("code")
+		characterID, ok = s.codes[code]
+		delete(s.codes, code)
+	case "refresh_token":
+		characterID, ok = s.refreshTokens[r.PostForm.Get("refresh_token")]
+	default:
+		writeSSOError(w, "unsupported_grant_type", "unsupported grant type")
+		return
+	}
+	if !ok {
+		writeSSOError(w, "invalid_grant", "Invalid refresh token. Token missing/expired.")
+		return
+	}
+
+	token := s.issueToken(characterID)
+	writeJSON(w, map[string]interface{}{
+		"access_token":  token.AccessToken,
+		"token_type":    token.TokenType,
+		"expires_in":    int(AccessTokenLifetime.Seconds()),
+		"refresh_token": token.RefreshToken,
+	})
+}
+
+func (s *Server) verify(w http.ResponseWriter, r *http.Request, characterID int64) {
+	c, ok := s.lookupCharacter(characterID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Character not found")
+		return
+	}
+	writeJSON(w, model.UserInfoResponse{CharacterID: c.ID, CharacterName: c.Name})
+}
+
+func (s *Server) character(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.lookupCharacter(pathID(r))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Character not found")
+		return
+	}
+
+	s.mut.Lock()
+	corp := s.corporations[c.CorporationID]
+	s.mut.Unlock()
+	writeJSON(w, model.CharacterResponse{
+		Name:          c.Name,
+		CorporationID: int32(c.CorporationID),
+		AllianceID:    int32(corp.AllianceID),
+		Birthday:      time.Date(2015, 3, 24, 11, 37, 0, 0, time.UTC),
+		BloodlineID:   1,
+		RaceID:        1,
+		Gender:        "female",
+	})
+}
+
+func (s *Server) corporation(w http.ResponseWriter, r *http.Request) {
+	s.mut.Lock()
+	corp, ok := s.corporations[pathID(r)]
+	s.mut.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Corporation not found")
+		return
+	}
+	writeJSON(w, corp)
+}
+
+func (s *Server) alliance(w http.ResponseWriter, r *http.Request) {
+	s.mut.Lock()
+	alliance, ok := s.alliances[pathID(r)]
+	s.mut.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Alliance not found")
+		return
+	}
+	writeJSON(w, alliance)
+}
+
+func (s *Server) universeNames(w http.ResponseWriter, r *http.Request) {
+	var ids []int64
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid body")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	names := make([]model.UniverseName, 0, len(ids))
+	for _, id := range ids {
+		switch {
+		case s.characters[id].Name != "":
+			names = append(names, model.UniverseName{ID: id, Name: s.characters[id].Name, Category: "character"})
+		case s.corporations[id].Name != "":
+			names = append(names, model.UniverseName{ID: id, Name: s.corporations[id].Name, Category: "corporation"})
+		case s.alliances[id].Name != "":
+			names = append(names, model.UniverseName{ID: id, Name: s.alliances[id].Name, Category: "alliance"})
+		default:
+			// ESI fails the whole request when any ID is unknown
+			writeError(w, http.StatusNotFound, "Ensure all IDs are valid before resolving.")
+			return
+		}
+	}
+	writeJSON(w, names)
+}
+
+// characterData serves a part of a character to a token of that character.
+func (s *Server) characterData(part func(c Character) interface{}) http.HandlerFunc {
+	return s.authenticated(func(w http.ResponseWriter, r *http.Request, characterID int64) {
+		if pathID(r) != characterID {
+			writeError(w, http.StatusForbidden, "Character in the token does not match the request")
+			return
+		}
+		c, ok := s.lookupCharacter(characterID)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Character not found")
+			return
+		}
+		writeJSON(w, part(c))
+	})
+}
+
+// authenticated checks the bearer token and passes on the character it was issued to.
+func (s *Server) authenticated(next func(w http.ResponseWriter, r *http.Request, characterID int64)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		header := r.Header.Get("Authorization")
+		if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+			writeError(w, http.StatusUnauthorized, "authorization not provided")
+			return
+		}
+
+		s.mut.Lock()
+		token, ok := s.accessTokens[header[len(prefix):]]
+		s.mut.Unlock()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "authorization not valid")
+			return
+		}
+		if time.Now().After(token.expires) {
+			writeError(w, http.StatusUnauthorized, "token is expired")
+			return
+		}
+		next(w, r, token.characterID)
+	}
+}
+
+func (s *Server) lookupCharacter(id int64) (Character, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	c, ok := s.characters[id]
+	return c, ok
+}
+
+// issueToken must be called with mut held.
+func (s *Server) issueToken(characterID int64) *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  randomString(),
+		TokenType:    "Bearer",
+		RefreshToken: randomString(),
+		Expiry:       time.Now().Add(AccessTokenLifetime),
+	}
+	s.accessTokens[token.AccessToken] = accessToken{characterID: characterID, expires: token.Expiry}
+	s.refreshTokens[token.RefreshToken] = characterID
+	return token
+}
+
+func pathID(r *http.Request) int64 {
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	return id
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func writeSSOError(w http.ResponseWriter, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": description})
+}
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -69,6 +70,41 @@ func (m *MockAssociationService) UnassociateCharacter(userId, charId string) err
 	return args.Error(0)
 }
 
+func (m *MockAssociationService) SuggestAssociations(profiles []model.EveProfile) ([]model.AssociationSuggestion, error) {
+	args := m.Called(profiles)
+	return args.Get(0).([]model.AssociationSuggestion), args.Error(1)
+}
+
+func (m *MockAssociationService) AcceptSuggestions(suggestions []model.Association) ([]model.Association, []model.AssociationRejection, error) {
+	args := m.Called(suggestions)
+	return args.Get(0).([]model.Association), args.Get(1).([]model.AssociationRejection), args.Error(2)
+}
+
+func (m *MockAssociationService) GetAssociationRules() (model.AssociationRules, error) {
+	args := m.Called()
+	return args.Get(0).(model.AssociationRules), args.Error(1)
+}
+
+func (m *MockAssociationService) UpdateAssociationRules(rules model.AssociationRules) error {
+	args := m.Called(rules)
+	return args.Error(0)
+}
+
+func (m *MockAssociationService) CheckAssociations(profiles []model.EveProfile) ([]model.AssociationIssue, error) {
+	args := m.Called(profiles)
+	return args.Get(0).([]model.AssociationIssue), args.Error(1)
+}
+
+func (m *MockAssociationService) RepairAssociations() ([]model.AssociationIssue, error) {
+	args := m.Called()
+	return args.Get(0).([]model.AssociationIssue), args.Error(1)
+}
+
+func (m *MockAssociationService) RemoveOrphanedAssociations(profiles []model.EveProfile, orphans []model.Association) ([]model.Association, error) {
+	args := m.Called(profiles, orphans)
+	return args.Get(0).([]model.Association), args.Error(1)
+}
+
 // MockESIService mocks interfaces.ESIService
 type MockESIService struct {
 	mock.Mock
@@ -119,13 +155,63 @@ func (m *MockESIService) GetAlliance(id int64, token *oauth2.Token) (*model.Alli
 	return args.Get(0).(*model.Alliance), args.Error(1)
 }
 
+func (m *MockESIService) GetCharacterWallet(characterID int64, token *oauth2.Token) (float64, error) {
+	args := m.Called(characterID, token)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockESIService) GetCharacterWalletJournal(characterID int64, token *oauth2.Token) ([]model.WalletJournalEntry, error) {
+	args := m.Called(characterID, token)
+	return args.Get(0).([]model.WalletJournalEntry), args.Error(1)
+}
+
+func (m *MockESIService) GetCharacterAssets(characterID int64, token *oauth2.Token) ([]model.EsiAsset, error) {
+	args := m.Called(characterID, token)
+	return args.Get(0).([]model.EsiAsset), args.Error(1)
+}
+
+func (m *MockESIService) GetCharacterAssetNames(characterID int64, token *oauth2.Token, itemIDs []int64) (map[int64]string, error) {
+	args := m.Called(characterID, token, itemIDs)
+	return args.Get(0).(map[int64]string), args.Error(1)
+}
+
+func (m *MockESIService) GetStation(id int64) (*model.Station, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Station), args.Error(1)
+}
+
+func (m *MockESIService) GetStructure(id int64, token *oauth2.Token) (*model.Structure, error) {
+	args := m.Called(id, token)
+	return args.Get(0).(*model.Structure), args.Error(1)
+}
+
+func (m *MockESIService) ResolveUniverseNames(ids []int64) (map[int64]string, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[int64]string), args.Error(1)
+}
+
+func (m *MockESIService) GetCharacterAttributes(characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error) {
+	args := m.Called(characterID, token)
+	return args.Get(0).(*model.CharacterAttributes), args.Error(1)
+}
+
+func (m *MockESIService) GetType(typeID int64) (*model.EsiType, error) {
+	args := m.Called(typeID)
+	return args.Get(0).(*model.EsiType), args.Error(1)
+}
+
+// ForServer returns the mock itself so expectations apply to every server
+func (m *MockESIService) ForServer(server string) (interfaces.ESIService, error) {
+	return m, nil
+}
+
 // MockCharacterService mocks interfaces.CharacterService
 type MockCharacterService struct {
 	mock.Mock
 }
 
-func (m *MockCharacterService) ProcessIdentity(charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error) {
-	args := m.Called(charIdentity)
+func (m *MockCharacterService) ProcessIdentity(charIdentity *model.CharacterIdentity, server string) (*model.CharacterIdentity, error) {
+	args := m.Called(charIdentity, server)
 	return args.Get(0).(*model.CharacterIdentity), args.Error(1)
 }
 
@@ -174,6 +260,31 @@ func (m *MockSkillService) ParseAndSaveSkillPlan(contents, name string) error {
 	return args.Error(0)
 }
 
+func (m *MockSkillService) ParseAndUpdateSkillPlan(contents, name, note string) error {
+	args := m.Called(contents, name, note)
+	return args.Error(0)
+}
+
+func (m *MockSkillService) GetSkillPlanRevisions(name string) ([]model.SkillPlanRevision, error) {
+	args := m.Called(name)
+	return args.Get(0).([]model.SkillPlanRevision), args.Error(1)
+}
+
+func (m *MockSkillService) DiffSkillPlanRevisions(name string, from, to int) (*model.SkillPlanDiff, error) {
+	args := m.Called(name, from, to)
+	return args.Get(0).(*model.SkillPlanDiff), args.Error(1)
+}
+
+func (m *MockSkillService) RollbackSkillPlan(name string, revision int) error {
+	args := m.Called(name, revision)
+	return args.Error(0)
+}
+
+func (m *MockSkillService) RenameSkillPlan(oldName, newName string) error {
+	args := m.Called(oldName, newName)
+	return args.Error(0)
+}
+
 func (m *MockSkillService) GetSkillPlanFile(name string) ([]byte, error) {
 	args := m.Called(name)
 	return args.Get(0).([]byte), args.Error(1)
@@ -200,6 +311,50 @@ func (m *MockSkillService) GetPlanAndConversionData(
 	return args.Get(0).(map[string]model.SkillPlanWithStatus), args.Get(1).(map[string]string)
 }
 
+func (m *MockSkillService) GetDoctrines() map[string]model.Doctrine {
+	args := m.Called()
+	return args.Get(0).(map[string]model.Doctrine)
+}
+
+func (m *MockSkillService) SaveDoctrine(doctrine model.Doctrine) error {
+	args := m.Called(doctrine)
+	return args.Error(0)
+}
+
+func (m *MockSkillService) DeleteDoctrine(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockSkillService) GetDoctrinesWithStatus(
+	accounts []model.Account,
+	doctrines map[string]model.Doctrine,
+	skillTypes map[string]model.SkillType,
+) map[string]model.DoctrineWithStatus {
+	args := m.Called(accounts, doctrines, skillTypes)
+	return args.Get(0).(map[string]model.DoctrineWithStatus)
+}
+
+func (m *MockSkillService) GetEmbeddedPlanConflicts() []model.EmbeddedPlanConflict {
+	args := m.Called()
+	return args.Get(0).([]model.EmbeddedPlanConflict)
+}
+
+func (m *MockSkillService) ResolveEmbeddedPlanConflict(planName, resolution string) error {
+	args := m.Called(planName, resolution)
+	return args.Error(0)
+}
+
+func (m *MockSkillService) PlanInjectors(accounts []model.Account, planName string, characterID int64) (*model.InjectorPlan, error) {
+	args := m.Called(accounts, planName, characterID)
+	return args.Get(0).(*model.InjectorPlan), args.Error(1)
+}
+
+func (m *MockSkillService) PlanExtraction(accounts []model.Account, characterID int64) (*model.ExtractionPlan, error) {
+	args := m.Called(accounts, characterID)
+	return args.Get(0).(*model.ExtractionPlan), args.Error(1)
+}
+
 // MockAccountService mocks interfaces.AccountService
 type MockAccountService struct {
 	mock.Mock
@@ -209,8 +364,8 @@ func (m *MockAccountService) ToggleAccountVisibility(accountID int64) error {
 	return nil
 }
 
-func (m *MockAccountService) FindOrCreateAccount(state string, char *model.UserInfoResponse, token *oauth2.Token) error {
-	args := m.Called(state, char, token)
+func (m *MockAccountService) FindOrCreateAccount(accountName, server string, char *model.UserInfoResponse, token *oauth2.Token) error {
+	args := m.Called(accountName, server, char, token)
 	return args.Error(0)
 }
 
@@ -254,6 +409,41 @@ func (m *MockAccountService) GetAccountNameByID(id string) (string, bool) {
 	return args.String(0), args.Bool(1)
 }
 
+func (m *MockAccountService) SetAccountExpiry(accountID int64, expiry *time.Time) error {
+	args := m.Called(accountID, expiry)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) SetCharacterMCTExpiry(characterID int64, expiry *time.Time) error {
+	args := m.Called(characterID, expiry)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) GetExpiryWarnings(accounts []model.Account) []model.ExpiryWarning {
+	args := m.Called(accounts)
+	return args.Get(0).([]model.ExpiryWarning)
+}
+
+func (m *MockAccountService) MoveCharacter(characterID int64, toAccountName string) error {
+	args := m.Called(characterID, toAccountName)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) MergeAccounts(fromAccountName, intoAccountName string) error {
+	args := m.Called(fromAccountName, intoAccountName)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) GetTokenWarnings(accounts []model.Account) []model.TokenWarning {
+	args := m.Called(accounts)
+	return args.Get(0).([]model.TokenWarning)
+}
+
+func (m *MockAccountService) ReauthorizeCharacter(characterID int64, server string, token *oauth2.Token) error {
+	args := m.Called(characterID, server, token)
+	return args.Error(0)
+}
+
 // MockConfigService mocks interfaces.ConfigService
 type MockConfigService struct {
 	mock.Mock
@@ -282,6 +472,26 @@ func (m *MockConfigService) GetSettingsDir() (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockConfigService) GetSettingsRoots() ([]model.SettingsRoot, error) {
+	args := m.Called()
+	return args.Get(0).([]model.SettingsRoot), args.Error(1)
+}
+
+func (m *MockConfigService) AddSettingsRoot(root model.SettingsRoot) error {
+	args := m.Called(root)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) RemoveSettingsRoot(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) DiscoverSettingsDirs() ([]model.SettingsDirCandidate, error) {
+	args := m.Called()
+	return args.Get(0).([]model.SettingsDirCandidate), args.Error(1)
+}
+
 func (m *MockConfigService) FetchUserSelections() (model.DropDownSelections, error) {
 	args := m.Called()
 	return args.Get(0).(model.DropDownSelections), args.Error(1)
@@ -312,6 +522,16 @@ func (m *MockConfigService) FetchConfigData() (*model.ConfigData, error) {
 	return args.Get(0).(*model.ConfigData), args.Error(1)
 }
 
+func (m *MockConfigService) GetCalendarToken() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockConfigService) ResetCalendarToken() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 // MockEveProfilesService mocks interfaces.EveProfilesService
 type MockEveProfilesService struct {
 	mock.Mock
@@ -327,13 +547,23 @@ func (m *MockEveProfilesService) BackupDir(targetDir, backupDir string) error {
 	return args.Error(0)
 }
 
-func (m *MockEveProfilesService) SyncDir(subDir, charId, userId string) (int, int, error) {
-	args := m.Called(subDir, charId, userId)
+func (m *MockEveProfilesService) LoadProfile(root, subDir string) (*model.EveProfile, error) {
+	args := m.Called(root, subDir)
+	return args.Get(0).(*model.EveProfile), args.Error(1)
+}
+
+func (m *MockEveProfilesService) SyncDir(root, subDir, charId, userId string) (int, int, error) {
+	args := m.Called(root, subDir, charId, userId)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockEveProfilesService) SyncAllDir(root, baseSubDir, charId, userId string) (int, int, error) {
+	args := m.Called(root, baseSubDir, charId, userId)
 	return args.Int(0), args.Int(1), args.Error(2)
 }
 
-func (m *MockEveProfilesService) SyncAllDir(baseSubDir, charId, userId string) (int, int, error) {
-	args := m.Called(baseSubDir, charId, userId)
+func (m *MockEveProfilesService) SyncToRoot(fromRoot, baseSubDir, toRoot, charId, userId string) (int, int, error) {
+	args := m.Called(fromRoot, baseSubDir, toRoot, charId, userId)
 	return args.Int(0), args.Int(1), args.Error(2)
 }
 
@@ -357,6 +587,11 @@ func (m *MockAppStateService) SetAppStateLogin(isLoggedIn bool) error {
 	return args.Error(0)
 }
 
+func (m *MockAppStateService) UpdateEveProfiles(update func(profiles []model.EveProfile) []model.EveProfile) error {
+	args := m.Called(update)
+	return args.Error(0)
+}
+
 func (m *MockAppStateService) ClearAppState() {
 	m.Called()
 }
@@ -417,6 +652,11 @@ func (m *MockConfigRepository) SaveRoles(roles []string) error {
 	return args.Error(0)
 }
 
+func (m *MockConfigRepository) DiscoverSettingsDirs() ([]model.SettingsDirCandidate, error) {
+	args := m.Called()
+	return args.Get(0).([]model.SettingsDirCandidate), args.Error(1)
+}
+
 func (m *MockConfigRepository) GetDefaultSettingsDir() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
@@ -532,6 +772,11 @@ func (m *MockEveProfilesRepository) SyncAllSubdirectories(baseSubDir, userId, ch
 	return args.Int(0), args.Int(1), args.Error(2)
 }
 
+func (m *MockEveProfilesRepository) SyncToSettingsDir(baseSubDir, userId, charId, settingsDir, targetSettingsDir string) (int, int, error) {
+	args := m.Called(baseSubDir, userId, charId, settingsDir, targetSettingsDir)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
 type MockSkillRepository struct {
 	mock.Mock
 }
@@ -556,6 +801,16 @@ func (m *MockSkillRepository) SaveSkillPlan(planName string, skills map[string]m
 	return args.Error(0)
 }
 
+func (m *MockSkillRepository) UpdateSkillPlan(planName string, skills map[string]model.Skill, note string) error {
+	args := m.Called(planName, skills, note)
+	return args.Error(0)
+}
+
+func (m *MockSkillRepository) RenameSkillPlan(oldName, newName string) error {
+	args := m.Called(oldName, newName)
+	return args.Error(0)
+}
+
 func (m *MockSkillRepository) DeleteSkillPlan(planName string) error {
 	args := m.Called(planName)
 	return args.Error(0)
@@ -566,6 +821,113 @@ func (m *MockSkillRepository) GetSkillTypeByID(id string) (model.SkillType, bool
 	return args.Get(0).(model.SkillType), args.Bool(1)
 }
 
+func (m *MockSkillRepository) GetDoctrines() map[string]model.Doctrine {
+	args := m.Called()
+	return args.Get(0).(map[string]model.Doctrine)
+}
+
+func (m *MockSkillRepository) SaveDoctrine(doctrine model.Doctrine) error {
+	args := m.Called(doctrine)
+	return args.Error(0)
+}
+
+func (m *MockSkillRepository) DeleteDoctrine(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockSkillRepository) GetEmbeddedPlanConflicts() []model.EmbeddedPlanConflict {
+	args := m.Called()
+	return args.Get(0).([]model.EmbeddedPlanConflict)
+}
+
+func (m *MockSkillRepository) ResolveEmbeddedPlanConflict(planName, resolution string) error {
+	args := m.Called(planName, resolution)
+	return args.Error(0)
+}
+
+func (m *MockSkillRepository) SaveRemotePlans(source string, files map[string][]byte) error {
+	args := m.Called(source, files)
+	return args.Error(0)
+}
+
+func (m *MockSkillRepository) RemoveRemotePlans(source string) error {
+	args := m.Called(source)
+	return args.Error(0)
+}
+
+type MockPlanSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockPlanSubscriptionService) GetSubscriptions() ([]model.PlanSubscription, error) {
+	args := m.Called()
+	return args.Get(0).([]model.PlanSubscription), args.Error(1)
+}
+
+func (m *MockPlanSubscriptionService) AddSubscription(ctx context.Context, name, url string) error {
+	args := m.Called(ctx, name, url)
+	return args.Error(0)
+}
+
+func (m *MockPlanSubscriptionService) RemoveSubscription(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockPlanSubscriptionService) RefreshSubscription(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockPlanSubscriptionService) RefreshAll(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+type MockPlanSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlanSubscriptionRepository) GetSubscriptions() ([]model.PlanSubscription, error) {
+	args := m.Called()
+	return args.Get(0).([]model.PlanSubscription), args.Error(1)
+}
+
+func (m *MockPlanSubscriptionRepository) SaveSubscription(sub model.PlanSubscription) error {
+	args := m.Called(sub)
+	return args.Error(0)
+}
+
+func (m *MockPlanSubscriptionRepository) DeleteSubscription(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+type MockSkillPlanHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockSkillPlanHistoryRepository) AddRevision(planName string, skills map[string]model.Skill, note string) (model.SkillPlanRevision, error) {
+	args := m.Called(planName, skills, note)
+	return args.Get(0).(model.SkillPlanRevision), args.Error(1)
+}
+
+func (m *MockSkillPlanHistoryRepository) GetRevisions(planName string) ([]model.SkillPlanRevision, error) {
+	args := m.Called(planName)
+	return args.Get(0).([]model.SkillPlanRevision), args.Error(1)
+}
+
+func (m *MockSkillPlanHistoryRepository) RenameHistory(oldName, newName string) error {
+	args := m.Called(oldName, newName)
+	return args.Error(0)
+}
+
+func (m *MockSkillPlanHistoryRepository) DeleteHistory(planName string) error {
+	args := m.Called(planName)
+	return args.Error(0)
+}
+
 // MockSessionService simulates the behavior of SessionService.
 // Now it returns a real *sessions.Session instead of a mock session.
 type MockSessionService struct {
@@ -614,3 +976,78 @@ func (m *MockDashboardService) GetCurrentAppState() model.AppState {
 	args := m.Called()
 	return args.Get(0).(model.AppState)
 }
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) EvaluateAlerts(accounts []model.Account, skillPlans map[string]model.SkillPlanWithStatus, doctrines map[string]model.DoctrineWithStatus) ([]model.Alert, error) {
+	args := m.Called(accounts, skillPlans, doctrines)
+	return args.Get(0).([]model.Alert), args.Error(1)
+}
+
+func (m *MockNotificationService) GetAlerts() ([]model.Alert, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Alert), args.Error(1)
+}
+
+func (m *MockNotificationService) AcknowledgeAlert(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) GetSettings() (model.NotificationSettings, error) {
+	args := m.Called()
+	return args.Get(0).(model.NotificationSettings), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdateSettings(settings model.NotificationSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) FetchNotificationState() (model.NotificationState, error) {
+	args := m.Called()
+	return args.Get(0).(model.NotificationState), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SaveNotificationState(state model.NotificationState) error {
+	args := m.Called(state)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(url, rule, mention string, alerts []model.Alert) error {
+	args := m.Called(url, rule, mention, alerts)
+	return args.Error(0)
+}
+
+type MockWalletService struct {
+	mock.Mock
+}
+
+func (m *MockWalletService) RefreshWallets(accounts []model.Account) model.WalletSummary {
+	args := m.Called(accounts)
+	return args.Get(0).(model.WalletSummary)
+}
+
+func (m *MockWalletService) SyncJournals(accounts []model.Account) {
+	m.Called(accounts)
+}
+
+func (m *MockWalletService) GetBalanceHistory(characterID int64) ([]model.BalanceSnapshot, error) {
+	args := m.Called(characterID)
+	return args.Get(0).([]model.BalanceSnapshot), args.Error(1)
+}
+
+func (m *MockWalletService) GetJournal(characterID int64) ([]model.WalletJournalEntry, error) {
+	args := m.Called(characterID)
+	return args.Get(0).([]model.WalletJournalEntry), args.Error(1)
+}